@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE INDEX IF NOT EXISTS idx_orders_date_created_id_desc ON orders (date_created DESC, id DESC);
+DROP INDEX IF EXISTS idx_orders_date_created_desc;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+CREATE INDEX IF NOT EXISTS idx_orders_date_created_desc ON orders (date_created DESC);
+DROP INDEX IF EXISTS idx_orders_date_created_id_desc;
+-- +goose StatementEnd
@@ -0,0 +1,212 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Orders, items and payments are partitioned monthly by the order creation date,
+-- so the whole month of data can be detached at once by the retention job.
+-- Foreign keys are not kept between the partitioned tables, because they would prevent
+-- partitions from being detached. Consistency is guaranteed by inserting an order in a single transaction.
+
+ALTER TABLE payments RENAME TO payments_legacy;
+ALTER TABLE items RENAME TO items_legacy;
+ALTER TABLE orders RENAME TO orders_legacy;
+ALTER INDEX IF EXISTS idx_orders_date_created_id_desc RENAME TO idx_orders_legacy_date_created_id_desc;
+
+CREATE TABLE orders (
+    id                TEXT NOT NULL,
+    track_number      TEXT NOT NULL,
+    entry             TEXT NOT NULL,
+    locale            TEXT NOT NULL,
+    internal_signature TEXT NOT NULL,
+    customer_id       TEXT NOT NULL,
+    delivery_service  TEXT NOT NULL,
+    shardkey          TEXT NOT NULL,
+    sm_id             INTEGER NOT NULL,
+    date_created      TIMESTAMPTZ NOT NULL,
+    oof_shard         TEXT NOT NULL,
+    delivery_name TEXT NOT NULL,
+    delivery_city TEXT NOT NULL,
+    delivery_phone TEXT NOT NULL,
+    delivery_zip TEXT NOT NULL,
+    delivery_address TEXT NOT NULL,
+    delivery_region TEXT NOT NULL,
+    delivery_email TEXT NOT NULL,
+    PRIMARY KEY (id, date_created)
+) PARTITION BY RANGE (date_created);
+
+CREATE TABLE items (
+    id          SERIAL NOT NULL,
+    order_id    TEXT NOT NULL,
+    chrt_id     INTEGER NOT NULL,
+    track_number TEXT NOT NULL,
+    price       DECIMAL NOT NULL,
+    rid         TEXT NOT NULL,
+    name        TEXT NOT NULL,
+    sale        DECIMAL NOT NULL,
+    size        TEXT NOT NULL,
+    total_price DECIMAL NOT NULL,
+    nm_id       INTEGER NOT NULL,
+    brand       TEXT NOT NULL,
+    status      INTEGER NOT NULL,
+    date_created TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (id, date_created)
+) PARTITION BY RANGE (date_created);
+
+CREATE TABLE payments (
+    transaction   TEXT NOT NULL,
+    order_id      TEXT NOT NULL,
+    request_id    TEXT NOT NULL,
+    currency      TEXT NOT NULL,
+    provider      TEXT NOT NULL,
+    amount        DECIMAL NOT NULL,
+    payment_dt    BIGINT NOT NULL,
+    bank          TEXT NOT NULL,
+    delivery_cost DECIMAL NOT NULL,
+    goods_total   INTEGER NOT NULL,
+    custom_fee    DECIMAL NOT NULL,
+    date_created  TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (transaction, date_created),
+    UNIQUE (order_id, date_created)
+) PARTITION BY RANGE (date_created);
+
+CREATE INDEX IF NOT EXISTS idx_orders_date_created_id_desc ON orders (date_created DESC, id DESC);
+CREATE INDEX IF NOT EXISTS idx_items_order_id ON items (order_id);
+
+CREATE TABLE orders_default PARTITION OF orders DEFAULT;
+CREATE TABLE items_default PARTITION OF items DEFAULT;
+CREATE TABLE payments_default PARTITION OF payments DEFAULT;
+
+-- create_orders_partitions creates monthly partitions of orders, items and payments
+-- for every month in [from_month, to_month]. Partitions are named like orders_p2025_08.
+CREATE OR REPLACE FUNCTION create_orders_partitions(from_month DATE, to_month DATE) RETURNS VOID AS $$
+DECLARE
+    month DATE := date_trunc('month', from_month);
+    tbl TEXT;
+BEGIN
+    WHILE month <= to_month LOOP
+        FOREACH tbl IN ARRAY ARRAY['orders', 'items', 'payments'] LOOP
+            EXECUTE format(
+                'CREATE TABLE IF NOT EXISTS %I PARTITION OF %I FOR VALUES FROM (%L) TO (%L)',
+                tbl || '_p' || to_char(month, 'YYYY_MM'),
+                tbl,
+                month::timestamptz,
+                (month + INTERVAL '1 month')::timestamptz
+            );
+        END LOOP;
+
+        month := month + INTERVAL '1 month';
+    END LOOP;
+END;
+$$ LANGUAGE plpgsql;
+
+SELECT create_orders_partitions(
+    COALESCE((SELECT min(date_created) FROM orders_legacy), now())::date,
+    (now() + INTERVAL '2 months')::date
+);
+
+INSERT INTO orders SELECT * FROM orders_legacy;
+
+INSERT INTO items (
+    id, order_id, chrt_id, track_number, price, rid, name, sale, size, total_price, nm_id, brand, status, date_created
+)
+SELECT i.id, i.order_id, i.chrt_id, i.track_number, i.price, i.rid, i.name, i.sale, i.size, i.total_price, i.nm_id, i.brand, i.status, o.date_created
+FROM items_legacy i
+JOIN orders_legacy o ON o.id = i.order_id;
+
+SELECT setval(pg_get_serial_sequence('items', 'id'), COALESCE((SELECT max(id) FROM items), 0) + 1, false);
+
+INSERT INTO payments (
+    transaction, order_id, request_id, currency, provider, amount, payment_dt, bank, delivery_cost, goods_total, custom_fee, date_created
+)
+SELECT p.transaction, p.order_id, p.request_id, p.currency, p.provider, p.amount, p.payment_dt, p.bank, p.delivery_cost, p.goods_total, p.custom_fee, o.date_created
+FROM payments_legacy p
+JOIN orders_legacy o ON o.id = p.order_id;
+
+DROP TABLE payments_legacy;
+DROP TABLE items_legacy;
+DROP TABLE orders_legacy;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+ALTER TABLE payments RENAME TO payments_partitioned;
+ALTER TABLE items RENAME TO items_partitioned;
+ALTER TABLE orders RENAME TO orders_partitioned;
+ALTER INDEX IF EXISTS idx_orders_date_created_id_desc RENAME TO idx_orders_partitioned_date_created_id_desc;
+
+CREATE TABLE orders (
+    id                TEXT PRIMARY KEY,
+    track_number      TEXT NOT NULL,
+    entry             TEXT NOT NULL,
+    locale            TEXT NOT NULL,
+    internal_signature TEXT NOT NULL,
+    customer_id       TEXT NOT NULL,
+    delivery_service  TEXT NOT NULL,
+    shardkey          TEXT NOT NULL,
+    sm_id             INTEGER NOT NULL,
+    date_created      TIMESTAMPTZ NOT NULL,
+    oof_shard         TEXT NOT NULL,
+    delivery_name TEXT NOT NULL,
+    delivery_city TEXT NOT NULL,
+    delivery_phone TEXT NOT NULL,
+    delivery_zip TEXT NOT NULL,
+    delivery_address TEXT NOT NULL,
+    delivery_region TEXT NOT NULL,
+    delivery_email TEXT NOT NULL
+);
+
+CREATE TABLE items (
+    id          SERIAL PRIMARY KEY,
+    order_id  TEXT REFERENCES orders(id) NOT NULL,
+    chrt_id     INTEGER NOT NULL,
+    track_number TEXT NOT NULL,
+    price       DECIMAL NOT NULL,
+    rid         TEXT NOT NULL,
+    name        TEXT NOT NULL,
+    sale        DECIMAL NOT NULL,
+    size        TEXT NOT NULL,
+    total_price DECIMAL NOT NULL,
+    nm_id       INTEGER NOT NULL,
+    brand       TEXT NOT NULL,
+    status      INTEGER NOT NULL
+);
+
+CREATE TABLE payments (
+    transaction   TEXT PRIMARY KEY,
+    order_id      TEXT UNIQUE NOT NULL REFERENCES orders(id),
+    request_id    TEXT NOT NULL,
+    currency      TEXT NOT NULL,
+    provider      TEXT NOT NULL,
+    amount        DECIMAL NOT NULL,
+    payment_dt    BIGINT NOT NULL,
+    bank          TEXT NOT NULL,
+    delivery_cost DECIMAL NOT NULL,
+    goods_total   INTEGER NOT NULL,
+    custom_fee    DECIMAL NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_orders_date_created_id_desc ON orders (date_created DESC, id DESC);
+
+INSERT INTO orders SELECT * FROM orders_partitioned;
+
+INSERT INTO items (
+    id, order_id, chrt_id, track_number, price, rid, name, sale, size, total_price, nm_id, brand, status
+)
+SELECT id, order_id, chrt_id, track_number, price, rid, name, sale, size, total_price, nm_id, brand, status
+FROM items_partitioned;
+
+SELECT setval(pg_get_serial_sequence('items', 'id'), COALESCE((SELECT max(id) FROM items), 0) + 1, false);
+
+INSERT INTO payments (
+    transaction, order_id, request_id, currency, provider, amount, payment_dt, bank, delivery_cost, goods_total, custom_fee
+)
+SELECT transaction, order_id, request_id, currency, provider, amount, payment_dt, bank, delivery_cost, goods_total, custom_fee
+FROM payments_partitioned;
+
+DROP FUNCTION IF EXISTS create_orders_partitions(DATE, DATE);
+DROP TABLE payments_partitioned;
+DROP TABLE items_partitioned;
+DROP TABLE orders_partitioned;
+
+-- +goose StatementEnd
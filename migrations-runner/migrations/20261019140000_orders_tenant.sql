@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE orders ADD COLUMN tenant TEXT NOT NULL DEFAULT 'default';
+CREATE INDEX IF NOT EXISTS idx_orders_tenant_date_created_id_desc ON orders (tenant, date_created DESC, id DESC);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_orders_tenant_date_created_id_desc;
+ALTER TABLE orders DROP COLUMN tenant;
+-- +goose StatementEnd
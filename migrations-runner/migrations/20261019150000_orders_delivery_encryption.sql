@@ -0,0 +1,19 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Delivery name, phone, address and email are encrypted with a per-order data key when encryption is enabled.
+-- Rows with NULL delivery_key_id are stored in plain text.
+ALTER TABLE orders
+    ADD COLUMN delivery_key_id TEXT,
+    ADD COLUMN delivery_data_key BYTEA,
+    ADD COLUMN delivery_email_index TEXT;
+CREATE INDEX IF NOT EXISTS idx_orders_delivery_email_index ON orders (delivery_email_index);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_orders_delivery_email_index;
+ALTER TABLE orders
+    DROP COLUMN delivery_key_id,
+    DROP COLUMN delivery_data_key,
+    DROP COLUMN delivery_email_index;
+-- +goose StatementEnd
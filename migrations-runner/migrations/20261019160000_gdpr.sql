@@ -0,0 +1,24 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE orders ADD COLUMN anonymized_at TIMESTAMPTZ;
+CREATE INDEX IF NOT EXISTS idx_orders_customer_id ON orders (customer_id);
+
+-- gdpr_audit records every export and erasure of personal data.
+-- Subjects are stored hashed, so the audit does not keep the erased data itself.
+CREATE TABLE gdpr_audit (
+    id           BIGSERIAL PRIMARY KEY,
+    action       TEXT NOT NULL,
+    subject_type TEXT NOT NULL,
+    subject_hash TEXT NOT NULL,
+    actor        TEXT NOT NULL,
+    order_ids    TEXT[] NOT NULL,
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS gdpr_audit;
+DROP INDEX IF EXISTS idx_orders_customer_id;
+ALTER TABLE orders DROP COLUMN anonymized_at;
+-- +goose StatementEnd
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"order-persistor/internal/archive"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// runArchive moves orders older than the configured age from postgres into the archive storage.
+func runArchive(args []string) error {
+	fs := flag.NewFlagSet("archive", flag.ExitOnError)
+	configPath := fs.String("config", "config.yaml", "path to config file (e.g. config.yaml)")
+	olderThan := fs.Duration("older-than", 0, "archive orders older than this age, overrides archive.older_than")
+	target := fs.String("target", "", "archive target (file:///dir or s3://bucket/prefix), overrides archive.target")
+	fs.Parse(args)
+
+	cfg, logger, err := loadConfig(*configPath)
+	if err != nil {
+		return err
+	}
+
+	if *olderThan != 0 {
+		cfg.Archive.OlderThan = *olderThan
+	}
+
+	if *target != "" {
+		cfg.Archive.Target = *target
+	}
+
+	if cfg.Archive.OlderThan <= 0 || cfg.Archive.BatchSize <= 0 || cfg.Archive.Target == "" {
+		return errors.New("archive older_than, batch_size and target are required")
+	}
+
+	storage, err := archive.NewStorage(cfg.Archive.Target, cfg.Archive.S3)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+
+	pool, err := pgxpool.New(ctx, cfg.Postgres.ConnString)
+	if err != nil {
+		return fmt.Errorf("creating pg pool: %w", err)
+	}
+	defer pool.Close()
+
+	repository, err := newOrdersRepository(cfg, pool)
+	if err != nil {
+		return err
+	}
+
+	// encrypted orders are archived as they are stored, so archives do not keep delivery data in plain text
+	repository.KeepEncrypted = true
+
+	archiver := archive.NewArchiver(repository, storage, cfg.Archive.BatchSize, logger)
+
+	res, err := archiver.Run(ctx, time.Now().Add(-cfg.Archive.OlderThan))
+	if err != nil {
+		return err
+	}
+
+	logger.Info("archive: done", "name", res.Name, "exported", res.Exported, "deleted", res.Deleted)
+	return nil
+}
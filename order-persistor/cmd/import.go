@@ -0,0 +1,71 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"order-persistor/internal/importer"
+	"order-persistor/internal/orders"
+	"os"
+	"os/signal"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// runImport writes orders from a NDJSON or JSON array file into postgres.
+func runImport(args []string) error {
+	fs := flag.NewFlagSet("import", flag.ExitOnError)
+	configPath := fs.String("config", "config.yaml", "path to config file (e.g. config.yaml)")
+	inputPath := fs.String("file", "-", "path to NDJSON or JSON array file with orders, - for stdin")
+	errorsPath := fs.String("errors", "import-errors.ndjson", "path to file to report failed orders to")
+	batchSize := fs.Int("batch", 100, "count of orders written in a single transaction")
+	tenant := fs.String("tenant", orders.DefaultTenant, "tenant the imported orders belong to")
+	fs.Parse(args)
+
+	if *batchSize <= 0 {
+		return errors.New("batch should be > 0")
+	}
+
+	cfg, logger, err := loadConfig(*configPath)
+	if err != nil {
+		return err
+	}
+
+	var input io.Reader = os.Stdin
+	if *inputPath != "-" {
+		f, err := os.Open(*inputPath)
+		if err != nil {
+			return fmt.Errorf("could not open input file: %w", err)
+		}
+		defer f.Close()
+
+		input = f
+	}
+
+	failures, err := os.Create(*errorsPath)
+	if err != nil {
+		return fmt.Errorf("could not create errors file: %w", err)
+	}
+	defer failures.Close()
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+
+	pool, err := pgxpool.New(ctx, cfg.Postgres.ConnString)
+	if err != nil {
+		return fmt.Errorf("creating pg pool: %w", err)
+	}
+	defer pool.Close()
+
+	repository, err := newOrdersRepository(cfg, pool)
+	if err != nil {
+		return err
+	}
+
+	res, err := importer.NewImporter(repository, *batchSize, *tenant, logger).Run(ctx, input, failures)
+	logger.Info("import: done", "read", res.Read, "imported", res.Imported, "failed", res.Failed, "errors_file", *errorsPath)
+
+	return err
+}
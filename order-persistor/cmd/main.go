@@ -1,18 +1,14 @@
 package main
 
 import (
-	"context"
 	"flag"
+	"fmt"
 	"log/slog"
-	"order-persistor/internal/api"
 	"order-persistor/internal/config"
-	"order-persistor/internal/inmemory"
-	"order-persistor/internal/kafka"
+	"order-persistor/internal/envelope"
 	"order-persistor/internal/log"
 	"order-persistor/internal/postgres"
 	"os"
-	"os/signal"
-	"time"
 
 	"github.com/jackc/pgx/v5/pgxpool"
 )
@@ -23,95 +19,69 @@ func init() {
 	flag.StringVar(&configPath, "config", "config.yaml", "path to config file (e.g. config.yaml)")
 }
 
+// commands are invoked as `order-persistor <command> [flags]`.
+// Running without a command starts the service.
+var commands = map[string]func(args []string) error{
+	"archive":   runArchive,
+	"import":    runImport,
+	"reencrypt": runReencrypt,
+}
+
 func main() {
+	if len(os.Args) > 1 {
+		if cmd, ok := commands[os.Args[1]]; ok {
+			if err := cmd(os.Args[2:]); err != nil {
+				slog.Error("command failed", "command", os.Args[1], "err", err)
+				os.Exit(1)
+			}
+
+			return
+		}
+	}
+
 	flag.Parse()
+	serve()
+}
 
-	cfgFile, err := os.Open(configPath)
+func loadConfig(path string) (*config.Config, *slog.Logger, error) {
+	cfgFile, err := os.Open(path)
 	if err != nil {
-		slog.Error("could not open config file", "err", err)
-		os.Exit(1)
+		return nil, nil, fmt.Errorf("could not open config file: %w", err)
 	}
 	defer cfgFile.Close()
 
 	var cfg config.Config
 	if err := config.LoadAndValidate(cfgFile, &cfg); err != nil {
-		slog.Error("failure loading config", "err", err)
-		os.Exit(1)
+		return nil, nil, err
 	}
 
 	logger, err := log.New(cfg.Log)
 	if err != nil {
-		slog.Error("could not create logger", "err", err)
-		os.Exit(1)
-	}
-
-	pool, err := pgxpool.New(context.Background(), cfg.Postgres.ConnString)
-	if err != nil {
-		logger.Error("creating pg pool", "err", err)
-		return
+		return nil, nil, fmt.Errorf("could not create logger: %w", err)
 	}
-	defer pool.Close()
 
-	paymentsDAO := postgres.PaymentsDAO{
-		Pool: pool,
-	}
+	return &cfg, logger, nil
+}
 
-	itemsDAO := postgres.ItemsDAO{
+func newOrdersRepository(cfg *config.Config, pool *pgxpool.Pool) (*postgres.OrdersRepository, error) {
+	repository := &postgres.OrdersRepository{
+		ItemsDAO: &postgres.ItemsDAO{
+			Pool: pool,
+		},
+		PaymentsDAO: &postgres.PaymentsDAO{
+			Pool: pool,
+		},
 		Pool: pool,
 	}
 
-	ordersRepository := postgres.OrdersRepository{
-		ItemsDAO:    &itemsDAO,
-		PaymentsDAO: &paymentsDAO,
-		Pool:        pool,
-	}
-
-	cachingOrdersRepository, err := inmemory.NewOrdersCache(cfg.Cache, &ordersRepository, logger)
-	if err != nil {
-		logger.Error("creating orders cache", "err", err)
-		return
-	}
-
-	ordersConsumer, err := kafka.NewOrdersConsumer(cfg.KafkaConsumer, cachingOrdersRepository, logger)
-	if err != nil {
-		logger.Error("failure creating order consumer", "err", err)
-		return
-	}
-
-	srv := api.NewServer(cfg.API, api.Params{
-		Logger:           logger,
-		OrdersRepository: cachingOrdersRepository,
-	})
-
-	ctx, cancel := signal.NotifyContext(context.Background(), os.Kill)
-	defer cancel()
-
-	if cfg.Prefill.Enabled {
-		ctx, cancel := context.WithTimeout(ctx, cfg.Prefill.Timeout)
-		defer cancel()
-
-		if err := cachingOrdersRepository.Prefill(ctx); err != nil {
-			logger.Error("error pre-filling orders cache", "err", err)
-			os.Exit(1)
+	if cfg.Encryption.Enabled {
+		keys, err := envelope.LoadKeyFile(cfg.Encryption.KeyFile)
+		if err != nil {
+			return nil, err
 		}
-	}
 
-	go func() {
-		err := srv.ListenAndServe()
-		logger.Info("api server stopped", "err", err)
-		cancel()
-	}()
-
-	go func() {
-		err := ordersConsumer.Run(ctx)
-		logger.Info("kafka consumer stopped", "err", err)
-		cancel()
-	}()
-
-	<-ctx.Done()
-	logger.Info("shutting down...")
+		repository.Cipher = envelope.NewCipher(keys, keys.IndexKey())
+	}
 
-	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
-	srv.Shutdown(shutdownCtx)
+	return repository, nil
 }
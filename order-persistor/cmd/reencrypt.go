@@ -0,0 +1,73 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"order-persistor/internal/orders"
+	"os"
+	"os/signal"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// runReencrypt encrypts delivery data of the stored orders with the current key,
+// either after key rotation or after encryption was enabled for orders stored in plain text.
+func runReencrypt(args []string) error {
+	fs := flag.NewFlagSet("reencrypt", flag.ExitOnError)
+	configPath := fs.String("config", "config.yaml", "path to config file (e.g. config.yaml)")
+	batchSize := fs.Int("batch", 500, "count of orders re-encrypted in a single transaction")
+	all := fs.Bool("all", false, "re-encrypt orders already encrypted with the current key too, e.g. to recompute blind indexes")
+	fs.Parse(args)
+
+	if *batchSize <= 0 {
+		return errors.New("batch should be > 0")
+	}
+
+	cfg, logger, err := loadConfig(*configPath)
+	if err != nil {
+		return err
+	}
+
+	if !cfg.Encryption.Enabled {
+		return errors.New("encryption is not enabled")
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+
+	pool, err := pgxpool.New(ctx, cfg.Postgres.ConnString)
+	if err != nil {
+		return fmt.Errorf("creating pg pool: %w", err)
+	}
+	defer pool.Close()
+
+	repository, err := newOrdersRepository(cfg, pool)
+	if err != nil {
+		return err
+	}
+
+	var (
+		cursor orders.Cursor
+		total  int
+	)
+
+	for {
+		next, n, err := repository.ReencryptBatch(ctx, cursor, *batchSize, *all)
+		if err != nil {
+			return fmt.Errorf("re-encrypting orders after %s: %w", cursor.ID, err)
+		}
+
+		cursor = next
+		total += n
+		logger.Info("reencrypt: batch written", "reencrypted", total, "key_id", repository.Cipher.CurrentKeyID())
+
+		if n < *batchSize {
+			break
+		}
+	}
+
+	logger.Info("reencrypt: done", "reencrypted", total)
+	return nil
+}
@@ -0,0 +1,232 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"log/slog"
+	"order-persistor/internal/api"
+	"order-persistor/internal/broadcast"
+	"order-persistor/internal/gdpr"
+	"order-persistor/internal/grpcapi"
+	"order-persistor/internal/inmemory"
+	"order-persistor/internal/invalidation"
+	"order-persistor/internal/kafka"
+	"order-persistor/internal/orders"
+	"order-persistor/internal/postgres"
+	"order-persistor/internal/redact"
+	"order-persistor/internal/retention"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// serve runs the service: kafka consumer, http and grpc apis and background jobs.
+func serve() {
+	cfg, logger, err := loadConfig(configPath)
+	if err != nil {
+		slog.Error("failure loading config", "err", err)
+		os.Exit(1)
+	}
+
+	pool, err := pgxpool.New(context.Background(), cfg.Postgres.ConnString)
+	if err != nil {
+		logger.Error("creating pg pool", "err", err)
+		return
+	}
+	defer pool.Close()
+
+	ordersRepository, err := newOrdersRepository(cfg, pool)
+	if err != nil {
+		logger.Error("creating orders repository", "err", err)
+		return
+	}
+
+	if cfg.Postgres.Replica.ConnString != "" {
+		replicaPool, err := pgxpool.New(context.Background(), cfg.Postgres.Replica.ConnString)
+		if err != nil {
+			logger.Error("creating pg replica pool", "err", err)
+			return
+		}
+		defer replicaPool.Close()
+
+		ordersRepository.Replica = postgres.NewReplica(replicaPool, cfg.Postgres.Replica, logger)
+	}
+
+	var persistingRepository orders.Repository = ordersRepository
+	var invalidationChannel invalidation.Channel
+	origin := invalidation.NewOrigin()
+
+	if cfg.Invalidation.Enabled {
+		switch cfg.Invalidation.Backend {
+		case "postgres":
+			invalidationChannel = &postgres.InvalidationChannel{
+				Pool:    pool,
+				Channel: cfg.Invalidation.Channel,
+			}
+		case "kafka":
+			ch, err := kafka.NewInvalidationChannel(context.Background(), cfg.Invalidation.Kafka, origin)
+			if err != nil {
+				logger.Error("creating kafka invalidation channel", "err", err)
+				return
+			}
+			defer ch.Close()
+
+			invalidationChannel = ch
+		}
+
+		persistingRepository = invalidation.NewPublishingRepository(ordersRepository, invalidationChannel, origin, logger)
+	}
+
+	cachingOrdersRepository, err := inmemory.NewOrdersCache(cfg.Cache, persistingRepository, logger)
+	if err != nil {
+		logger.Error("creating orders cache", "err", err)
+		return
+	}
+
+	logRedaction, err := redact.NewPolicy(redact.Mode(cmp.Or(cfg.Redaction.Logs, string(redact.Partial))), cfg.Redaction.HashKey)
+	if err != nil {
+		logger.Error("creating log redaction policy", "err", err)
+		return
+	}
+
+	responseRedaction, err := redact.NewPolicy(redact.Mode(cmp.Or(cfg.Redaction.Responses, string(redact.Remove))), cfg.Redaction.HashKey)
+	if err != nil {
+		logger.Error("creating response redaction policy", "err", err)
+		return
+	}
+
+	hub := broadcast.NewHub(cfg.Broadcast.Buffer, cfg.Broadcast.Replay)
+	consumedRepository := broadcast.NewPublishingRepository(cachingOrdersRepository, hub)
+
+	ordersConsumer, err := kafka.NewOrdersConsumer(cfg.KafkaConsumer, consumedRepository, logRedaction, logger)
+	if err != nil {
+		logger.Error("failure creating order consumer", "err", err)
+		return
+	}
+
+	var ready func() bool
+	if cfg.Prefill.Enabled && cfg.Prefill.GateReadiness {
+		ready = cachingOrdersRepository.PrefillFinished
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Kill)
+	defer cancel()
+
+	authenticator, err := api.NewAuthenticator(ctx, cfg.API.Auth)
+	if err != nil {
+		logger.Error("creating api authenticator", "err", err)
+		return
+	}
+
+	var (
+		evictor gdpr.Evictor   = cachingOrdersRepository
+		replay  gdpr.Forgetter = hub
+	)
+
+	if invalidationChannel != nil {
+		evictor = invalidation.NewPublishingEvictor(cachingOrdersRepository, invalidationChannel, origin, logger)
+		replay = invalidation.NewPublishingForgetter(hub, invalidationChannel, origin, logger)
+	}
+
+	tlsConfig, err := api.NewTLSConfig(cfg.API.TLS, logger)
+	if err != nil {
+		logger.Error("creating api tls config", "err", err)
+		return
+	}
+
+	srv := api.NewServer(cfg.API, api.Params{
+		Logger:           logger,
+		OrdersRepository: cachingOrdersRepository,
+		GDPR:             gdpr.NewService(ordersRepository, evictor, replay, cfg.Redaction.HashKey, logger),
+		Hub:              hub,
+		Redaction:        responseRedaction,
+		Authenticator:    authenticator,
+		Cached:           cachingOrdersRepository.Contains,
+		TLSConfig:        tlsConfig,
+		Ready:            ready,
+	})
+
+	var grpcSrv *grpcapi.Server
+	if cfg.GRPC.Enabled {
+		grpcSrv = grpcapi.NewServer(cfg.GRPC, grpcapi.Params{
+			Logger:           logger,
+			OrdersRepository: cachingOrdersRepository,
+			Hub:              hub,
+			Redaction:        responseRedaction,
+			Authenticator:    authenticator,
+			TLSConfig:        tlsConfig,
+		})
+	}
+
+	if cfg.Prefill.Enabled {
+		go func() {
+			ctx, cancel := context.WithTimeout(ctx, cfg.Prefill.Timeout)
+			defer cancel()
+
+			// failed prefill is not fatal: cache misses are served from postgres anyway
+			if err := cachingOrdersRepository.Prefill(ctx, cfg.Prefill.PageSize); err != nil {
+				logger.Error("error pre-filling orders cache", "err", err)
+			}
+		}()
+	}
+
+	go func() {
+		var err error
+		if srv.TLSConfig != nil {
+			// certificate is provided by the tls config
+			err = srv.ListenAndServeTLS("", "")
+		} else {
+			err = srv.ListenAndServe()
+		}
+
+		logger.Info("api server stopped", "err", err)
+		cancel()
+	}()
+
+	if grpcSrv != nil {
+		go func() {
+			err := grpcSrv.ListenAndServe()
+			logger.Info("grpc server stopped", "err", err)
+			cancel()
+		}()
+	}
+
+	go func() {
+		err := ordersConsumer.Run(ctx)
+		logger.Info("kafka consumer stopped", "err", err)
+		cancel()
+	}()
+
+	retentionJob := retention.NewJob(cfg.Retention, &postgres.Partitions{Pool: pool}, logger)
+	go func() {
+		err := retentionJob.Run(ctx)
+		logger.Info("retention job stopped", "err", err)
+	}()
+
+	if ordersRepository.Replica != nil {
+		go func() {
+			err := ordersRepository.Replica.Run(ctx)
+			logger.Info("replica health checking stopped", "err", err)
+		}()
+	}
+
+	if invalidationChannel != nil {
+		go func() {
+			err := invalidation.Run(ctx, invalidationChannel, origin, cachingOrdersRepository, hub, cfg.Invalidation.RetryBackoff, logger)
+			logger.Info("cache invalidation stopped", "err", err)
+		}()
+	}
+
+	<-ctx.Done()
+	logger.Info("shutting down...")
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	srv.Shutdown(shutdownCtx)
+
+	if grpcSrv != nil {
+		grpcSrv.Shutdown(shutdownCtx)
+	}
+}
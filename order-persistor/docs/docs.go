@@ -15,14 +15,168 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
+        "/admin/gdpr/erase": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Anonymizes delivery name, phone, zip, address and email of all the orders of the customer found by either customer_id or delivery email.\nOrders with their items and payments are kept. Erased orders are evicted from the cache and the erasure is recorded in the audit.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "gdpr"
+                ],
+                "summary": "Erase personal data of a data subject",
+                "parameters": [
+                    {
+                        "description": "Exactly one of customer_id and email",
+                        "name": "subject",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/gdpr.Subject"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.GDPRErasure"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid subject",
+                        "schema": {
+                            "$ref": "#/definitions/api.Error"
+                        }
+                    },
+                    "401": {
+                        "description": "Not authenticated",
+                        "schema": {
+                            "$ref": "#/definitions/api.Error"
+                        }
+                    },
+                    "403": {
+                        "description": "Missing orders:admin scope",
+                        "schema": {
+                            "$ref": "#/definitions/api.Error"
+                        }
+                    },
+                    "429": {
+                        "description": "Rate limit exceeded, see Retry-After header",
+                        "schema": {
+                            "$ref": "#/definitions/api.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/api.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/gdpr/export": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns all the orders of the customer found by either customer_id or delivery email. The export is recorded in the audit.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "gdpr"
+                ],
+                "summary": "Export orders of a data subject",
+                "parameters": [
+                    {
+                        "description": "Exactly one of customer_id and email",
+                        "name": "subject",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/gdpr.Subject"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.GDPRExport"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid subject",
+                        "schema": {
+                            "$ref": "#/definitions/api.Error"
+                        }
+                    },
+                    "401": {
+                        "description": "Not authenticated",
+                        "schema": {
+                            "$ref": "#/definitions/api.Error"
+                        }
+                    },
+                    "403": {
+                        "description": "Missing orders:admin scope",
+                        "schema": {
+                            "$ref": "#/definitions/api.Error"
+                        }
+                    },
+                    "429": {
+                        "description": "Rate limit exceeded, see Retry-After header",
+                        "schema": {
+                            "$ref": "#/definitions/api.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/api.Error"
+                        }
+                    }
+                }
+            }
+        },
         "/order/{id}": {
             "get": {
-                "description": "Returns the order object for the specified ID",
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns the order object for the specified ID.\nPersonal data of the customer is masked unless the caller has the orders:read:pii scope.\nThe representation is negotiated by the Accept header: JSON (application/json, indented with\napplication/json;pretty=true), item lines in CSV (text/csv) or the orders.v1.Order protobuf message\n(application/x-protobuf).",
                 "consumes": [
                     "application/json"
                 ],
                 "produces": [
-                    "application/json"
+                    "application/json",
+                    "text/csv",
+                    "application/x-protobuf"
                 ],
                 "tags": [
                     "orders"
@@ -35,17 +189,56 @@ const docTemplate = `{
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated fields to return, like id,payment.amount,items. Nested fields are dotted, id is order_uid",
+                        "name": "fields",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Tenant whose orders are read, default if omitted",
+                        "name": "X-Tenant",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the cached order",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Last-Modified of the cached order",
+                        "name": "If-Modified-Since",
+                        "in": "header"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/orders.Order"
+                            "$ref": "#/definitions/orderschema.Order"
                         }
                     },
+                    "304": {
+                        "description": "Cached order is current"
+                    },
                     "400": {
-                        "description": "Invalid request",
+                        "description": "Invalid request or unknown field",
+                        "schema": {
+                            "$ref": "#/definitions/api.Error"
+                        }
+                    },
+                    "401": {
+                        "description": "Not authenticated",
+                        "schema": {
+                            "$ref": "#/definitions/api.Error"
+                        }
+                    },
+                    "403": {
+                        "description": "Missing orders:read scope or tenant is not allowed",
                         "schema": {
                             "$ref": "#/definitions/api.Error"
                         }
@@ -56,11 +249,127 @@ const docTemplate = `{
                             "$ref": "#/definitions/api.Error"
                         }
                     },
+                    "406": {
+                        "description": "No acceptable representation",
+                        "schema": {
+                            "$ref": "#/definitions/api.Error"
+                        }
+                    },
+                    "429": {
+                        "description": "Rate limit exceeded, see Retry-After header",
+                        "schema": {
+                            "$ref": "#/definitions/api.Error"
+                        }
+                    },
                     "500": {
                         "description": "Internal server error",
                         "schema": {
                             "$ref": "#/definitions/api.Error"
                         }
+                    },
+                    "504": {
+                        "description": "Lookup did not finish within api.timeout",
+                        "schema": {
+                            "$ref": "#/definitions/api.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/orders/stream": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Pushes the orders persisted by this instance as server-sent events named \"order\", each with\nthe order JSON as data. Send the ID of the last received event in the Last-Event-ID header\nto resume: the recent orders published after it are sent first. Clients falling behind are\ndisconnected and resume the same way.\nPersonal data of the customer is masked unless the caller has the orders:read:pii scope.",
+                "produces": [
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "orders"
+                ],
+                "summary": "Stream orders",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Stream only orders of the customer",
+                        "name": "customer_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Stream only orders of the delivery service",
+                        "name": "delivery_service",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID of the last received event",
+                        "name": "Last-Event-ID",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Tenant whose orders are streamed, default if omitted",
+                        "name": "X-Tenant",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Stream of order events",
+                        "schema": {
+                            "$ref": "#/definitions/orderschema.Order"
+                        }
+                    },
+                    "401": {
+                        "description": "Not authenticated",
+                        "schema": {
+                            "$ref": "#/definitions/api.Error"
+                        }
+                    },
+                    "403": {
+                        "description": "Missing orders:read scope or tenant is not allowed",
+                        "schema": {
+                            "$ref": "#/definitions/api.Error"
+                        }
+                    },
+                    "429": {
+                        "description": "Rate limit exceeded, see Retry-After header",
+                        "schema": {
+                            "$ref": "#/definitions/api.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/api.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/schema/order.json": {
+            "get": {
+                "description": "Returns the JSON Schema (draft-07) of order messages consumed from kafka",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "schema"
+                ],
+                "summary": "Get order JSON Schema",
+                "responses": {
+                    "200": {
+                        "description": "JSON Schema",
+                        "schema": {
+                            "type": "object"
+                        }
                     }
                 }
             }
@@ -75,7 +384,46 @@ const docTemplate = `{
                 }
             }
         },
-        "orders.Delivery": {
+        "api.GDPRErasure": {
+            "type": "object",
+            "properties": {
+                "anonymized": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "api.GDPRExport": {
+            "type": "object",
+            "properties": {
+                "exported_at": {
+                    "type": "string"
+                },
+                "orders": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/orderschema.Order"
+                    }
+                },
+                "subject": {
+                    "$ref": "#/definitions/gdpr.Subject"
+                }
+            }
+        },
+        "gdpr.Subject": {
+            "type": "object",
+            "properties": {
+                "customer_id": {
+                    "type": "string"
+                },
+                "email": {
+                    "type": "string"
+                }
+            }
+        },
+        "orderschema.Delivery": {
             "type": "object",
             "required": [
                 "address",
@@ -110,7 +458,7 @@ const docTemplate = `{
                 }
             }
         },
-        "orders.Item": {
+        "orderschema.Item": {
             "type": "object",
             "properties": {
                 "brand": {
@@ -148,7 +496,7 @@ const docTemplate = `{
                 }
             }
         },
-        "orders.Order": {
+        "orderschema.Order": {
             "type": "object",
             "required": [
                 "customer_id",
@@ -170,7 +518,7 @@ const docTemplate = `{
                     "type": "string"
                 },
                 "delivery": {
-                    "$ref": "#/definitions/orders.Delivery"
+                    "$ref": "#/definitions/orderschema.Delivery"
                 },
                 "delivery_service": {
                     "type": "string"
@@ -184,7 +532,7 @@ const docTemplate = `{
                 "items": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/orders.Item"
+                        "$ref": "#/definitions/orderschema.Item"
                     }
                 },
                 "locale": {
@@ -197,7 +545,7 @@ const docTemplate = `{
                     "type": "string"
                 },
                 "payment": {
-                    "$ref": "#/definitions/orders.Payment"
+                    "$ref": "#/definitions/orderschema.Payment"
                 },
                 "shardkey": {
                     "type": "string"
@@ -210,7 +558,7 @@ const docTemplate = `{
                 }
             }
         },
-        "orders.Payment": {
+        "orderschema.Payment": {
             "type": "object",
             "required": [
                 "amount",
@@ -256,6 +604,19 @@ const docTemplate = `{
                 }
             }
         }
+    },
+    "securityDefinitions": {
+        "ApiKeyAuth": {
+            "type": "apiKey",
+            "name": "X-API-Key",
+            "in": "header"
+        },
+        "BearerAuth": {
+            "description": "JWT as \"Bearer \u003ctoken\u003e\"",
+            "type": "apiKey",
+            "name": "Authorization",
+            "in": "header"
+        }
     }
 }`
 
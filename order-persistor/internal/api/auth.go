@@ -0,0 +1,172 @@
+package api
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"net/http"
+	"order-persistor/internal/config"
+	"slices"
+)
+
+// Scopes of API access. Orders are returned without personal data of customers unless ScopeReadPII is granted.
+// ScopeAdmin grants export and erasure of personal data, it is never granted to Anonymous callers.
+const (
+	ScopeRead    = "orders:read"
+	ScopeReadPII = "orders:read:pii"
+	ScopeAdmin   = "orders:admin"
+)
+
+const apiKeyHeader = "X-API-Key"
+
+var (
+	// errNoCredentials means the request has no credentials of the authenticator, so the next one is tried.
+	errNoCredentials      = errors.New("no credentials")
+	errInvalidCredentials = errors.New("invalid credentials")
+
+	responseUnauthorized = newErrorResponse(401, "Unauthorized")
+	responseForbidden    = newErrorResponse(403, "Forbidden")
+)
+
+// Principal is the authenticated caller.
+type Principal struct {
+	Name string
+	// Method is the way the caller was authenticated: api_key, jwt or none.
+	Method string
+	Scopes []string
+	// Tenant limits the caller to the orders of a single tenant, if set.
+	Tenant string
+}
+
+func (p *Principal) HasScope(scope string) bool {
+	return slices.Contains(p.Scopes, scope)
+}
+
+// Anonymous is the principal of all the requests when authentication is disabled.
+var Anonymous = &Principal{
+	Name:   "anonymous",
+	Method: "none",
+	Scopes: []string{ScopeRead, ScopeReadPII},
+}
+
+type principalKey struct{}
+
+// PrincipalFrom returns the caller authenticated by the auth middleware.
+func PrincipalFrom(ctx context.Context) *Principal {
+	p, _ := ctx.Value(principalKey{}).(*Principal)
+	return p
+}
+
+// Authenticator identifies the caller of the request.
+// It returns errNoCredentials if the request carries none of the credentials it checks.
+type Authenticator interface {
+	Authenticate(r *http.Request) (*Principal, error)
+}
+
+// Authenticators tries each of the authenticators in order until one finds its credentials in the request.
+type Authenticators []Authenticator
+
+func (a Authenticators) Authenticate(r *http.Request) (*Principal, error) {
+	for _, auth := range a {
+		p, err := auth.Authenticate(r)
+		if errors.Is(err, errNoCredentials) {
+			continue
+		}
+
+		return p, err
+	}
+
+	return nil, errNoCredentials
+}
+
+// NewAuthenticator creates the authenticator of the configured methods, or nil if auth is disabled.
+// Keys of a JWKS URL are refreshed in background until ctx is done.
+func NewAuthenticator(ctx context.Context, cfg config.Auth) (Authenticator, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+
+	var res Authenticators
+	if len(cfg.APIKeys) > 0 {
+		res = append(res, NewAPIKeyAuthenticator(cfg.APIKeys))
+	}
+
+	if cfg.JWT.JWKSFile != "" || cfg.JWT.JWKSURL != "" {
+		jwtAuth, err := NewJWTAuthenticator(ctx, cfg.JWT)
+		if err != nil {
+			return nil, fmt.Errorf("could not create jwt authenticator: %w", err)
+		}
+
+		res = append(res, jwtAuth)
+	}
+
+	return res, nil
+}
+
+// APIKeyAuthenticator accepts static keys sent in the X-API-Key header.
+type APIKeyAuthenticator struct {
+	// keys are indexed by their digests, so lookup time does not depend on how much of a key matches
+	keys map[[sha256.Size]byte]*Principal
+}
+
+func NewAPIKeyAuthenticator(keys []config.APIKey) *APIKeyAuthenticator {
+	a := &APIKeyAuthenticator{
+		keys: make(map[[sha256.Size]byte]*Principal, len(keys)),
+	}
+
+	for _, k := range keys {
+		a.keys[sha256.Sum256([]byte(k.Key))] = &Principal{
+			Name:   k.Name,
+			Method: "api_key",
+			Scopes: k.Scopes,
+			Tenant: k.Tenant,
+		}
+	}
+
+	return a
+}
+
+func (a *APIKeyAuthenticator) Authenticate(r *http.Request) (*Principal, error) {
+	key := r.Header.Get(apiKeyHeader)
+	if key == "" {
+		return nil, errNoCredentials
+	}
+
+	p, ok := a.keys[sha256.Sum256([]byte(key))]
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown api key", errInvalidCredentials)
+	}
+
+	return p, nil
+}
+
+// NewAuthMiddleware authenticates requests and lets through only the callers with the scope.
+// Failures are reported to the log middleware. Requests are not authenticated if a is nil.
+func NewAuthMiddleware(a Authenticator, scope string) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			p := Anonymous
+			if a != nil {
+				var err error
+				p, err = a.Authenticate(r)
+				if err != nil {
+					addLogFields(r.Context(), "auth_error", err.Error())
+					w.Header().Set("WWW-Authenticate", `Bearer, ApiKey`)
+					responseUnauthorized.Write(w)
+					return
+				}
+			}
+
+			addLogFields(r.Context(), "principal", p.Name, "auth_method", p.Method)
+
+			if !p.HasScope(scope) {
+				addLogFields(r.Context(), "auth_error", "missing scope "+scope)
+				responseForbidden.Write(w)
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), principalKey{}, p)))
+		})
+	}
+}
@@ -0,0 +1,174 @@
+package api
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"order-persistor/internal/config"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// writeJWKS writes the public part of the key as a JWKS file with the kid.
+func writeJWKS(t *testing.T, key *rsa.PrivateKey, kid string) string {
+	t.Helper()
+
+	jwks := map[string]any{
+		"keys": []map[string]string{{
+			"kty": "RSA",
+			"alg": "RS256",
+			"use": "sig",
+			"kid": kid,
+			"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}},
+	}
+
+	raw, err := json.Marshal(jwks)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	if err := os.WriteFile(path, raw, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	return path
+}
+
+func signToken(t *testing.T, key *rsa.PrivateKey, kid string, claims jwt.MapClaims) string {
+	t.Helper()
+
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = kid
+
+	signed, err := token.SignedString(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return signed
+}
+
+func TestAuthMiddleware(t *testing.T) {
+	t.Parallel()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	auth, err := NewAuthenticator(context.Background(), config.Auth{
+		Enabled: true,
+		APIKeys: []config.APIKey{
+			{Name: "support", Key: "support-key-0123456789", Scopes: []string{ScopeRead, ScopeReadPII}},
+			{Name: "metrics", Key: "metrics-key-0123456789"},
+		},
+		JWT: config.JWT{
+			JWKSFile:    writeJWKS(t, key, "test"),
+			Issuer:      "https://auth.example.com",
+			Audience:    "order-persistor",
+			TenantClaim: "tenant",
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	claims := func(scope any) jwt.MapClaims {
+		return jwt.MapClaims{
+			"sub":    "client",
+			"iss":    "https://auth.example.com",
+			"aud":    "order-persistor",
+			"exp":    time.Now().Add(time.Hour).Unix(),
+			"scope":  scope,
+			"tenant": "acme",
+		}
+	}
+
+	expired := claims(ScopeRead)
+	expired["exp"] = time.Now().Add(-time.Hour).Unix()
+
+	foreignAudience := claims(ScopeRead)
+	foreignAudience["aud"] = "another-service"
+
+	cases := []struct {
+		name    string
+		header  string
+		value   string
+		code    int
+		subject string
+		tenant  string
+	}{
+		{name: "no credentials", code: 401},
+		{name: "unknown api key", header: apiKeyHeader, value: "unknown-key-0123456789", code: 401},
+		{name: "api key", header: apiKeyHeader, value: "support-key-0123456789", code: 200, subject: "support"},
+		{name: "api key without scope", header: apiKeyHeader, value: "metrics-key-0123456789", code: 403},
+		{
+			name:    "jwt with string scope",
+			header:  "Authorization",
+			value:   "Bearer " + signToken(t, key, "test", claims(ScopeRead+" "+ScopeReadPII)),
+			code:    200,
+			subject: "client",
+			tenant:  "acme",
+		},
+		{
+			name:    "jwt with array scope",
+			header:  "Authorization",
+			value:   "Bearer " + signToken(t, key, "test", claims([]string{ScopeRead})),
+			code:    200,
+			subject: "client",
+			tenant:  "acme",
+		},
+		{name: "jwt without scope", header: "Authorization", value: "Bearer " + signToken(t, key, "test", claims("")), code: 403},
+		{name: "expired jwt", header: "Authorization", value: "Bearer " + signToken(t, key, "test", expired), code: 401},
+		{name: "jwt of another audience", header: "Authorization", value: "Bearer " + signToken(t, key, "test", foreignAudience), code: 401},
+		{name: "jwt signed by unknown key", header: "Authorization", value: "Bearer " + signToken(t, otherKey, "test", claims(ScopeRead)), code: 401},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var principal *Principal
+			h := NewAuthMiddleware(auth, ScopeRead)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				principal = PrincipalFrom(r.Context())
+			}))
+
+			r := httptest.NewRequest(http.MethodGet, "/order/1", nil)
+			if tc.header != "" {
+				r.Header.Set(tc.header, tc.value)
+			}
+
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+
+			if w.Code != tc.code {
+				t.Fatalf("expected status %d, got %d", tc.code, w.Code)
+			}
+
+			if tc.code != 200 {
+				return
+			}
+
+			if principal.Name != tc.subject || principal.Tenant != tc.tenant {
+				t.Fatalf("unexpected principal: %+v", principal)
+			}
+		})
+	}
+}
@@ -0,0 +1,138 @@
+package api
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"order-persistor/internal/config"
+	"order-persistor/internal/mocks"
+	"order-persistor/internal/orders"
+	"order-persistor/internal/redact"
+	"testing"
+	"time"
+
+	"go.uber.org/mock/gomock"
+
+	"github.com/lezzercringe/some-assignment/orderschema"
+)
+
+func TestGetOrderHandler_conditional(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	rep := mocks.NewMockRepository(ctrl)
+
+	order := &orders.Order{Order: orderschema.Order{ID: "b563feb7b2b84b6test", CreatedAt: time.Date(2026, 10, 19, 12, 0, 0, 500, time.UTC)}}
+	rep.EXPECT().GetByID(gomock.Any(), order.ID).Return(order, nil).Times(5)
+
+	redaction, _ := redact.NewPolicy(redact.Remove, "")
+	srv := NewServer(config.API{Timeout: time.Second, Compression: true}, Params{
+		Logger:           slog.New(slog.DiscardHandler),
+		OrdersRepository: rep,
+		Redaction:        redaction,
+	})
+
+	request := func(header ...string) *http.Response {
+		r := httptest.NewRequest(http.MethodGet, "/order/"+order.ID, nil)
+		for i := 0; i < len(header); i += 2 {
+			r.Header.Set(header[i], header[i+1])
+		}
+
+		w := httptest.NewRecorder()
+		srv.Handler.ServeHTTP(w, r)
+		return w.Result()
+	}
+
+	resp := request("Accept-Encoding", "gzip")
+	if resp.StatusCode != 200 || resp.Header.Get("Content-Encoding") != "gzip" {
+		t.Fatalf("expected gzip compressed order, got %d %q", resp.StatusCode, resp.Header.Get("Content-Encoding"))
+	}
+
+	body, err := gzip.NewReader(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got orders.Order
+	if err := json.NewDecoder(body).Decode(&got); err != nil || got.ID != order.ID {
+		t.Fatalf("unexpected order %+v, %v", got, err)
+	}
+
+	etag := resp.Header.Get("ETag")
+	if etag == "" {
+		t.Fatal("expected ETag")
+	}
+
+	if lm := resp.Header.Get("Last-Modified"); lm != "Mon, 19 Oct 2026 12:00:00 GMT" {
+		t.Errorf("unexpected Last-Modified %q", lm)
+	}
+
+	if cc := resp.Header.Get("Cache-Control"); cc != defaultCacheControl {
+		t.Errorf("unexpected Cache-Control %q", cc)
+	}
+
+	if resp := request("If-None-Match", `"other", `+etag); resp.StatusCode != http.StatusNotModified {
+		t.Errorf("expected 304 for matching ETag, got %d", resp.StatusCode)
+	} else if b, _ := io.ReadAll(resp.Body); len(b) != 0 {
+		t.Errorf("expected no body, got %q", b)
+	}
+
+	// If-Modified-Since is ignored along with If-None-Match
+	if resp := request("If-None-Match", `"other"`, "If-Modified-Since", "Mon, 19 Oct 2026 12:00:00 GMT"); resp.StatusCode != 200 {
+		t.Errorf("expected 200 for stale ETag, got %d", resp.StatusCode)
+	}
+
+	if resp := request("If-Modified-Since", "Mon, 19 Oct 2026 12:00:00 GMT"); resp.StatusCode != http.StatusNotModified {
+		t.Errorf("expected 304 for unmodified order, got %d", resp.StatusCode)
+	}
+
+	if resp := request("If-Modified-Since", "Mon, 19 Oct 2026 11:59:59 GMT"); resp.StatusCode != 200 {
+		t.Errorf("expected 200 for order modified since, got %d", resp.StatusCode)
+	}
+}
+
+func TestGetOrderHandler_conditionalAnonymized(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	rep := mocks.NewMockRepository(ctrl)
+
+	order := &orders.Order{
+		Order:        orderschema.Order{ID: "b563feb7b2b84b6test", CreatedAt: time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)},
+		AnonymizedAt: time.Date(2026, 10, 20, 9, 30, 0, 0, time.UTC),
+	}
+	rep.EXPECT().GetByID(gomock.Any(), order.ID).Return(order, nil).Times(2)
+
+	redaction, _ := redact.NewPolicy(redact.Remove, "")
+	srv := NewServer(config.API{Timeout: time.Second}, Params{
+		Logger:           slog.New(slog.DiscardHandler),
+		OrdersRepository: rep,
+		Redaction:        redaction,
+	})
+
+	request := func(ifModifiedSince string) *http.Response {
+		r := httptest.NewRequest(http.MethodGet, "/order/"+order.ID, nil)
+		r.Header.Set("If-Modified-Since", ifModifiedSince)
+
+		w := httptest.NewRecorder()
+		srv.Handler.ServeHTTP(w, r)
+		return w.Result()
+	}
+
+	// the erasure changed the order after the client cached it
+	resp := request("Mon, 19 Oct 2026 12:00:00 GMT")
+	if resp.StatusCode != 200 {
+		t.Fatalf("expected 200 for order anonymized since, got %d", resp.StatusCode)
+	}
+
+	if lm := resp.Header.Get("Last-Modified"); lm != "Tue, 20 Oct 2026 09:30:00 GMT" {
+		t.Errorf("unexpected Last-Modified %q", lm)
+	}
+
+	if resp := request("Tue, 20 Oct 2026 09:30:00 GMT"); resp.StatusCode != http.StatusNotModified {
+		t.Errorf("expected 304 for unmodified order, got %d", resp.StatusCode)
+	}
+}
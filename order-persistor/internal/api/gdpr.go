@@ -0,0 +1,149 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"order-persistor/internal/gdpr"
+	"order-persistor/internal/orders"
+	"time"
+
+	"github.com/lezzercringe/some-assignment/orderschema"
+)
+
+// maxSubjectSize limits the body of data subject requests.
+const maxSubjectSize = 4 << 10
+
+// GDPRExport is the data subject export.
+type GDPRExport struct {
+	Subject    gdpr.Subject        `json:"subject"`
+	ExportedAt time.Time           `json:"exported_at"`
+	Orders     []orderschema.Order `json:"orders"`
+}
+
+// GDPRErasure lists the orders whose personal data was erased.
+type GDPRErasure struct {
+	Anonymized []string `json:"anonymized"`
+}
+
+type GDPRExportHandler struct {
+	Logger  *slog.Logger
+	Service *gdpr.Service
+}
+
+// ExportSubjectData godoc
+// @Summary Export orders of a data subject
+// @Description Returns all the orders of the customer found by either customer_id or delivery email. The export is recorded in the audit.
+// @Tags gdpr
+// @Accept json
+// @Produce json
+// @Param subject body gdpr.Subject true "Exactly one of customer_id and email"
+// @Success 200 {object} GDPRExport
+// @Failure 400 {object} Error "Invalid subject"
+// @Failure 401 {object} Error "Not authenticated"
+// @Failure 403 {object} Error "Missing orders:admin scope"
+// @Failure 429 {object} Error "Rate limit exceeded, see Retry-After header"
+// @Failure 500 {object} Error "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /admin/gdpr/export [post]
+func (h *GDPRExportHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	subject, ok := readSubject(w, r)
+	if !ok {
+		return
+	}
+
+	ctx := subjectContext(r.Context())
+	found, err := h.Service.Export(ctx, subject, PrincipalFrom(ctx).Name)
+	if err != nil {
+		h.Logger.ErrorContext(ctx, "exporting subject data", "err", err)
+		responseInternalError.Write(w)
+		return
+	}
+
+	export := GDPRExport{Subject: subject, ExportedAt: time.Now().UTC(), Orders: make([]orderschema.Order, 0, len(found))}
+	for i := range found {
+		export.Orders = append(export.Orders, found[i].Order)
+	}
+
+	w.Header().Set("Content-Disposition", `attachment; filename="orders-export.json"`)
+	if err := respondJSON(export, w); err != nil {
+		h.Logger.ErrorContext(ctx, "sending http response", "err", err)
+		responseInternalError.Write(w)
+	}
+}
+
+type GDPREraseHandler struct {
+	Logger  *slog.Logger
+	Service *gdpr.Service
+}
+
+// EraseSubjectData godoc
+// @Summary Erase personal data of a data subject
+// @Description Anonymizes delivery name, phone, zip, address and email of all the orders of the customer found by either customer_id or delivery email.
+// @Description Orders with their items and payments are kept. Erased orders are evicted from the cache and the erasure is recorded in the audit.
+// @Tags gdpr
+// @Accept json
+// @Produce json
+// @Param subject body gdpr.Subject true "Exactly one of customer_id and email"
+// @Success 200 {object} GDPRErasure
+// @Failure 400 {object} Error "Invalid subject"
+// @Failure 401 {object} Error "Not authenticated"
+// @Failure 403 {object} Error "Missing orders:admin scope"
+// @Failure 429 {object} Error "Rate limit exceeded, see Retry-After header"
+// @Failure 500 {object} Error "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /admin/gdpr/erase [post]
+func (h *GDPREraseHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	subject, ok := readSubject(w, r)
+	if !ok {
+		return
+	}
+
+	ctx := subjectContext(r.Context())
+	ids, err := h.Service.Erase(ctx, subject, PrincipalFrom(ctx).Name)
+	if err != nil {
+		h.Logger.ErrorContext(ctx, "erasing subject data", "err", err)
+		responseInternalError.Write(w)
+		return
+	}
+
+	if err := respondJSON(GDPRErasure{Anonymized: ids}, w); err != nil {
+		h.Logger.ErrorContext(ctx, "sending http response", "err", err)
+		responseInternalError.Write(w)
+	}
+}
+
+// readSubject decodes the subject from the body of a POST request, writing an error response if it is invalid.
+func readSubject(w http.ResponseWriter, r *http.Request) (gdpr.Subject, bool) {
+	var subject gdpr.Subject
+
+	if r.Method != http.MethodPost {
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return subject, false
+	}
+
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxSubjectSize)).Decode(&subject); err != nil {
+		newErrorResponse(400, "invalid request body").Write(w)
+		return subject, false
+	}
+
+	if err := subject.Validate(); err != nil {
+		newErrorResponse(400, err.Error()).Write(w)
+		return subject, false
+	}
+
+	return subject, true
+}
+
+// subjectContext limits the search of subject orders to the tenant of the caller, if it has one.
+// Otherwise orders of all the tenants are searched.
+func subjectContext(ctx context.Context) context.Context {
+	if p := PrincipalFrom(ctx); p.Tenant != "" {
+		return orders.WithTenant(ctx, p.Tenant)
+	}
+
+	return ctx
+}
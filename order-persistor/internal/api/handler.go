@@ -1,28 +1,58 @@
 package api
 
 import (
+	"context"
 	"errors"
 	"log/slog"
 	"net/http"
 	"order-persistor/internal/orders"
+	"order-persistor/internal/redact"
 )
 
 type GetOrderHandler struct {
 	Logger     *slog.Logger
 	Repository orders.Repository
+	// Redaction masks personal data in responses to callers without the PII scope.
+	Redaction *redact.Policy
+	// UncachedLimiter limits lookups of the orders Cached reports missing, if set.
+	UncachedLimiter *RateLimiter
+	// Cached reports whether the order is cached. If nil, all the lookups are treated as uncached.
+	Cached func(id string) bool
+	// CacheControl is sent with orders if set.
+	CacheControl string
+	// Vary lists the request headers, besides Accept-Encoding, the response depends on.
+	Vary []string
 }
 
 // GetOrder godoc
 // @Summary Get order by ID
-// @Description Returns the order object for the specified ID
+// @Description Returns the order object for the specified ID.
+// @Description Personal data of the customer is masked unless the caller has the orders:read:pii scope.
+// @Description The representation is negotiated by the Accept header: JSON (application/json, indented with
+// @Description application/json;pretty=true), item lines in CSV (text/csv) or the orders.v1.Order protobuf message
+// @Description (application/x-protobuf).
 // @Tags orders
 // @Accept json
 // @Produce json
+// @Produce text/csv
+// @Produce application/x-protobuf
 // @Param id path string true "Order ID"
-// @Success 200 {object} orders.Order
-// @Failure 400 {object} Error "Invalid request"
+// @Param fields query string false "Comma-separated fields to return, like id,payment.amount,items. Nested fields are dotted, id is order_uid"
+// @Param X-Tenant header string false "Tenant whose orders are read, default if omitted"
+// @Param If-None-Match header string false "ETag of the cached order"
+// @Param If-Modified-Since header string false "Last-Modified of the cached order"
+// @Success 200 {object} orderschema.Order
+// @Success 304 "Cached order is current"
+// @Failure 400 {object} Error "Invalid request or unknown field"
+// @Failure 401 {object} Error "Not authenticated"
+// @Failure 403 {object} Error "Missing orders:read scope or tenant is not allowed"
 // @Failure 404 {object} Error "Order not found"
+// @Failure 406 {object} Error "No acceptable representation"
+// @Failure 429 {object} Error "Rate limit exceeded, see Retry-After header"
 // @Failure 500 {object} Error "Internal server error"
+// @Failure 504 {object} Error "Lookup did not finish within api.timeout"
+// @Security ApiKeyAuth
+// @Security BearerAuth
 // @Router /order/{id} [get]
 func (h *GetOrderHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	log := h.Logger.With("url", r.URL)
@@ -39,6 +69,26 @@ func (h *GetOrderHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	rep, ok := negotiate(r.Header.Get("Accept"))
+	if !ok {
+		responseNotAcceptable.Write(w)
+		return
+	}
+
+	fields, err := parseFields(r.URL.Query().Get("fields"))
+	if err != nil {
+		newErrorResponse(400, err.Error()).Write(w)
+		return
+	}
+
+	if h.UncachedLimiter != nil && (h.Cached == nil || !h.Cached(orderID)) {
+		if ok, retryAfter := h.UncachedLimiter.Allow(clientFrom(r.Context())); !ok {
+			addLogFields(r.Context(), "rate_limited", clientFrom(r.Context()), "uncached", true)
+			writeTooManyRequests(w, retryAfter)
+			return
+		}
+	}
+
 	order, err := h.Repository.GetByID(r.Context(), orderID)
 	if err != nil {
 		if errors.Is(err, orders.ErrNotFound) {
@@ -46,13 +96,50 @@ func (h *GetOrderHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
+		if errors.Is(err, context.DeadlineExceeded) {
+			addLogFields(r.Context(), "err", err.Error())
+			responseTimeout.Write(w)
+			return
+		}
+
 		log.ErrorContext(r.Context(), "retrieving order from repository", "err", err)
 		responseInternalError.Write(w)
 		return
 	}
 
-	if err := respondJSON(order, w); err != nil {
-		log.ErrorContext(r.Context(), "sending http response", "err", err)
+	if p := PrincipalFrom(r.Context()); p == nil || !p.HasScope(ScopeReadPII) {
+		order = h.Redaction.Order(order)
+	}
+
+	body, err := rep.render(order, fields)
+	if err != nil {
+		log.ErrorContext(r.Context(), "rendering order", "err", err, "content_type", rep.contentType)
 		responseInternalError.Write(w)
+		return
+	}
+
+	// orders do not change after creation, save for the erasure of personal data
+	etag := etagOf(body)
+	modified := order.ModifiedAt()
+	header := w.Header()
+	header.Set("ETag", etag)
+	if !modified.IsZero() {
+		header.Set("Last-Modified", modified.UTC().Format(http.TimeFormat))
+	}
+
+	if h.CacheControl != "" {
+		header.Set("Cache-Control", h.CacheControl)
+	}
+
+	for _, v := range h.Vary {
+		header.Add("Vary", v)
 	}
+
+	if notModified(r, etag, modified) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	header.Set("Content-Type", rep.contentType)
+	w.Write(body)
 }
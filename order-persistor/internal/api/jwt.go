@@ -0,0 +1,120 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"order-persistor/internal/config"
+	"os"
+	"strings"
+
+	"github.com/MicahParks/keyfunc/v3"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const defaultScopeClaim = "scope"
+
+// JWTAuthenticator accepts bearer tokens signed by the keys of a JWKS.
+type JWTAuthenticator struct {
+	keyfunc     jwt.Keyfunc
+	parser      *jwt.Parser
+	scopeClaim  string
+	tenantClaim string
+}
+
+// NewJWTAuthenticator loads the JWKS from the file, or from the URL refreshing it in background until ctx is done.
+func NewJWTAuthenticator(ctx context.Context, cfg config.JWT) (*JWTAuthenticator, error) {
+	var (
+		keys keyfunc.Keyfunc
+		err  error
+	)
+
+	if cfg.JWKSFile != "" {
+		raw, readErr := os.ReadFile(cfg.JWKSFile)
+		if readErr != nil {
+			return nil, readErr
+		}
+
+		keys, err = keyfunc.NewJWKSetJSON(raw)
+	} else {
+		keys, err = keyfunc.NewDefaultCtx(ctx, []string{cfg.JWKSURL})
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("could not load jwks: %w", err)
+	}
+
+	opts := []jwt.ParserOption{
+		jwt.WithValidMethods([]string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512", "EdDSA"}),
+		jwt.WithExpirationRequired(),
+	}
+
+	if cfg.Issuer != "" {
+		opts = append(opts, jwt.WithIssuer(cfg.Issuer))
+	}
+
+	if cfg.Audience != "" {
+		opts = append(opts, jwt.WithAudience(cfg.Audience))
+	}
+
+	scopeClaim := cfg.ScopeClaim
+	if scopeClaim == "" {
+		scopeClaim = defaultScopeClaim
+	}
+
+	return &JWTAuthenticator{
+		keyfunc:     keys.Keyfunc,
+		parser:      jwt.NewParser(opts...),
+		scopeClaim:  scopeClaim,
+		tenantClaim: cfg.TenantClaim,
+	}, nil
+}
+
+func (a *JWTAuthenticator) Authenticate(r *http.Request) (*Principal, error) {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok || token == "" {
+		return nil, errNoCredentials
+	}
+
+	claims := jwt.MapClaims{}
+	if _, err := a.parser.ParseWithClaims(token, claims, a.keyfunc); err != nil {
+		return nil, fmt.Errorf("%w: %w", errInvalidCredentials, err)
+	}
+
+	subject, _ := claims.GetSubject()
+	p := &Principal{
+		Name:   subject,
+		Method: "jwt",
+		Scopes: scopesOf(claims[a.scopeClaim]),
+	}
+
+	if a.tenantClaim != "" {
+		tenant, ok := claims[a.tenantClaim].(string)
+		if !ok || tenant == "" {
+			return nil, fmt.Errorf("%w: token has no %s claim", errInvalidCredentials, a.tenantClaim)
+		}
+
+		p.Tenant = tenant
+	}
+
+	return p, nil
+}
+
+// scopesOf reads scopes from either a space-separated string or an array of strings.
+func scopesOf(claim any) []string {
+	switch v := claim.(type) {
+	case string:
+		return strings.Fields(v)
+	case []any:
+		scopes := make([]string, 0, len(v))
+		for _, s := range v {
+			if s, ok := s.(string); ok {
+				scopes = append(scopes, s)
+			}
+		}
+
+		return scopes
+	default:
+		return nil
+	}
+}
@@ -1,8 +1,11 @@
 package api
 
 import (
+	"context"
 	"log/slog"
 	"net/http"
+	"order-persistor/internal/orders"
+	"sync"
 	"time"
 )
 
@@ -26,7 +29,8 @@ func NewLogMiddleware(logger *slog.Logger) Middleware {
 				wrapped: w,
 			}
 
-			next.ServeHTTP(wrappedWriter, r)
+			extra := &logFields{}
+			next.ServeHTTP(wrappedWriter, r.WithContext(context.WithValue(r.Context(), logFieldsKey{}, extra)))
 
 			duration := time.Since(start)
 
@@ -36,6 +40,7 @@ func NewLogMiddleware(logger *slog.Logger) Middleware {
 				"status", wrappedWriter.code,
 				"duration", duration.String(),
 			}
+			fields = append(fields, extra.get()...)
 
 			if wrappedWriter.code >= 400 {
 				logger.Error("HTTP", fields...)
@@ -46,6 +51,72 @@ func NewLogMiddleware(logger *slog.Logger) Middleware {
 	}
 }
 
+// NewTimeoutMiddleware sets the deadline of handling requests, which applies to the repository reads
+// made with the request context. Handlers respond with responseTimeout when it is exceeded.
+func NewTimeoutMiddleware(timeout time.Duration) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx, cancel := context.WithTimeout(r.Context(), timeout)
+			defer cancel()
+
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+type logFieldsKey struct{}
+
+// logFields are collected by the inner middlewares and handlers to be written in the request log line.
+type logFields struct {
+	mu     sync.Mutex
+	fields []any
+}
+
+func (f *logFields) get() []any {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.fields
+}
+
+// addLogFields adds key-value pairs to the log line of the request, if it is logged.
+func addLogFields(ctx context.Context, kv ...any) {
+	f, ok := ctx.Value(logFieldsKey{}).(*logFields)
+	if !ok {
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.fields = append(f.fields, kv...)
+}
+
+// NewTenantMiddleware limits the orders the request reads to the tenant given in the header.
+// The tenant of the authenticated caller takes precedence, and requesting another one is forbidden.
+func NewTenantMiddleware(header string) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			tenant := r.Header.Get(header)
+			if p := PrincipalFrom(r.Context()); p != nil && p.Tenant != "" {
+				if tenant != "" && tenant != p.Tenant {
+					addLogFields(r.Context(), "auth_error", "tenant "+tenant+" is not allowed")
+					responseForbidden.Write(w)
+					return
+				}
+
+				tenant = p.Tenant
+			}
+
+			if tenant == "" {
+				tenant = orders.DefaultTenant
+			}
+
+			next.ServeHTTP(w, r.WithContext(orders.WithTenant(r.Context(), tenant)))
+		})
+	}
+}
+
 var _ http.ResponseWriter = &responseWriterWrapper{}
 
 type responseWriterWrapper struct {
@@ -65,3 +136,8 @@ func (l *responseWriterWrapper) WriteHeader(statusCode int) {
 	l.code = statusCode
 	l.wrapped.WriteHeader(statusCode)
 }
+
+// Unwrap lets http.ResponseController reach the flushing and deadline methods of the wrapped writer.
+func (l *responseWriterWrapper) Unwrap() http.ResponseWriter {
+	return l.wrapped
+}
@@ -0,0 +1,49 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"order-persistor/internal/mocks"
+	"order-persistor/internal/orders"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/mock/gomock"
+)
+
+func TestTimeoutMiddleware(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	rep := mocks.NewMockRepository(ctrl)
+	rep.EXPECT().
+		GetByID(gomock.Any(), "slow").
+		DoAndReturn(func(ctx context.Context, _ string) (*orders.Order, error) {
+			<-ctx.Done()
+			return nil, errors.Join(orders.ErrInternalFailure, ctx.Err())
+		})
+
+	h := stackMiddleware(
+		&GetOrderHandler{Logger: slog.New(slog.DiscardHandler), Repository: rep},
+		NewTimeoutMiddleware(10*time.Millisecond),
+	)
+
+	r := httptest.NewRequest(http.MethodGet, "/order/slow", nil)
+	r.SetPathValue("id", "slow")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+
+	if w.Code != http.StatusGatewayTimeout {
+		t.Fatalf("expected 504, got %d", w.Code)
+	}
+
+	if !strings.Contains(w.Body.String(), `"message":"Request timed out"`) {
+		t.Fatalf("unexpected body: %s", w.Body.String())
+	}
+}
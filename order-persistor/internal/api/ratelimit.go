@@ -0,0 +1,134 @@
+package api
+
+import (
+	"cmp"
+	"context"
+	"math"
+	"net"
+	"net/http"
+	"order-persistor/internal/config"
+	"strconv"
+	"strings"
+	"time"
+
+	lru "github.com/hashicorp/golang-lru/v2"
+	"golang.org/x/time/rate"
+)
+
+const defaultMaxClients = 10_000
+
+// RateLimiter keeps a token bucket per client.
+type RateLimiter struct {
+	rate    rate.Limit
+	burst   int
+	buckets *lru.Cache[string, *rate.Limiter]
+}
+
+// NewRateLimiter creates a limiter of rate requests per second with burst, tracking up to maxClients clients.
+// A forgotten client starts over with a full bucket.
+func NewRateLimiter(r float64, burst int, maxClients int) *RateLimiter {
+	if maxClients <= 0 {
+		maxClients = defaultMaxClients
+	}
+
+	// size is positive, so creation does not fail
+	buckets, _ := lru.New[string, *rate.Limiter](maxClients)
+
+	return &RateLimiter{
+		rate:    rate.Limit(r),
+		burst:   burst,
+		buckets: buckets,
+	}
+}
+
+// Allow takes a token of the client, otherwise it returns how long the client should wait for one.
+func (l *RateLimiter) Allow(client string) (bool, time.Duration) {
+	bucket, ok := l.buckets.Get(client)
+	if !ok {
+		bucket = rate.NewLimiter(l.rate, l.burst)
+		// concurrent first requests of a client may race here, and one of them gets an extra token
+		l.buckets.Add(client, bucket)
+	}
+
+	r := bucket.Reserve()
+	if delay := r.Delay(); delay > 0 {
+		r.Cancel()
+		return false, delay
+	}
+
+	return true, 0
+}
+
+type clientKey struct{}
+
+// clientFrom returns the key the request is limited by.
+func clientFrom(ctx context.Context) string {
+	client, _ := ctx.Value(clientKey{}).(string)
+	return client
+}
+
+// NewRateLimitMiddleware limits requests of every client, responding 429 to the clients out of tokens.
+// Clients are identified by the caller authenticated by the auth middleware, and by IP address otherwise,
+// so the middleware stacked before the auth one limits IP addresses, including the ones failing authentication.
+// Limiter is not applied if it is nil, while the client is still identified for the handler.
+func NewRateLimitMiddleware(l *RateLimiter, trustForwardedFor bool) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			client := clientIP(r, trustForwardedFor)
+			if p := PrincipalFrom(r.Context()); p != nil && p != Anonymous {
+				client = p.Method + ":" + p.Name
+			}
+
+			if l != nil {
+				if ok, retryAfter := l.Allow(client); !ok {
+					addLogFields(r.Context(), "rate_limited", client)
+					writeTooManyRequests(w, retryAfter)
+					return
+				}
+			}
+
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), clientKey{}, client)))
+		})
+	}
+}
+
+// newRateLimiters creates the limiters of IP addresses before authentication, of all and of uncached lookups,
+// nil ones are not applied.
+func newRateLimiters(cfg config.RateLimit) (ip, all, uncached *RateLimiter) {
+	if !cfg.Enabled {
+		return nil, nil, nil
+	}
+
+	ip = NewRateLimiter(cmp.Or(cfg.IPRate, cfg.Rate), cmp.Or(cfg.IPBurst, cfg.Burst), cfg.MaxClients)
+	all = NewRateLimiter(cfg.Rate, cfg.Burst, cfg.MaxClients)
+	if cfg.UncachedRate > 0 {
+		uncached = NewRateLimiter(cfg.UncachedRate, cfg.UncachedBurst, cfg.MaxClients)
+	}
+
+	return ip, all, uncached
+}
+
+func writeTooManyRequests(w http.ResponseWriter, retryAfter time.Duration) {
+	w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+	newErrorResponse(http.StatusTooManyRequests, "Too many requests").Write(w)
+}
+
+func clientIP(r *http.Request, trustForwardedFor bool) string {
+	if trustForwardedFor {
+		forwarded := r.Header.Values("X-Forwarded-For")
+		if len(forwarded) > 0 {
+			// the proxy appends the address it received the request from, preceding ones are up to the client
+			addrs := strings.Split(forwarded[len(forwarded)-1], ",")
+			if addr := strings.TrimSpace(addrs[len(addrs)-1]); addr != "" {
+				return "ip:" + addr
+			}
+		}
+	}
+
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+
+	return "ip:" + host
+}
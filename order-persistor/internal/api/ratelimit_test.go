@@ -0,0 +1,147 @@
+package api
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"order-persistor/internal/config"
+	"order-persistor/internal/mocks"
+	"order-persistor/internal/orders"
+	"strconv"
+	"testing"
+	"time"
+
+	"go.uber.org/mock/gomock"
+
+	"github.com/lezzercringe/some-assignment/orderschema"
+)
+
+func TestRateLimitMiddleware(t *testing.T) {
+	t.Parallel()
+
+	h := NewRateLimitMiddleware(NewRateLimiter(1, 2, 0), true)(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+
+	request := func(remoteAddr, forwardedFor string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, "/order/1", nil)
+		r.RemoteAddr = remoteAddr
+		if forwardedFor != "" {
+			r.Header.Set("X-Forwarded-For", forwardedFor)
+		}
+
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w
+	}
+
+	for range 2 {
+		if w := request("10.0.0.1:1234", "192.0.2.1"); w.Code != 200 {
+			t.Fatalf("expected request within burst to pass, got %d", w.Code)
+		}
+	}
+
+	w := request("10.0.0.1:1234", "192.0.2.1")
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("expected 429, got %d", w.Code)
+	}
+
+	if retryAfter, err := strconv.Atoi(w.Header().Get("Retry-After")); err != nil || retryAfter < 1 {
+		t.Fatalf("unexpected Retry-After: %q", w.Header().Get("Retry-After"))
+	}
+
+	// only the address appended by the proxy identifies the client
+	if w := request("10.0.0.1:1234", "spoofed, 192.0.2.1"); w.Code != http.StatusTooManyRequests {
+		t.Fatalf("expected spoofed address to be ignored, got %d", w.Code)
+	}
+
+	if w := request("10.0.0.1:1234", "192.0.2.2"); w.Code != 200 {
+		t.Fatalf("expected another client to pass, got %d", w.Code)
+	}
+}
+
+func TestGetOrderHandler_uncachedLimit(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	rep := mocks.NewMockRepository(ctrl)
+	rep.EXPECT().GetByID(gomock.Any(), "cached").Return(&orders.Order{Order: orderschema.Order{ID: "cached"}}, nil).Times(3)
+	rep.EXPECT().GetByID(gomock.Any(), "uncached").Return(&orders.Order{Order: orderschema.Order{ID: "uncached"}}, nil).Times(1)
+
+	handler := &GetOrderHandler{
+		Logger:          slog.New(slog.DiscardHandler),
+		Repository:      rep,
+		UncachedLimiter: NewRateLimiter(1, 1, 0),
+		Cached:          func(id string) bool { return id != "uncached" },
+	}
+
+	get := func(id string) int {
+		r := httptest.NewRequest(http.MethodGet, "/order/"+id, nil)
+		r.SetPathValue("id", id)
+		ctx := context.WithValue(r.Context(), principalKey{}, Anonymous)
+		r = r.WithContext(context.WithValue(ctx, clientKey{}, "ip:192.0.2.1"))
+
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		return w.Code
+	}
+
+	if code := get("uncached"); code != 200 {
+		t.Fatalf("expected first uncached lookup to pass, got %d", code)
+	}
+
+	if code := get("uncached"); code != http.StatusTooManyRequests {
+		t.Fatalf("expected second uncached lookup to be limited, got %d", code)
+	}
+
+	for range 3 {
+		if code := get("cached"); code != 200 {
+			t.Fatalf("expected cached lookup to pass, got %d", code)
+		}
+	}
+}
+
+func TestNewServer_rateLimitsFailedAuthentication(t *testing.T) {
+	t.Parallel()
+
+	authCfg := config.Auth{
+		Enabled: true,
+		APIKeys: []config.APIKey{{Name: "support", Key: "0123456789abcdef", Scopes: []string{ScopeRead}}},
+	}
+
+	authenticator, err := NewAuthenticator(t.Context(), authCfg)
+	if err != nil {
+		t.Fatalf("could not create authenticator: %v", err)
+	}
+
+	srv := NewServer(config.API{
+		Timeout:   time.Second,
+		Auth:      authCfg,
+		RateLimit: config.RateLimit{Enabled: true, Rate: 1, Burst: 3, IPRate: 1, IPBurst: 2},
+	}, Params{
+		Logger:        slog.New(slog.DiscardHandler),
+		Authenticator: authenticator,
+	})
+
+	request := func(key string) int {
+		r := httptest.NewRequest(http.MethodGet, "/order/1", nil)
+		r.RemoteAddr = "192.0.2.1:1234"
+		r.Header.Set(apiKeyHeader, key)
+
+		w := httptest.NewRecorder()
+		srv.Handler.ServeHTTP(w, r)
+		return w.Code
+	}
+
+	for range 2 {
+		if code := request("guessed-key-0000"); code != http.StatusUnauthorized {
+			t.Fatalf("expected 401 for wrong key, got %d", code)
+		}
+	}
+
+	// the address ran out of tokens before authentication, whatever the key
+	if code := request("guessed-key-0001"); code != http.StatusTooManyRequests {
+		t.Fatalf("expected guessing of keys to be limited, got %d", code)
+	}
+}
@@ -0,0 +1,20 @@
+package api
+
+import (
+	"net/http"
+)
+
+var responseNotReady = newErrorResponse(503, "Service is not ready")
+
+type ReadinessHandler struct {
+	Ready func() bool
+}
+
+func (h *ReadinessHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if h.Ready != nil && !h.Ready() {
+		responseNotReady.Write(w)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
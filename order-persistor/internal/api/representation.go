@@ -0,0 +1,311 @@
+package api
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"order-persistor/internal/grpcapi/ordersv1"
+	"order-persistor/internal/orders"
+	"strconv"
+	"strings"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// representation renders orders in a media type negotiated by the Accept header.
+type representation struct {
+	contentType string
+	render      func(o *orders.Order, fields fieldSet) ([]byte, error)
+}
+
+var (
+	representationJSON = &representation{
+		contentType: "application/json",
+		render:      renderJSON,
+	}
+	representationPrettyJSON = &representation{
+		contentType: "application/json",
+		render: func(o *orders.Order, fields fieldSet) ([]byte, error) {
+			body, err := renderJSON(o, fields)
+			if err != nil {
+				return nil, err
+			}
+
+			var buf bytes.Buffer
+			err = json.Indent(&buf, body, "", "  ")
+			return buf.Bytes(), err
+		},
+	}
+	representationCSV = &representation{
+		contentType: "text/csv",
+		render:      renderItemsCSV,
+	}
+	representationProtobuf = &representation{
+		contentType: "application/x-protobuf",
+		render:      renderProtobuf,
+	}
+)
+
+// negotiate picks the representation preferred by the Accept header, JSON if the header is empty.
+// Pretty JSON is requested with application/json;pretty=true. False is returned if no representation is acceptable.
+func negotiate(accept string) (*representation, bool) {
+	if strings.TrimSpace(accept) == "" {
+		return representationJSON, true
+	}
+
+	type candidate struct {
+		rep *representation
+		q   float64
+	}
+
+	var candidates []candidate
+	for mediaRange := range strings.SplitSeq(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(mediaRange)
+		if err != nil {
+			continue
+		}
+
+		q := 1.0
+		if v, ok := params["q"]; ok {
+			if q, err = strconv.ParseFloat(v, 64); err != nil || q <= 0 {
+				continue
+			}
+		}
+
+		var rep *representation
+		switch mediaType {
+		case "application/json":
+			rep = representationJSON
+			if pretty, _ := strconv.ParseBool(params["pretty"]); pretty {
+				rep = representationPrettyJSON
+			}
+		case "*/*", "application/*":
+			rep = representationJSON
+		case "text/csv", "text/*":
+			rep = representationCSV
+		case "application/x-protobuf", "application/protobuf":
+			rep = representationProtobuf
+		default:
+			continue
+		}
+
+		candidates = append(candidates, candidate{rep: rep, q: q})
+	}
+
+	if len(candidates) == 0 {
+		return nil, false
+	}
+
+	// the first of the most preferred ranges wins
+	best := candidates[0]
+	for _, c := range candidates[1:] {
+		if c.q > best.q {
+			best = c
+		}
+	}
+
+	return best.rep, true
+}
+
+func renderJSON(o *orders.Order, fields fieldSet) ([]byte, error) {
+	body, err := json.Marshal(o)
+	if err != nil || fields == nil {
+		return body, err
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(body))
+	// keeps integers as they are
+	dec.UseNumber()
+
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(fields.pruneJSON(v))
+}
+
+func renderProtobuf(o *orders.Order, fields fieldSet) ([]byte, error) {
+	msg := ordersv1.FromOrder(o)
+	if fields != nil {
+		fields.pruneMessage(msg.ProtoReflect())
+	}
+
+	return proto.Marshal(msg)
+}
+
+// itemColumns are the columns of item lines in CSV, named after the JSON fields.
+var itemColumns = []struct {
+	name  string
+	value func(i *orders.Item) string
+}{
+	{"chrt_id", func(i *orders.Item) string { return strconv.Itoa(i.CHRTID) }},
+	{"track_number", func(i *orders.Item) string { return i.TrackNumber }},
+	{"rid", func(i *orders.Item) string { return i.RID }},
+	{"name", func(i *orders.Item) string { return i.Name }},
+	{"size", func(i *orders.Item) string { return i.Size }},
+	{"nm_id", func(i *orders.Item) string { return strconv.Itoa(i.NMID) }},
+	{"brand", func(i *orders.Item) string { return i.Brand }},
+	{"status", func(i *orders.Item) string { return strconv.Itoa(i.Status) }},
+	{"price", func(i *orders.Item) string { return i.Price.String() }},
+	{"sale", func(i *orders.Item) string { return i.Sale.String() }},
+	{"total_price", func(i *orders.Item) string { return i.TotalPrice.String() }},
+}
+
+// renderItemsCSV renders the item lines of the order with a header. Selected items.* fields narrow the columns.
+func renderItemsCSV(o *orders.Order, fields fieldSet) ([]byte, error) {
+	var selected fieldSet
+	if fields != nil {
+		selected = fields["items"]
+	}
+
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+
+	header := make([]string, 0, len(itemColumns))
+	for _, c := range itemColumns {
+		if selected.has(c.name) {
+			header = append(header, c.name)
+		}
+	}
+
+	if err := w.Write(header); err != nil {
+		return nil, err
+	}
+
+	for i := range o.Items {
+		record := make([]string, 0, len(header))
+		for _, c := range itemColumns {
+			if selected.has(c.name) {
+				record = append(record, c.value(&o.Items[i]))
+			}
+		}
+
+		if err := w.Write(record); err != nil {
+			return nil, err
+		}
+	}
+
+	w.Flush()
+	return buf.Bytes(), w.Error()
+}
+
+var errUnknownField = errors.New("unknown field")
+
+// fieldSet is the tree of selected fields. A nil subtree selects the whole field.
+type fieldSet map[string]fieldSet
+
+// fieldAliases are the short names of the order fields.
+var fieldAliases = map[string]string{
+	"id": "order_uid",
+}
+
+// parseFields parses comma-separated dotted paths of the order fields, like id,payment.amount,items.
+// Paths are checked against the order message, whose fields are named after the JSON ones.
+// Nil is returned for an empty list, which selects all the fields.
+func parseFields(list string) (fieldSet, error) {
+	if strings.TrimSpace(list) == "" {
+		return nil, nil
+	}
+
+	root := fieldSet{}
+	for path := range strings.SplitSeq(list, ",") {
+		path = strings.TrimSpace(path)
+		if path == "" {
+			continue
+		}
+
+		names := strings.Split(path, ".")
+		if alias, ok := fieldAliases[names[0]]; ok {
+			names[0] = alias
+		}
+
+		desc := (&ordersv1.Order{}).ProtoReflect().Descriptor()
+		node := root
+		for i, name := range names {
+			fd := desc.Fields().ByName(protoreflect.Name(name))
+			if fd == nil || (fd.Message() == nil && i < len(names)-1) {
+				return nil, fmt.Errorf("%w %s", errUnknownField, path)
+			}
+
+			sub, seen := node[name]
+			if seen && sub == nil {
+				// the whole field is already selected
+				break
+			}
+
+			if i == len(names)-1 {
+				node[name] = nil
+				break
+			}
+
+			if sub == nil {
+				sub = fieldSet{}
+				node[name] = sub
+			}
+
+			node, desc = sub, fd.Message()
+		}
+	}
+
+	return root, nil
+}
+
+// has reports whether the field is selected.
+func (s fieldSet) has(name string) bool {
+	if s == nil {
+		return true
+	}
+
+	_, ok := s[name]
+	return ok
+}
+
+// pruneJSON drops the fields not selected from the decoded JSON value, applying to every element of arrays.
+func (s fieldSet) pruneJSON(v any) any {
+	if s == nil {
+		return v
+	}
+
+	switch v := v.(type) {
+	case map[string]any:
+		res := make(map[string]any, len(s))
+		for name, sub := range s {
+			if field, ok := v[name]; ok {
+				res[name] = sub.pruneJSON(field)
+			}
+		}
+
+		return res
+	case []any:
+		for i := range v {
+			v[i] = s.pruneJSON(v[i])
+		}
+	}
+
+	return v
+}
+
+// pruneMessage clears the fields not selected in the message, applying to every element of repeated fields.
+func (s fieldSet) pruneMessage(m protoreflect.Message) {
+	m.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		sub, ok := s[string(fd.Name())]
+		switch {
+		case !ok:
+			m.Clear(fd)
+		case sub == nil || fd.Message() == nil:
+		case fd.IsList():
+			for i := range v.List().Len() {
+				sub.pruneMessage(v.List().Get(i).Message())
+			}
+		default:
+			sub.pruneMessage(v.Message())
+		}
+
+		return true
+	})
+}
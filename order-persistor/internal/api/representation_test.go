@@ -0,0 +1,147 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"order-persistor/internal/grpcapi/ordersv1"
+	"order-persistor/internal/mocks"
+	"order-persistor/internal/orders"
+	"order-persistor/internal/redact"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/lezzercringe/some-assignment/orderschema"
+	"github.com/shopspring/decimal"
+	"go.uber.org/mock/gomock"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestNegotiate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		accept string
+		want   *representation
+	}{
+		{accept: "", want: representationJSON},
+		{accept: "*/*", want: representationJSON},
+		{accept: "application/json; pretty=true", want: representationPrettyJSON},
+		{accept: "text/html, */*;q=0.8", want: representationJSON},
+		{accept: "application/json;q=0.5, text/csv", want: representationCSV},
+		{accept: "application/x-protobuf, application/json", want: representationProtobuf},
+		{accept: "application/protobuf;q=0", want: nil},
+		{accept: "text/html", want: nil},
+	}
+
+	for _, tt := range tests {
+		got, ok := negotiate(tt.accept)
+		if got != tt.want || ok != (tt.want != nil) {
+			t.Errorf("negotiate(%q) = %v, %t, want %v", tt.accept, got, ok, tt.want)
+		}
+	}
+}
+
+func TestParseFields(t *testing.T) {
+	t.Parallel()
+
+	got, err := parseFields("id, payment.amount,items,payment.currency,items.name")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := fieldSet{
+		"order_uid": nil,
+		"payment":   {"amount": nil, "currency": nil},
+		"items":     nil,
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("unexpected fields %v", got)
+	}
+
+	for _, list := range []string{"nope", "payment.nope", "order_uid.nope"} {
+		if _, err := parseFields(list); !errors.Is(err, errUnknownField) {
+			t.Errorf("expected unknown field error for %q, got %v", list, err)
+		}
+	}
+}
+
+func TestGetOrderHandler_representations(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	rep := mocks.NewMockRepository(ctrl)
+
+	order := &orders.Order{Order: orderschema.Order{ID: "b563feb7b2b84b6test", TrackNumber: "WBILMTESTTRACK"}}
+	order.Payment = &orders.Payment{Amount: decimal.RequireFromString("1817.50"), Currency: "USD"}
+	order.Items = []orders.Item{
+		{CHRTID: 9934930, Name: "Mascaras", Price: decimal.NewFromInt(453)},
+		{CHRTID: 9934931, Name: "Lipstick, red", Price: decimal.NewFromInt(120)},
+	}
+	rep.EXPECT().GetByID(gomock.Any(), order.ID).Return(order, nil).AnyTimes()
+
+	redaction, _ := redact.NewPolicy(redact.Remove, "")
+	h := &GetOrderHandler{Logger: slog.New(slog.DiscardHandler), Repository: rep, Redaction: redaction}
+
+	request := func(accept, fields string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, "/order/"+order.ID+"?fields="+fields, nil)
+		r.SetPathValue("id", order.ID)
+		r.Header.Set("Accept", accept)
+
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w
+	}
+
+	w := request("application/json", "id,payment.amount,items.name")
+	var sparse map[string]any
+	if err := json.Unmarshal(w.Body.Bytes(), &sparse); err != nil {
+		t.Fatal(err)
+	}
+
+	wantSparse := map[string]any{
+		"order_uid": order.ID,
+		"payment":   map[string]any{"amount": "1817.5"},
+		"items":     []any{map[string]any{"name": "Mascaras"}, map[string]any{"name": "Lipstick, red"}},
+	}
+
+	if !reflect.DeepEqual(sparse, wantSparse) {
+		t.Errorf("unexpected sparse order %s", w.Body)
+	}
+
+	if w := request("application/json", "nope"); w.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for unknown field, got %d", w.Code)
+	}
+
+	if w := request("application/json;pretty=true", "id"); w.Body.String() != "{\n  \"order_uid\": \""+order.ID+"\"\n}" {
+		t.Errorf("unexpected pretty JSON %q", w.Body)
+	}
+
+	w = request("text/csv", "items.name,items.chrt_id")
+	if ct := w.Header().Get("Content-Type"); ct != "text/csv" {
+		t.Errorf("unexpected content type %q", ct)
+	}
+
+	if want := "chrt_id,name\n9934930,Mascaras\n9934931,\"Lipstick, red\"\n"; w.Body.String() != want {
+		t.Errorf("unexpected CSV %q", w.Body)
+	}
+
+	w = request("application/x-protobuf", "id,payment.currency")
+	var msg ordersv1.Order
+	if err := proto.Unmarshal(w.Body.Bytes(), &msg); err != nil {
+		t.Fatal(err)
+	}
+
+	wantMsg := &ordersv1.Order{OrderUid: order.ID, Payment: &ordersv1.Payment{Currency: "USD"}}
+	if !proto.Equal(&msg, wantMsg) {
+		t.Errorf("unexpected protobuf order %v", &msg)
+	}
+
+	if w := request("text/html", ""); w.Code != http.StatusNotAcceptable || !strings.Contains(w.Body.String(), "text/csv") {
+		t.Errorf("expected 406, got %d %s", w.Code, w.Body)
+	}
+}
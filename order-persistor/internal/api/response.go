@@ -5,7 +5,11 @@ import (
 	"net/http"
 )
 
-var responseInternalError = newErrorResponse(500, "Internal server error")
+var (
+	responseInternalError = newErrorResponse(500, "Internal server error")
+	responseTimeout       = newErrorResponse(504, "Request timed out")
+	responseNotAcceptable = newErrorResponse(406, "Supported representations are application/json, text/csv and application/x-protobuf")
+)
 
 type HTTPError struct {
 	Code int
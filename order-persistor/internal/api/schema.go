@@ -0,0 +1,28 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/lezzercringe/some-assignment/orderschema"
+)
+
+// SchemaHandler serves the JSON Schema of order messages, so producers can check their payloads against it.
+type SchemaHandler struct{}
+
+// GetOrderSchema godoc
+// @Summary Get order JSON Schema
+// @Description Returns the JSON Schema (draft-07) of order messages consumed from kafka
+// @Tags schema
+// @Produce json
+// @Success 200 {object} object "JSON Schema"
+// @Router /schema/order.json [get]
+func (h *SchemaHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/schema+json")
+	w.Header().Set("X-Schema-Version", orderschema.Version)
+	w.Write(orderschema.JSONSchema())
+}
@@ -1,44 +1,160 @@
 package api
 
 import (
+	"cmp"
+	"crypto/tls"
 	"log/slog"
 	"net"
 	"net/http"
+	"order-persistor/internal/broadcast"
 	"order-persistor/internal/config"
+	"order-persistor/internal/gdpr"
 	"order-persistor/internal/orders"
+	"order-persistor/internal/redact"
+	"time"
 
 	_ "order-persistor/docs"
 
 	gorilla "github.com/gorilla/handlers"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	swagger "github.com/swaggo/http-swagger"
 )
 
+const (
+	defaultTenantHeader      = "X-Tenant"
+	defaultReadHeaderTimeout = 5 * time.Second
+	defaultReadTimeout       = 10 * time.Second
+	defaultWriteTimeout      = 30 * time.Second
+	defaultIdleTimeout       = 2 * time.Minute
+	defaultMaxHeaderBytes    = 64 << 10
+	defaultCacheControl      = "private, no-cache"
+)
+
 type Params struct {
 	Logger           *slog.Logger
 	OrdersRepository orders.Repository
+	// GDPR serves the data subject requests. If nil, the admin endpoints are not registered.
+	GDPR *gdpr.Service
+	// Hub delivers the orders pushed to the stream clients. If nil, the stream endpoint is not registered.
+	Hub *broadcast.Hub
+	// Redaction masks personal data in responses to callers without the PII scope.
+	Redaction *redact.Policy
+	// Authenticator identifies callers of the order endpoints. If nil, requests are not authenticated.
+	Authenticator Authenticator
+	// Cached reports whether the order is cached, so its lookup is not limited as uncached one.
+	Cached func(id string) bool
+	// TLSConfig makes the server serve HTTPS with HTTP/2, see NewTLSConfig. If nil, plain HTTP is served.
+	TLSConfig *tls.Config
+	// Ready reports whether the service is ready to receive traffic. If nil, the service is always ready.
+	Ready func() bool
 }
 
 // @title           Order-persistor API
 // @version         1.0
 
+// @securityDefinitions.apikey ApiKeyAuth
+// @in header
+// @name X-API-Key
+
+// @securityDefinitions.apikey BearerAuth
+// @in header
+// @name Authorization
+// @description JWT as "Bearer <token>"
+
 func NewServer(cfg config.API, p Params) *http.Server {
 	mux := http.NewServeMux()
+	ipLimiter, limiter, uncachedLimiter := newRateLimiters(cfg.RateLimit)
+	tenantHeader := cmp.Or(cfg.TenantHeader, defaultTenantHeader)
 	handler := GetOrderHandler{
-		Logger:     p.Logger,
-		Repository: p.OrdersRepository,
+		Logger:          p.Logger,
+		Repository:      p.OrdersRepository,
+		Redaction:       p.Redaction,
+		UncachedLimiter: uncachedLimiter,
+		Cached:          p.Cached,
+		CacheControl:    cmp.Or(cfg.CacheControl, defaultCacheControl),
+		Vary:            []string{"Accept", tenantHeader, "Authorization", apiKeyHeader},
+	}
+
+	compress := func(h http.Handler) http.Handler { return h }
+	if cfg.Compression {
+		compress = gorilla.CompressHandler
+	}
+
+	corsOptions := []gorilla.CORSOption{
+		gorilla.AllowedHeaders([]string{tenantHeader, "Authorization", apiKeyHeader, "Last-Event-ID"}),
+	}
+
+	if len(cfg.CORSOrigins) > 0 {
+		corsOptions = append(corsOptions, gorilla.AllowedOrigins(cfg.CORSOrigins))
 	}
 
 	httpAddr := net.JoinHostPort(cfg.Host, cfg.Port)
 	mux.Handle("/order/{id}", stackMiddleware(
 		&handler,
 		gorilla.RecoveryHandler(),
-		gorilla.CORS(),
+		gorilla.CORS(corsOptions...),
 		NewLogMiddleware(p.Logger),
+		NewTimeoutMiddleware(cfg.Timeout),
+		NewRateLimitMiddleware(ipLimiter, cfg.RateLimit.TrustForwardedFor),
+		NewAuthMiddleware(p.Authenticator, ScopeRead),
+		NewRateLimitMiddleware(limiter, cfg.RateLimit.TrustForwardedFor),
+		NewTenantMiddleware(tenantHeader),
+		compress,
+	))
+	if p.Hub != nil {
+		// streams are not limited by the handling timeout, only by the write timeout of every event
+		mux.Handle("/orders/stream", stackMiddleware(
+			&StreamHandler{Logger: p.Logger, Hub: p.Hub, Redaction: p.Redaction},
+			gorilla.RecoveryHandler(),
+			gorilla.CORS(corsOptions...),
+			NewLogMiddleware(p.Logger),
+			NewRateLimitMiddleware(ipLimiter, cfg.RateLimit.TrustForwardedFor),
+			NewAuthMiddleware(p.Authenticator, ScopeRead),
+			NewRateLimitMiddleware(limiter, cfg.RateLimit.TrustForwardedFor),
+			NewTenantMiddleware(tenantHeader),
+		))
+	}
+
+	if p.GDPR != nil {
+		adminMiddleware := []Middleware{
+			gorilla.RecoveryHandler(),
+			NewLogMiddleware(p.Logger),
+			NewRateLimitMiddleware(ipLimiter, cfg.RateLimit.TrustForwardedFor),
+			NewAuthMiddleware(p.Authenticator, ScopeAdmin),
+		}
+
+		mux.Handle("/admin/gdpr/export", stackMiddleware(&GDPRExportHandler{Logger: p.Logger, Service: p.GDPR}, adminMiddleware...))
+		mux.Handle("/admin/gdpr/erase", stackMiddleware(&GDPREraseHandler{Logger: p.Logger, Service: p.GDPR}, adminMiddleware...))
+	}
+
+	mux.Handle("/schema/order.json", stackMiddleware(
+		&SchemaHandler{},
+		gorilla.RecoveryHandler(),
+		gorilla.CORS(),
 	))
 	mux.Handle("/swagger/", swagger.WrapHandler)
 
+	probes := http.NewServeMux()
+	probes.Handle("/readyz", &ReadinessHandler{Ready: p.Ready})
+	probes.Handle("/metrics", promhttp.Handler())
+	tlsConfig, root := exemptFromClientAuth(p.TLSConfig, mux, probes)
+
+	protocols := &http.Protocols{}
+	protocols.SetHTTP1(true)
+	protocols.SetHTTP2(true)
+	protocols.SetUnencryptedHTTP2(cfg.UnencryptedHTTP2)
+
 	return &http.Server{
-		Addr:    httpAddr,
-		Handler: mux,
+		Addr:              httpAddr,
+		TLSConfig:         tlsConfig,
+		Protocols:         protocols,
+		Handler:           root,
+		ReadHeaderTimeout: cmp.Or(cfg.ReadHeaderTimeout, defaultReadHeaderTimeout),
+		ReadTimeout:       cmp.Or(cfg.ReadTimeout, defaultReadTimeout),
+		// responses to the requests reaching the handler deadline should still be written
+		WriteTimeout:   cmp.Or(cfg.WriteTimeout, max(defaultWriteTimeout, cfg.Timeout+time.Second)),
+		IdleTimeout:    cmp.Or(cfg.IdleTimeout, defaultIdleTimeout),
+		MaxHeaderBytes: cmp.Or(cfg.MaxHeaderBytes, defaultMaxHeaderBytes),
+		ErrorLog:       slog.NewLogLogger(p.Logger.Handler(), slog.LevelWarn),
 	}
 }
@@ -0,0 +1,159 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"order-persistor/internal/broadcast"
+	"order-persistor/internal/orders"
+	"order-persistor/internal/redact"
+	"time"
+)
+
+const (
+	// streamHeartbeat keeps idle streams from being closed by proxies.
+	streamHeartbeat = 15 * time.Second
+	// streamWriteTimeout disconnects the clients not reading the stream.
+	streamWriteTimeout = 10 * time.Second
+	// streamRetry is the delay browsers reconnect after, in milliseconds.
+	streamRetry = 3000
+)
+
+type StreamHandler struct {
+	Logger *slog.Logger
+	Hub    *broadcast.Hub
+	// Redaction masks personal data in events sent to callers without the PII scope.
+	Redaction *redact.Policy
+}
+
+// StreamOrders godoc
+// @Summary Stream orders
+// @Description Pushes the orders persisted by this instance as server-sent events named "order", each with
+// @Description the order JSON as data. Send the ID of the last received event in the Last-Event-ID header
+// @Description to resume: the recent orders published after it are sent first. Clients falling behind are
+// @Description disconnected and resume the same way.
+// @Description Personal data of the customer is masked unless the caller has the orders:read:pii scope.
+// @Tags orders
+// @Produce text/event-stream
+// @Param customer_id query string false "Stream only orders of the customer"
+// @Param delivery_service query string false "Stream only orders of the delivery service"
+// @Param Last-Event-ID header string false "ID of the last received event"
+// @Param X-Tenant header string false "Tenant whose orders are streamed, default if omitted"
+// @Success 200 {object} orderschema.Order "Stream of order events"
+// @Failure 401 {object} Error "Not authenticated"
+// @Failure 403 {object} Error "Missing orders:read scope or tenant is not allowed"
+// @Failure 429 {object} Error "Rate limit exceeded, see Retry-After header"
+// @Failure 500 {object} Error "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /orders/stream [get]
+func (h *StreamHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	ctx := r.Context()
+	log := h.Logger.With("url", r.URL)
+	rc := http.NewResponseController(w)
+
+	// the stream outlives the server write timeout, every write gets its own deadline instead
+	if err := rc.SetWriteDeadline(time.Time{}); err != nil {
+		log.ErrorContext(ctx, "streaming is not supported by response writer", "err", err)
+		responseInternalError.Write(w)
+		return
+	}
+
+	query := r.URL.Query()
+	filter := streamFilter{
+		customerID:      query.Get("customer_id"),
+		deliveryService: query.Get("delivery_service"),
+	}
+	filter.tenant, filter.limited = orders.TenantFrom(ctx)
+
+	pii := false
+	if p := PrincipalFrom(ctx); p != nil && p.HasScope(ScopeReadPII) {
+		pii = true
+	}
+
+	events := h.Hub.Subscribe(ctx, r.Header.Get("Last-Event-ID"))
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	// disables response buffering of nginx
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	send := func(format string, args ...any) bool {
+		_ = rc.SetWriteDeadline(time.Now().Add(streamWriteTimeout))
+		if _, err := fmt.Fprintf(w, format, args...); err != nil {
+			return false
+		}
+
+		return rc.Flush() == nil
+	}
+
+	if !send("retry: %d\n\n", streamRetry) {
+		return
+	}
+
+	heartbeat := time.NewTicker(streamHeartbeat)
+	defer heartbeat.Stop()
+
+	sent := 0
+	defer func() {
+		addLogFields(ctx, "events", sent)
+	}()
+
+	for {
+		select {
+		case e, ok := <-events:
+			if !ok {
+				if ctx.Err() == nil {
+					addLogFields(ctx, "stream", "fell behind")
+				}
+
+				return
+			}
+
+			if !filter.match(&e.Order) {
+				continue
+			}
+
+			order := &e.Order
+			if !pii {
+				order = h.Redaction.Order(order)
+			}
+
+			data, err := json.Marshal(order)
+			if err != nil {
+				log.ErrorContext(ctx, "marshalling streamed order", "err", err)
+				continue
+			}
+
+			if !send("id: %s\nevent: order\ndata: %s\n\n", e.ID, data) {
+				return
+			}
+
+			sent++
+		case <-heartbeat.C:
+			if !send(": heartbeat\n\n") {
+				return
+			}
+		}
+	}
+}
+
+type streamFilter struct {
+	tenant          string
+	limited         bool
+	customerID      string
+	deliveryService string
+}
+
+func (f *streamFilter) match(o *orders.Order) bool {
+	return (!f.limited || o.Tenant == f.tenant) &&
+		(f.customerID == "" || o.CustomerID == f.customerID) &&
+		(f.deliveryService == "" || o.DeliveryService == f.deliveryService)
+}
@@ -0,0 +1,95 @@
+package api
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"order-persistor/internal/broadcast"
+	"order-persistor/internal/orders"
+	"order-persistor/internal/redact"
+	"strings"
+	"testing"
+
+	"github.com/lezzercringe/some-assignment/orderschema"
+)
+
+func TestStreamHandler(t *testing.T) {
+	t.Parallel()
+
+	hub := broadcast.NewHub(0, 0)
+	redaction, _ := redact.NewPolicy(redact.Remove, "")
+
+	reader := &Principal{Name: "dashboard", Scopes: []string{ScopeRead}}
+	h := stackMiddleware(
+		&StreamHandler{Logger: slog.New(slog.DiscardHandler), Hub: hub, Redaction: redaction},
+		NewLogMiddleware(slog.New(slog.DiscardHandler)),
+		func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), principalKey{}, reader)))
+			})
+		},
+		NewTenantMiddleware(defaultTenantHeader),
+	)
+
+	srv := httptest.NewServer(h)
+	defer srv.Close()
+
+	matching := &orders.Order{Order: orderschema.Order{ID: "match", CustomerID: "c1", DeliveryService: "meest"}, Tenant: orders.DefaultTenant}
+	matching.Delivery.Phone = "+9720000000"
+
+	// published before connecting, they are replayed to the client resuming after an event of another process
+	hub.Publish(&orders.Order{Order: orderschema.Order{ID: "other-tenant", CustomerID: "c1", DeliveryService: "meest"}, Tenant: "acme"})
+	hub.Publish(&orders.Order{Order: orderschema.Order{ID: "other-customer", CustomerID: "c2", DeliveryService: "meest"}, Tenant: orders.DefaultTenant})
+	hub.Publish(&orders.Order{Order: orderschema.Order{ID: "other-service", CustomerID: "c1", DeliveryService: "dhl"}, Tenant: orders.DefaultTenant})
+	hub.Publish(matching)
+
+	ctx, cancel := context.WithCancel(t.Context())
+	defer cancel()
+
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL+"/orders/stream?customer_id=c1&delivery_service=meest", nil)
+	req.Header.Set("Last-Event-ID", "stale-1")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("unexpected content type %q", ct)
+	}
+
+	var id, event, data string
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() && data == "" {
+		field, value, _ := strings.Cut(scanner.Text(), ": ")
+		switch field {
+		case "id":
+			id = value
+		case "event":
+			event = value
+		case "data":
+			data = value
+		}
+	}
+
+	if id == "" || event != "order" {
+		t.Fatalf("unexpected event %q with id %q", event, id)
+	}
+
+	var got orders.Order
+	if err := json.Unmarshal([]byte(data), &got); err != nil {
+		t.Fatal(err)
+	}
+
+	if got.ID != matching.ID {
+		t.Errorf("expected only matching order to be streamed, got %s", got.ID)
+	}
+
+	if got.CustomerID != "" || got.Delivery.Phone != "" {
+		t.Errorf("expected personal data to be removed, got %+v", got)
+	}
+}
@@ -0,0 +1,175 @@
+package api
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"order-persistor/internal/config"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// certCheckInterval is how often the certificate files are checked for changes, at most.
+const certCheckInterval = 10 * time.Second
+
+// NewTLSConfig creates the TLS configuration of the API server, or nil if TLS is disabled.
+// Certificate is reloaded once its files change, so that renewed certificates are served without restart.
+func NewTLSConfig(cfg config.TLS, logger *slog.Logger) (*tls.Config, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+
+	reloader, err := newCertReloader(cfg.CertFile, cfg.KeyFile, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	tlsCfg := &tls.Config{
+		MinVersion:     tls.VersionTLS12,
+		GetCertificate: reloader.GetCertificate,
+	}
+
+	if cfg.MinVersion == "1.3" {
+		tlsCfg.MinVersion = tls.VersionTLS13
+	}
+
+	if cfg.ClientCAFile != "" {
+		pem, err := os.ReadFile(cfg.ClientCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("could not read client CA file: %w", err)
+		}
+
+		tlsCfg.ClientCAs = x509.NewCertPool()
+		if !tlsCfg.ClientCAs.AppendCertsFromPEM(pem) {
+			return nil, errors.New("client CA file has no certificates")
+		}
+
+		tlsCfg.ClientAuth = tls.RequireAndVerifyClientCert
+		if cfg.ClientAuth == "optional" {
+			tlsCfg.ClientAuth = tls.VerifyClientCertIfGiven
+		}
+	}
+
+	return tlsCfg, nil
+}
+
+// exemptFromClientAuth relaxes the required client certificates of tlsCfg to the routes other than
+// the probes and metrics, which kubelet and Prometheus request without certificates.
+// Certificates are then verified during the handshake if given, and required by h for the other routes.
+func exemptFromClientAuth(tlsCfg *tls.Config, h http.Handler, probes *http.ServeMux) (*tls.Config, http.Handler) {
+	if tlsCfg == nil || tlsCfg.ClientAuth != tls.RequireAndVerifyClientCert {
+		probes.Handle("/", h)
+		return tlsCfg, probes
+	}
+
+	relaxed := tlsCfg.Clone()
+	relaxed.ClientAuth = tls.VerifyClientCertIfGiven
+	probes.Handle("/", stackMiddleware(h, NewClientCertMiddleware()))
+
+	return relaxed, probes
+}
+
+// NewClientCertMiddleware lets through only the requests made with a verified client certificate.
+func NewClientCertMiddleware() Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.TLS == nil || len(r.TLS.VerifiedChains) == 0 {
+				responseUnauthorized.Write(w)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// certReloader serves the certificate of the files, reloading it when any of the files is modified.
+// A broken certificate is logged and the previous one keeps being served.
+type certReloader struct {
+	certFile string
+	keyFile  string
+	logger   *slog.Logger
+
+	lastCheck atomic.Int64
+
+	mu      sync.RWMutex
+	cert    *tls.Certificate
+	modTime time.Time
+}
+
+func newCertReloader(certFile, keyFile string, logger *slog.Logger) (*certReloader, error) {
+	r := &certReloader{
+		certFile: certFile,
+		keyFile:  keyFile,
+		logger:   logger,
+	}
+
+	if err := r.reload(); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+func (r *certReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	now := time.Now()
+	if last := r.lastCheck.Load(); now.Sub(time.Unix(0, last)) >= certCheckInterval && r.lastCheck.CompareAndSwap(last, now.UnixNano()) {
+		if err := r.reload(); err != nil {
+			r.logger.Error("could not reload tls certificate, serving the previous one", "err", err)
+		}
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.cert, nil
+}
+
+// reload loads the certificate if its files were modified since the last load.
+func (r *certReloader) reload() error {
+	modTime, err := latestModTime(r.certFile, r.keyFile)
+	if err != nil {
+		return err
+	}
+
+	r.mu.RLock()
+	unchanged := r.cert != nil && !modTime.After(r.modTime)
+	r.mu.RUnlock()
+
+	if unchanged {
+		return nil
+	}
+
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return fmt.Errorf("could not load tls certificate: %w", err)
+	}
+
+	r.mu.Lock()
+	r.cert, r.modTime = &cert, modTime
+	r.mu.Unlock()
+
+	r.logger.Info("loaded tls certificate", "cert_file", r.certFile, "modified", modTime)
+	return nil
+}
+
+func latestModTime(paths ...string) (time.Time, error) {
+	var latest time.Time
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return time.Time{}, err
+		}
+
+		if info.ModTime().After(latest) {
+			latest = info.ModTime()
+		}
+	}
+
+	return latest, nil
+}
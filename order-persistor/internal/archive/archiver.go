@@ -0,0 +1,223 @@
+package archive
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"order-persistor/internal/orders"
+	"os"
+	"time"
+
+	"github.com/lezzercringe/some-assignment/orderschema"
+)
+
+// maxLineSize limits the size of a single archived order.
+const maxLineSize = 16 << 20
+
+var ErrCountMismatch = errors.New("archived orders count mismatch")
+
+// Record is an archived order. The fields internal to the persistor are not encoded with the order,
+// so they are written next to the order fields explicitly.
+type Record struct {
+	orderschema.Order
+	Tenant       string     `json:"tenant"`
+	AnonymizedAt *time.Time `json:"anonymized_at,omitempty"`
+	// DeliveryEncryption is set if the delivery name, phone, address and email are archived encrypted, as they are stored.
+	DeliveryEncryption *orders.SealedDelivery `json:"delivery_encryption,omitempty"`
+}
+
+func newRecord(o *orders.Order) Record {
+	r := Record{Order: o.Order, Tenant: o.Tenant, DeliveryEncryption: o.Sealed}
+	if !o.AnonymizedAt.IsZero() {
+		r.AnonymizedAt = &o.AnonymizedAt
+	}
+
+	return r
+}
+
+type Repository interface {
+	CountBefore(ctx context.Context, before time.Time) (int, error)
+	ListBefore(ctx context.Context, c orders.Cursor, n int) ([]orders.Order, error)
+	DeleteByIDs(ctx context.Context, ids []string) (int, error)
+}
+
+type Result struct {
+	Name     string
+	Exported int
+	Deleted  int
+}
+
+// Archiver moves orders older than a cutoff from the database into a gzip-compressed
+// newline-delimited JSON file, with items and payments embedded into their orders.
+// The repository is expected to read encrypted orders without decrypting them, so they are archived encrypted.
+type Archiver struct {
+	repository Repository
+	storage    Storage
+	batchSize  int
+	logger     *slog.Logger
+}
+
+func NewArchiver(repository Repository, storage Storage, batchSize int, logger *slog.Logger) *Archiver {
+	return &Archiver{
+		repository: repository,
+		storage:    storage,
+		batchSize:  batchSize,
+		logger:     logger,
+	}
+}
+
+// Run exports all the orders created before cutoff, verifies the stored archive
+// and only then deletes the archived orders from the database.
+// Nothing is deleted if the archive does not contain exactly as many orders as the database had.
+func (a *Archiver) Run(ctx context.Context, cutoff time.Time) (*Result, error) {
+	expected, err := a.repository.CountBefore(ctx, cutoff)
+	if err != nil {
+		return nil, fmt.Errorf("could not count orders: %w", err)
+	}
+
+	res := &Result{
+		Name: fmt.Sprintf("orders-before-%s.ndjson.gz", cutoff.UTC().Format("20060102T150405Z")),
+	}
+
+	if expected == 0 {
+		a.logger.Info("archive: nothing to archive", "cutoff", cutoff)
+		return res, nil
+	}
+
+	res.Exported, err = a.export(ctx, cutoff, res.Name)
+	if err != nil {
+		return nil, fmt.Errorf("could not export orders: %w", err)
+	}
+
+	a.logger.Info("archive: exported orders", "name", res.Name, "exported", res.Exported)
+
+	stored, err := a.walk(ctx, res.Name, func([]string) error { return nil })
+	if err != nil {
+		return nil, fmt.Errorf("could not verify archive: %w", err)
+	}
+
+	if stored != expected || res.Exported != expected {
+		return nil, fmt.Errorf("%w: expected %d, exported %d, stored %d", ErrCountMismatch, expected, res.Exported, stored)
+	}
+
+	a.logger.Info("archive: verified archive", "name", res.Name, "orders", stored)
+
+	_, err = a.walk(ctx, res.Name, func(ids []string) error {
+		deleted, err := a.repository.DeleteByIDs(ctx, ids)
+		res.Deleted += deleted
+		return err
+	})
+	if err != nil {
+		return res, fmt.Errorf("could not delete archived orders: %w", err)
+	}
+
+	a.logger.Info("archive: deleted archived orders", "deleted", res.Deleted)
+	return res, nil
+}
+
+// export writes the orders into a local temporary file first, so the storage receives the archive in one piece.
+func (a *Archiver) export(ctx context.Context, cutoff time.Time, name string) (int, error) {
+	tmp, err := os.CreateTemp("", name+".*")
+	if err != nil {
+		return 0, err
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	gz := gzip.NewWriter(tmp)
+	enc := json.NewEncoder(gz)
+
+	var exported int
+	cursor := orders.Cursor{CreatedAt: cutoff}
+
+	for {
+		page, err := a.repository.ListBefore(ctx, cursor, a.batchSize)
+		if err != nil {
+			return 0, err
+		}
+
+		for i := range page {
+			if err := enc.Encode(newRecord(&page[i])); err != nil {
+				return 0, err
+			}
+		}
+
+		exported += len(page)
+		if len(page) < a.batchSize {
+			break
+		}
+
+		cursor = orders.CursorOf(&page[len(page)-1])
+	}
+
+	if err := gz.Close(); err != nil {
+		return 0, err
+	}
+
+	if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+		return 0, err
+	}
+
+	return exported, a.storage.Put(ctx, name, tmp)
+}
+
+// walk reads the stored archive, passing IDs of the orders to fn by batches, and returns the number of orders read.
+func (a *Archiver) walk(ctx context.Context, name string, fn func(ids []string) error) (int, error) {
+	f, err := a.storage.Get(ctx, name)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return 0, err
+	}
+
+	scanner := bufio.NewScanner(gz)
+	scanner.Buffer(nil, maxLineSize)
+
+	var (
+		read  int
+		batch = make([]string, 0, a.batchSize)
+	)
+
+	for scanner.Scan() {
+		var order struct {
+			ID string `json:"order_uid"`
+		}
+
+		if err := json.Unmarshal(scanner.Bytes(), &order); err != nil {
+			return read, fmt.Errorf("line %d: %w", read+1, err)
+		}
+
+		read++
+		batch = append(batch, order.ID)
+
+		if len(batch) == a.batchSize {
+			if err := fn(batch); err != nil {
+				return read, err
+			}
+
+			batch = batch[:0]
+		}
+	}
+
+	// gzip reader verifies the checksum once the stream is fully read
+	if err := scanner.Err(); err != nil {
+		return read, err
+	}
+
+	if len(batch) > 0 {
+		if err := fn(batch); err != nil {
+			return read, err
+		}
+	}
+
+	return read, nil
+}
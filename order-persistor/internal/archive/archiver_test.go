@@ -0,0 +1,163 @@
+package archive
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"order-persistor/internal/mocks"
+	"order-persistor/internal/orders"
+	"testing"
+	"time"
+
+	"go.uber.org/mock/gomock"
+
+	"github.com/lezzercringe/some-assignment/orderschema"
+)
+
+// expectStorage makes the storage mock keep the single put archive in memory, returning the kept archive.
+func expectStorage(storage *mocks.MockStorage) *[]byte {
+	var stored []byte
+
+	storage.EXPECT().
+		Put(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ string, r io.Reader) error {
+			var err error
+			stored, err = io.ReadAll(r)
+			return err
+		}).
+		Times(1)
+
+	storage.EXPECT().
+		Get(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(context.Context, string) (io.ReadCloser, error) {
+			return io.NopCloser(bytes.NewReader(stored)), nil
+		}).
+		AnyTimes()
+
+	return &stored
+}
+
+func TestArchiver_Run(t *testing.T) {
+	t.Parallel()
+
+	log := slog.New(slog.DiscardHandler)
+	cutoff := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	archived := []orders.Order{
+		{Order: orderschema.Order{ID: "first", CreatedAt: cutoff.Add(-time.Hour)}},
+		{Order: orderschema.Order{ID: "second", CreatedAt: cutoff.Add(-2 * time.Hour)}},
+		{Order: orderschema.Order{ID: "third", CreatedAt: cutoff.Add(-3 * time.Hour)}},
+	}
+
+	t.Run("deletes orders after archive is verified", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		rep := mocks.NewMockArchiveRepository(ctrl)
+		storage := mocks.NewMockStorage(ctrl)
+		expectStorage(storage)
+
+		rep.EXPECT().CountBefore(gomock.Any(), cutoff).Return(3, nil)
+		gomock.InOrder(
+			rep.EXPECT().
+				ListBefore(gomock.Any(), orders.Cursor{CreatedAt: cutoff}, 2).
+				Return(archived[:2], nil),
+			rep.EXPECT().
+				ListBefore(gomock.Any(), orders.CursorOf(&archived[1]), 2).
+				Return(archived[2:], nil),
+			rep.EXPECT().
+				DeleteByIDs(gomock.Any(), []string{"first", "second"}).
+				Return(2, nil),
+			rep.EXPECT().
+				DeleteByIDs(gomock.Any(), []string{"third"}).
+				Return(1, nil),
+		)
+
+		res, err := NewArchiver(rep, storage, 2, log).Run(context.Background(), cutoff)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if res.Exported != 3 || res.Deleted != 3 {
+			t.Fatalf("unexpected result: %+v", res)
+		}
+	})
+
+	t.Run("archive keeps tenant, anonymization and encryption of orders", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		anonymizedAt := cutoff.Add(-30 * time.Minute)
+		tenanted := []orders.Order{
+			{Order: orderschema.Order{ID: "first", CreatedAt: cutoff.Add(-time.Hour)}, Tenant: "marketplace-a", AnonymizedAt: anonymizedAt},
+			{
+				Order:  orderschema.Order{ID: "second", CreatedAt: cutoff.Add(-2 * time.Hour), Delivery: orders.Delivery{Name: "enc:v1:c2VhbGVk"}},
+				Tenant: "marketplace-b",
+				Sealed: &orders.SealedDelivery{KeyID: "2026-10", DataKey: []byte("encrypted data key")},
+			},
+		}
+
+		rep := mocks.NewMockArchiveRepository(ctrl)
+		storage := mocks.NewMockStorage(ctrl)
+		stored := expectStorage(storage)
+
+		rep.EXPECT().CountBefore(gomock.Any(), cutoff).Return(2, nil)
+		rep.EXPECT().ListBefore(gomock.Any(), orders.Cursor{CreatedAt: cutoff}, 10).Return(tenanted, nil)
+		rep.EXPECT().DeleteByIDs(gomock.Any(), []string{"first", "second"}).Return(2, nil)
+
+		if _, err := NewArchiver(rep, storage, 10, log).Run(context.Background(), cutoff); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		gz, err := gzip.NewReader(bytes.NewReader(*stored))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		dec := json.NewDecoder(gz)
+		for _, want := range tenanted {
+			var got Record
+			if err := dec.Decode(&got); err != nil {
+				t.Fatalf("could not read archived order %s: %v", want.ID, err)
+			}
+
+			if got.ID != want.ID || got.Tenant != want.Tenant {
+				t.Errorf("expected order %s of tenant %s, got %s of %s", want.ID, want.Tenant, got.ID, got.Tenant)
+			}
+
+			if (got.AnonymizedAt == nil) != want.AnonymizedAt.IsZero() ||
+				(got.AnonymizedAt != nil && !got.AnonymizedAt.Equal(want.AnonymizedAt)) {
+				t.Errorf("expected order %s anonymized at %v, got %v", want.ID, want.AnonymizedAt, got.AnonymizedAt)
+			}
+
+			if got.Delivery.Name != want.Delivery.Name || (got.DeliveryEncryption == nil) != (want.Sealed == nil) ||
+				(got.DeliveryEncryption != nil && (got.DeliveryEncryption.KeyID != want.Sealed.KeyID ||
+					!bytes.Equal(got.DeliveryEncryption.DataKey, want.Sealed.DataKey))) {
+				t.Errorf("expected order %s delivery %q sealed with %+v, got %q with %+v",
+					want.ID, want.Delivery.Name, want.Sealed, got.Delivery.Name, got.DeliveryEncryption)
+			}
+		}
+	})
+
+	t.Run("does not delete anything on count mismatch", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		rep := mocks.NewMockArchiveRepository(ctrl)
+		storage := mocks.NewMockStorage(ctrl)
+		expectStorage(storage)
+
+		rep.EXPECT().CountBefore(gomock.Any(), cutoff).Return(4, nil)
+		rep.EXPECT().
+			ListBefore(gomock.Any(), orders.Cursor{CreatedAt: cutoff}, 10).
+			Return(archived, nil)
+
+		_, err := NewArchiver(rep, storage, 10, log).Run(context.Background(), cutoff)
+		if !errors.Is(err, ErrCountMismatch) {
+			t.Fatalf("expected count mismatch error, got: %v", err)
+		}
+	})
+}
@@ -0,0 +1,115 @@
+package archive
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+	"order-persistor/internal/config"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+)
+
+// Storage keeps archive files by name.
+type Storage interface {
+	Put(ctx context.Context, name string, r io.Reader) error
+	Get(ctx context.Context, name string) (io.ReadCloser, error)
+}
+
+// NewStorage creates the storage for the target URL, which is either file:///dir or s3://bucket/prefix.
+func NewStorage(target string, s3cfg config.ArchiveS3) (Storage, error) {
+	u, err := url.Parse(target)
+	if err != nil {
+		return nil, fmt.Errorf("invalid archive target: %w", err)
+	}
+
+	switch u.Scheme {
+	case "file":
+		return &FSStorage{Dir: u.Path}, nil
+	case "s3":
+		client, err := minio.New(s3cfg.Endpoint, &minio.Options{
+			Creds:  credentials.NewStaticV4(s3cfg.AccessKey, s3cfg.SecretKey, ""),
+			Secure: s3cfg.UseSSL,
+			Region: s3cfg.Region,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("could not create s3 client: %w", err)
+		}
+
+		return &S3Storage{
+			Client: client,
+			Bucket: u.Host,
+			Prefix: strings.TrimPrefix(u.Path, "/"),
+		}, nil
+	default:
+		return nil, fmt.Errorf("unsupported archive target scheme: %q", u.Scheme)
+	}
+}
+
+type FSStorage struct {
+	Dir string
+}
+
+// Put writes the file under a temporary name first, so a partially written archive is never visible.
+func (s *FSStorage) Put(ctx context.Context, name string, r io.Reader) error {
+	if err := os.MkdirAll(s.Dir, 0o755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(s.Dir, name+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, r); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), filepath.Join(s.Dir, name))
+}
+
+func (s *FSStorage) Get(ctx context.Context, name string) (io.ReadCloser, error) {
+	return os.Open(filepath.Join(s.Dir, name))
+}
+
+type S3Storage struct {
+	Client *minio.Client
+	Bucket string
+	Prefix string
+}
+
+func (s *S3Storage) Put(ctx context.Context, name string, r io.Reader) error {
+	_, err := s.Client.PutObject(ctx, s.Bucket, s.key(name), r, -1, minio.PutObjectOptions{
+		ContentType:     "application/x-ndjson",
+		ContentEncoding: "gzip",
+	})
+
+	return err
+}
+
+func (s *S3Storage) Get(ctx context.Context, name string) (io.ReadCloser, error) {
+	return s.Client.GetObject(ctx, s.Bucket, s.key(name), minio.GetObjectOptions{})
+}
+
+func (s *S3Storage) key(name string) string {
+	if s.Prefix == "" {
+		return name
+	}
+
+	return strings.TrimSuffix(s.Prefix, "/") + "/" + name
+}
@@ -0,0 +1,162 @@
+// Package broadcast fans out the orders persisted by this instance to live subscribers, like gRPC watchers
+// and server-sent event streams.
+package broadcast
+
+import (
+	"cmp"
+	"context"
+	"order-persistor/internal/orders"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultBuffer = 100
+	defaultReplay = 1000
+)
+
+// Event is a published order along with its ID, which subscribers resume after.
+type Event struct {
+	// ID is unique within the hub. IDs of a hub differ from the ones of the hubs of other processes.
+	ID    string
+	Order orders.Order
+
+	seq uint64
+}
+
+// Hub delivers published orders to all the current subscribers without blocking the publisher.
+// It keeps a number of the latest events to replay them to the subscribers resuming after a disconnect.
+type Hub struct {
+	buffer int
+	replay int
+	// epoch tells events of this hub from the ones of the previous processes.
+	epoch string
+
+	mu     sync.Mutex
+	seq    uint64
+	recent []Event
+	subs   map[chan Event]struct{}
+}
+
+// NewHub creates a hub letting subscribers fall behind by up to buffer orders and replaying up to replay latest
+// orders to the resuming ones.
+func NewHub(buffer, replay int) *Hub {
+	if buffer <= 0 {
+		buffer = defaultBuffer
+	}
+
+	if replay <= 0 {
+		replay = defaultReplay
+	}
+
+	return &Hub{
+		buffer: buffer,
+		replay: replay,
+		epoch:  strconv.FormatInt(time.Now().UnixNano(), 36),
+		subs:   make(map[chan Event]struct{}),
+	}
+}
+
+// Subscribe returns the channel of events published after the call. If lastEventID is set, the channel starts
+// with the kept events published after it, or all the kept events if it was published by another process.
+// The channel is closed once ctx is done, or earlier if the subscriber falls behind by more than the buffer,
+// which is told by ctx not being done.
+func (h *Hub) Subscribe(ctx context.Context, lastEventID string) <-chan Event {
+	h.mu.Lock()
+
+	var missed []Event
+	if lastEventID != "" {
+		missed = h.after(lastEventID)
+	}
+
+	ch := make(chan Event, h.buffer+len(missed))
+	for _, e := range missed {
+		ch <- e
+	}
+
+	h.subs[ch] = struct{}{}
+	h.mu.Unlock()
+
+	context.AfterFunc(ctx, func() {
+		h.unsubscribe(ch)
+	})
+
+	return ch
+}
+
+// Publish delivers the order to the subscribers, dropping the ones whose buffers are full.
+func (h *Hub) Publish(o *orders.Order) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.seq++
+	e := Event{
+		ID:    h.epoch + "-" + strconv.FormatUint(h.seq, 10),
+		Order: *o,
+		seq:   h.seq,
+	}
+
+	h.recent = append(h.recent, e)
+	if len(h.recent) > h.replay {
+		h.recent = h.recent[len(h.recent)-h.replay:]
+	}
+
+	for ch := range h.subs {
+		select {
+		case ch <- e:
+		default:
+			delete(h.subs, ch)
+			close(ch)
+		}
+	}
+}
+
+// Forget drops the kept events of the orders with the IDs, so that their personal data, once erased,
+// is not replayed. Events already delivered to the subscribers are not recalled.
+func (h *Hub) Forget(ids ...string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.recent = slices.DeleteFunc(h.recent, func(e Event) bool {
+		return slices.Contains(ids, e.Order.ID)
+	})
+}
+
+// Purge drops all the kept events, so that resuming subscribers get none of the events published before.
+func (h *Hub) Purge() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	clear(h.recent)
+	h.recent = h.recent[:0]
+}
+
+// after returns the kept events published after the event with the ID.
+func (h *Hub) after(id string) []Event {
+	epoch, seq, _ := strings.Cut(id, "-")
+	n, err := strconv.ParseUint(seq, 10, 64)
+	if epoch != h.epoch || err != nil {
+		// the event is not known, the subscriber could have missed any of the kept events
+		return h.recent
+	}
+
+	// the kept events are ordered by sequence number, which has gaps where events were forgotten
+	i, _ := slices.BinarySearchFunc(h.recent, n+1, func(e Event, seq uint64) int {
+		return cmp.Compare(e.seq, seq)
+	})
+
+	return h.recent[i:]
+}
+
+func (h *Hub) unsubscribe(ch chan Event) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if _, ok := h.subs[ch]; ok {
+		delete(h.subs, ch)
+		close(ch)
+	}
+}
@@ -0,0 +1,156 @@
+package broadcast
+
+import (
+	"context"
+	"order-persistor/internal/orders"
+	"testing"
+
+	"github.com/lezzercringe/some-assignment/orderschema"
+)
+
+func receive(t *testing.T, ch <-chan Event, n int) []Event {
+	t.Helper()
+
+	res := make([]Event, 0, n)
+	for range n {
+		select {
+		case e, ok := <-ch:
+			if !ok {
+				t.Fatalf("channel closed after %d events", len(res))
+			}
+
+			res = append(res, e)
+		default:
+			t.Fatalf("expected %d events, got %d", n, len(res))
+		}
+	}
+
+	return res
+}
+
+func TestHub_replay(t *testing.T) {
+	t.Parallel()
+
+	hub := NewHub(10, 3)
+
+	live := hub.Subscribe(t.Context(), "")
+	for _, id := range []string{"1", "2", "3", "4"} {
+		hub.Publish(&orders.Order{Order: orderschema.Order{ID: id}})
+	}
+
+	events := receive(t, live, 4)
+
+	tests := []struct {
+		name        string
+		lastEventID string
+		want        []string
+	}{
+		{name: "no last event", lastEventID: "", want: nil},
+		{name: "latest event", lastEventID: events[3].ID, want: nil},
+		{name: "kept event", lastEventID: events[1].ID, want: []string{"3", "4"}},
+		{name: "dropped event", lastEventID: events[0].ID, want: []string{"2", "3", "4"}},
+		{name: "event of another process", lastEventID: "stale-2", want: []string{"2", "3", "4"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ch := hub.Subscribe(t.Context(), tt.lastEventID)
+
+			got := receive(t, ch, len(tt.want))
+			for i, e := range got {
+				if e.Order.ID != tt.want[i] {
+					t.Errorf("expected order %s at %d, got %s", tt.want[i], i, e.Order.ID)
+				}
+			}
+
+			if len(ch) != 0 {
+				t.Errorf("expected no more events, got %d", len(ch))
+			}
+		})
+	}
+}
+
+func TestHub_slowSubscriber(t *testing.T) {
+	t.Parallel()
+
+	hub := NewHub(1, 0)
+
+	ctx, cancel := context.WithCancel(t.Context())
+	slow := hub.Subscribe(ctx, "")
+
+	hub.Publish(&orders.Order{Order: orderschema.Order{ID: "1"}})
+	hub.Publish(&orders.Order{Order: orderschema.Order{ID: "2"}})
+
+	receive(t, slow, 1)
+	if _, ok := <-slow; ok {
+		t.Fatal("expected slow subscriber to be dropped")
+	}
+
+	if ctx.Err() != nil {
+		t.Fatal("expected context not to be done")
+	}
+
+	// unsubscribing the dropped subscriber must not close its channel again
+	cancel()
+}
+
+func TestHub_Forget(t *testing.T) {
+	t.Parallel()
+
+	hub := NewHub(10, 10)
+
+	live := hub.Subscribe(t.Context(), "")
+	for _, id := range []string{"1", "2", "3", "4"} {
+		hub.Publish(&orders.Order{Order: orderschema.Order{ID: id}})
+	}
+
+	events := receive(t, live, 4)
+	hub.Forget("2", "4")
+
+	tests := []struct {
+		name        string
+		lastEventID string
+		want        []string
+	}{
+		{name: "before forgotten event", lastEventID: events[0].ID, want: []string{"3"}},
+		{name: "forgotten event", lastEventID: events[1].ID, want: []string{"3"}},
+		{name: "latest forgotten event", lastEventID: events[3].ID, want: nil},
+		{name: "event of another process", lastEventID: "stale-2", want: []string{"1", "3"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ch := hub.Subscribe(t.Context(), tt.lastEventID)
+
+			got := receive(t, ch, len(tt.want))
+			for i, e := range got {
+				if e.Order.ID != tt.want[i] {
+					t.Errorf("expected order %s at %d, got %s", tt.want[i], i, e.Order.ID)
+				}
+			}
+
+			if len(ch) != 0 {
+				t.Errorf("expected no more events, got %d", len(ch))
+			}
+		})
+	}
+}
+
+func TestHub_Purge(t *testing.T) {
+	t.Parallel()
+
+	hub := NewHub(10, 10)
+
+	live := hub.Subscribe(t.Context(), "")
+	hub.Publish(&orders.Order{Order: orderschema.Order{ID: "1"}})
+	hub.Publish(&orders.Order{Order: orderschema.Order{ID: "2"}})
+
+	events := receive(t, live, 2)
+	hub.Purge()
+	hub.Publish(&orders.Order{Order: orderschema.Order{ID: "3"}})
+
+	ch := hub.Subscribe(t.Context(), events[0].ID)
+	if got := receive(t, ch, 1); got[0].Order.ID != "3" || len(ch) != 0 {
+		t.Errorf("expected only the order published after purge, got %s and %d more", got[0].Order.ID, len(ch))
+	}
+}
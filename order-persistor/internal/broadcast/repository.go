@@ -0,0 +1,43 @@
+package broadcast
+
+import (
+	"context"
+	"order-persistor/internal/orders"
+)
+
+var _ orders.Repository = &PublishingRepository{}
+
+// PublishingRepository publishes every created order to the hub.
+type PublishingRepository struct {
+	decoratee orders.Repository
+	hub       *Hub
+}
+
+func NewPublishingRepository(decoratee orders.Repository, hub *Hub) *PublishingRepository {
+	return &PublishingRepository{
+		decoratee: decoratee,
+		hub:       hub,
+	}
+}
+
+func (r *PublishingRepository) Create(ctx context.Context, o *orders.Order) (*orders.Order, error) {
+	inserted, err := r.decoratee.Create(ctx, o)
+	if err != nil {
+		return nil, err
+	}
+
+	r.hub.Publish(inserted)
+	return inserted, nil
+}
+
+func (r *PublishingRepository) GetByID(ctx context.Context, id string) (*orders.Order, error) {
+	return r.decoratee.GetByID(ctx, id)
+}
+
+func (r *PublishingRepository) ListRecent(ctx context.Context, n int) ([]orders.Order, error) {
+	return r.decoratee.ListRecent(ctx, n)
+}
+
+func (r *PublishingRepository) ListBefore(ctx context.Context, c orders.Cursor, n int) ([]orders.Order, error) {
+	return r.decoratee.ListBefore(ctx, c, n)
+}
@@ -6,32 +6,232 @@ type Log struct {
 	Level string `yaml:"level" validate:"required"`
 }
 
+// Redaction configures masking of personal data of customers: partial, hash or remove.
+type Redaction struct {
+	// Logs is the masking of personal data in logged messages, partial by default.
+	Logs string `yaml:"logs" validate:"omitempty,oneof=partial hash remove"`
+	// Responses is the masking of personal data in API responses to callers without the PII scope, remove by default.
+	Responses string `yaml:"responses" validate:"omitempty,oneof=partial hash remove"`
+	// HashKey keys the hashes, so that values with little entropy could not be recovered by brute force.
+	// It also keys the subject hashes of the GDPR audit, so it is required if auth is enabled.
+	HashKey string `yaml:"hash_key"`
+}
+
+// Encryption configures envelope encryption of delivery name, phone, address and email of orders.
+// Only the stored orders are encrypted: API responses, order streams and GDPR exports carry the decrypted data,
+// while archives keep the orders encrypted as they are stored, so orders stored in plain text are archived in plain text.
+type Encryption struct {
+	Enabled bool `yaml:"enabled"`
+	// KeyFile is the local file with key encryption keys and the blind index key.
+	KeyFile string `yaml:"key_file" validate:"required_if=Enabled true"`
+}
+
 type Cache struct {
 	Size int `yaml:"size" validate:"required,gte=0"`
 }
 
 type Prefill struct {
-	Enabled bool          `yaml:"enabled"`
-	Timeout time.Duration `yaml:"timeout"`
+	Enabled  bool          `yaml:"enabled"`
+	Timeout  time.Duration `yaml:"timeout"`
+	PageSize int           `yaml:"page_size"`
+	// GateReadiness keeps the service not ready until prefill is either completed or failed.
+	GateReadiness bool `yaml:"gate_readiness"`
 }
 
 type KafkaConsumer struct {
-	Servers            string        `yaml:"servers" validate:"required"`
-	GroupID            string        `yaml:"group_id"`
-	Topic              string        `yaml:"topic" validate:"required"`
+	Servers string `yaml:"servers" validate:"required"`
+	GroupID string `yaml:"group_id"`
+	// Topic is consumed with the default settings, while Topics allow several topics with overrides.
+	// At least one of them is required.
+	Topic              string        `yaml:"topic" validate:"required_without=Topics"`
+	Topics             []KafkaTopic  `yaml:"topics" validate:"dive"`
 	ReadTimeout        time.Duration `yaml:"read_timeout" validate:"required"`
 	ProcessTimeout     time.Duration `yaml:"process_timeout" validate:"required"`
 	ReadFailureBackoff time.Duration `yaml:"read_failure_backoff" validate:"required"`
+	// Strict makes the consumer validate messages against the order JSON Schema and reject unknown fields.
+	// It is the default validation profile of the topics.
+	Strict bool `yaml:"strict"`
+}
+
+// KafkaTopic overrides consumption settings of a topic.
+type KafkaTopic struct {
+	// Name is a topic name, or a regular expression matching topic names if it starts with ^.
+	Name string `yaml:"name" validate:"required"`
+	// Decoder is the encoding of messages: json (default) or gzip+json.
+	Decoder string `yaml:"decoder" validate:"omitempty,oneof=json gzip+json"`
+	// Profile is the validation profile: default checks validation rules only, schema also checks JSON Schema
+	// and strict also rejects unknown fields. Defaults to strict if Strict is set and to default otherwise.
+	Profile string `yaml:"profile" validate:"omitempty,oneof=default schema strict"`
+	// Tenant owns the orders consumed from the topic, orders.DefaultTenant if empty.
+	Tenant string `yaml:"tenant"`
 }
 
 type API struct {
-	Host    string        `yaml:"host" validate:"required"`
-	Port    string        `yaml:"port" validate:"required"`
+	Host string `yaml:"host" validate:"required"`
+	Port string `yaml:"port" validate:"required"`
+	// Timeout is the deadline of order lookups, including the repository reads.
 	Timeout time.Duration `yaml:"timeout" validate:"required"`
+	// ReadHeaderTimeout, ReadTimeout, WriteTimeout and IdleTimeout bound the phases of connections, see http.Server.
+	// They are 5s, 10s, 30s and 2m by default.
+	ReadHeaderTimeout time.Duration `yaml:"read_header_timeout" validate:"gte=0"`
+	ReadTimeout       time.Duration `yaml:"read_timeout" validate:"gte=0"`
+	WriteTimeout      time.Duration `yaml:"write_timeout" validate:"gte=0"`
+	IdleTimeout       time.Duration `yaml:"idle_timeout" validate:"gte=0"`
+	// MaxHeaderBytes limits the size of request headers, 64KB by default.
+	MaxHeaderBytes int `yaml:"max_header_bytes" validate:"gte=0"`
+	// TenantHeader is the request header with the tenant whose orders are read, X-Tenant by default.
+	// Requests without the header read the orders of orders.DefaultTenant.
+	TenantHeader string `yaml:"tenant_header"`
+	// CORSOrigins are the origins allowed to call the API from browsers, any origin if empty.
+	CORSOrigins []string  `yaml:"cors_origins"`
+	Auth        Auth      `yaml:"auth"`
+	RateLimit   RateLimit `yaml:"rate_limit"`
+	TLS         TLS       `yaml:"tls"`
+	// UnencryptedHTTP2 serves HTTP/2 without TLS (h2c) along with HTTP/1, for proxies talking HTTP/2 to the service.
+	// HTTP/2 is always served over TLS.
+	UnencryptedHTTP2 bool `yaml:"unencrypted_http2"`
+	// CacheControl is sent with orders, "private, no-cache" by default: clients revalidate them with the ETag
+	// and shared caches do not keep them, as the responses depend on the caller.
+	CacheControl string `yaml:"cache_control"`
+	// Compression compresses responses with gzip for the clients accepting it.
+	Compression bool `yaml:"compression"`
+}
+
+// GRPC configures the gRPC API, served on its own port along with the REST one.
+// It shares authentication, TLS and redaction settings with the REST API.
+type GRPC struct {
+	Enabled bool   `yaml:"enabled"`
+	Host    string `yaml:"host"`
+	Port    string `yaml:"port" validate:"required_if=Enabled true"`
+}
+
+// Broadcast configures delivering the consumed orders to the gRPC watchers and the event stream clients.
+type Broadcast struct {
+	// Buffer is the number of orders a client may fall behind by before it is disconnected, 100 by default.
+	Buffer int `yaml:"buffer" validate:"gte=0"`
+	// Replay is the number of the latest orders kept to resend to the resuming clients, 1000 by default.
+	Replay int `yaml:"replay" validate:"gte=0"`
+}
+
+// TLS configures serving the API over HTTPS.
+type TLS struct {
+	Enabled bool `yaml:"enabled"`
+	// CertFile and KeyFile are PEM files, which are reloaded when they change on disk.
+	CertFile string `yaml:"cert_file" validate:"required_if=Enabled true"`
+	KeyFile  string `yaml:"key_file" validate:"required_if=Enabled true"`
+	// ClientCAFile enables mTLS: client certificates are verified against the CA certificates in the PEM file.
+	ClientCAFile string `yaml:"client_ca_file"`
+	// ClientAuth is require (default) to reject clients without certificates, or optional to verify only the given ones.
+	// The REST API serves /readyz and /metrics without certificates in either case, for probes and scrapes.
+	ClientAuth string `yaml:"client_auth" validate:"omitempty,oneof=require optional"`
+	// MinVersion is the minimal TLS version, 1.2 (default) or 1.3.
+	MinVersion string `yaml:"min_version" validate:"omitempty,oneof=1.2 1.3"`
+}
+
+// RateLimit configures token buckets of API clients. Authenticated callers are limited by their names,
+// anonymous ones by IP addresses.
+type RateLimit struct {
+	Enabled bool `yaml:"enabled"`
+	// Rate is the sustained number of order lookups per second of a client, Burst is the size of its bucket.
+	Rate  float64 `yaml:"rate" validate:"gte=0"`
+	Burst int     `yaml:"burst" validate:"gte=0"`
+	// UncachedRate and UncachedBurst additionally limit the lookups missing the cache, which cost database queries.
+	// Such lookups are not limited separately if UncachedRate is zero.
+	UncachedRate  float64 `yaml:"uncached_rate" validate:"gte=0"`
+	UncachedBurst int     `yaml:"uncached_burst" validate:"gte=0"`
+	// IPRate and IPBurst limit the requests of every IP address before authentication, so that guessing
+	// of credentials is limited too. They default to Rate and Burst.
+	IPRate  float64 `yaml:"ip_rate" validate:"gte=0"`
+	IPBurst int     `yaml:"ip_burst" validate:"gte=0"`
+	// MaxClients bounds the number of tracked clients, the least recently seen ones are forgotten.
+	MaxClients int `yaml:"max_clients" validate:"gte=0"`
+	// TrustForwardedFor identifies anonymous clients by the last address of X-Forwarded-For,
+	// which should only be enabled behind a proxy setting the header.
+	TrustForwardedFor bool `yaml:"trust_forwarded_for"`
+}
+
+// Auth configures authentication of API requests. Requests are not authenticated if it is disabled.
+type Auth struct {
+	Enabled bool     `yaml:"enabled"`
+	APIKeys []APIKey `yaml:"api_keys" validate:"dive"`
+	JWT     JWT      `yaml:"jwt"`
+}
+
+// APIKey is a static key sent in the X-API-Key header.
+type APIKey struct {
+	Name   string   `yaml:"name" validate:"required"`
+	Key    string   `yaml:"key" validate:"required,min=16"`
+	Scopes []string `yaml:"scopes"`
+	// Tenant limits the key to the orders of a single tenant.
+	Tenant string `yaml:"tenant"`
+}
+
+// JWT configures validation of bearer tokens. Tokens are not accepted if neither JWKS file nor URL is set.
+type JWT struct {
+	JWKSFile string `yaml:"jwks_file"`
+	JWKSURL  string `yaml:"jwks_url" validate:"omitempty,url"`
+	Issuer   string `yaml:"issuer"`
+	Audience string `yaml:"audience"`
+	// ScopeClaim holds either space-separated scopes or an array of them, scope by default.
+	ScopeClaim string `yaml:"scope_claim"`
+	// TenantClaim limits the token to the orders of the tenant it holds, if set.
+	TenantClaim string `yaml:"tenant_claim"`
 }
 
 type Postgres struct {
-	ConnString string `yaml:"conn_string" validate:"required"`
+	ConnString string          `yaml:"conn_string" validate:"required"`
+	Replica    PostgresReplica `yaml:"replica"`
+}
+
+// PostgresReplica configures an optional read replica. Replica is not used if ConnString is empty.
+type PostgresReplica struct {
+	ConnString          string        `yaml:"conn_string"`
+	HealthCheckInterval time.Duration `yaml:"health_check_interval"`
+	// MaxLag is a replication lag after which replica is considered unhealthy. Zero disables the check.
+	MaxLag time.Duration `yaml:"max_lag"`
+}
+
+type Invalidation struct {
+	Enabled bool              `yaml:"enabled"`
+	Backend string            `yaml:"backend" validate:"omitempty,oneof=postgres kafka"`
+	Channel string            `yaml:"channel"`
+	Kafka   InvalidationKafka `yaml:"kafka"`
+	// RetryBackoff is a delay between resubscription attempts after the channel failure.
+	RetryBackoff time.Duration `yaml:"retry_backoff"`
+}
+
+type InvalidationKafka struct {
+	Servers string `yaml:"servers"`
+	Topic   string `yaml:"topic"`
+}
+
+// Retention configures maintenance of monthly order partitions.
+// Upcoming partitions are always created, while old ones are detached only if Enabled is set.
+type Retention struct {
+	Interval      time.Duration `yaml:"interval" validate:"required"`
+	PremakeMonths int           `yaml:"premake_months" validate:"gte=1"`
+	Enabled       bool          `yaml:"enabled"`
+	// MaxAge is an age after which the whole month partition is detached.
+	MaxAge time.Duration `yaml:"max_age"`
+	// Mode is either detach (partition is moved to the archive schema) or drop.
+	Mode string `yaml:"mode" validate:"omitempty,oneof=detach drop"`
+}
+
+// Archive configures the archive command.
+type Archive struct {
+	OlderThan time.Duration `yaml:"older_than"`
+	BatchSize int           `yaml:"batch_size"`
+	// Target is either file:///dir or s3://bucket/prefix.
+	Target string    `yaml:"target"`
+	S3     ArchiveS3 `yaml:"s3"`
+}
+
+type ArchiveS3 struct {
+	Endpoint  string `yaml:"endpoint"`
+	AccessKey string `yaml:"access_key"`
+	SecretKey string `yaml:"secret_key"`
+	Region    string `yaml:"region"`
+	UseSSL    bool   `yaml:"use_ssl"`
 }
 
 type Config struct {
@@ -41,4 +241,11 @@ type Config struct {
 	Postgres      Postgres      `yaml:"postgres" validate:"required"`
 	API           API           `yaml:"api" validate:"required"`
 	Prefill       Prefill       `yaml:"prefill" validate:"required"`
+	Invalidation  Invalidation  `yaml:"invalidation"`
+	Retention     Retention     `yaml:"retention" validate:"required"`
+	Archive       Archive       `yaml:"archive"`
+	Redaction     Redaction     `yaml:"redaction"`
+	Encryption    Encryption    `yaml:"encryption"`
+	GRPC          GRPC          `yaml:"grpc"`
+	Broadcast     Broadcast     `yaml:"broadcast"`
 }
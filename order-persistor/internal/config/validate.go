@@ -2,6 +2,9 @@ package config
 
 import (
 	"errors"
+	"fmt"
+	"regexp"
+	"strings"
 
 	"github.com/go-playground/validator/v10"
 )
@@ -11,10 +14,89 @@ func validate(cfg *Config) error {
 		return err
 	}
 
+	if err := validateKafkaConsumer(&cfg.KafkaConsumer); err != nil {
+		return err
+	}
+
+	if cfg.API.WriteTimeout != 0 && cfg.API.WriteTimeout <= cfg.API.Timeout {
+		return errors.New("api write_timeout should be greater than timeout, so timed out requests get a response")
+	}
+
+	if err := validateAuth(&cfg.API.Auth); err != nil {
+		return err
+	}
+
+	// the data subject endpoints are served with auth only, and their audit hashes subjects with the key
+	if cfg.API.Auth.Enabled && cfg.Redaction.HashKey == "" {
+		return errors.New("redaction hash_key is required if auth is enabled, since it keys the gdpr audit")
+	}
+
+	if err := validateRateLimit(&cfg.API.RateLimit); err != nil {
+		return err
+	}
+
 	if err := validatePrefill(&cfg.Prefill); err != nil {
 		return err
 	}
 
+	if err := validateInvalidation(&cfg.Invalidation); err != nil {
+		return err
+	}
+
+	if err := validateReplica(&cfg.Postgres.Replica); err != nil {
+		return err
+	}
+
+	if err := validateRetention(&cfg.Retention); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func validateKafkaConsumer(c *KafkaConsumer) error {
+	for _, t := range c.Topics {
+		if !strings.HasPrefix(t.Name, "^") {
+			continue
+		}
+
+		if _, err := regexp.Compile(t.Name); err != nil {
+			return fmt.Errorf("invalid kafka topic pattern %q: %w", t.Name, err)
+		}
+	}
+
+	return nil
+}
+
+func validateAuth(a *Auth) error {
+	if !a.Enabled {
+		return nil
+	}
+
+	if len(a.APIKeys) == 0 && a.JWT.JWKSFile == "" && a.JWT.JWKSURL == "" {
+		return errors.New("either api keys or jwks are required if auth is enabled")
+	}
+
+	if a.JWT.JWKSFile != "" && a.JWT.JWKSURL != "" {
+		return errors.New("only one of jwks file and jwks url can be set")
+	}
+
+	return nil
+}
+
+func validateRateLimit(l *RateLimit) error {
+	if !l.Enabled {
+		return nil
+	}
+
+	if l.Rate <= 0 || l.Burst <= 0 {
+		return errors.New("rate limit rate and burst should be > 0 if rate limit is enabled")
+	}
+
+	if l.UncachedRate > 0 && l.UncachedBurst <= 0 {
+		return errors.New("rate limit uncached_burst should be > 0 if uncached_rate is set")
+	}
+
 	return nil
 }
 
@@ -23,5 +105,58 @@ func validatePrefill(p *Prefill) error {
 		return errors.New("prefill timeout should be >= 0 if prefill is enabled")
 	}
 
+	if p.Enabled && p.PageSize <= 0 {
+		return errors.New("prefill page size should be > 0 if prefill is enabled")
+	}
+
+	return nil
+}
+
+func validateInvalidation(i *Invalidation) error {
+	if !i.Enabled {
+		return nil
+	}
+
+	if i.RetryBackoff <= 0 {
+		return errors.New("invalidation retry backoff should be > 0 if invalidation is enabled")
+	}
+
+	switch i.Backend {
+	case "postgres":
+		if i.Channel == "" {
+			return errors.New("invalidation channel is required for postgres backend")
+		}
+	case "kafka":
+		if i.Kafka.Servers == "" || i.Kafka.Topic == "" {
+			return errors.New("invalidation kafka servers and topic are required for kafka backend")
+		}
+	default:
+		return errors.New("invalidation backend should be either postgres or kafka")
+	}
+
+	return nil
+}
+
+func validateReplica(r *PostgresReplica) error {
+	if r.ConnString == "" {
+		return nil
+	}
+
+	if r.HealthCheckInterval <= 0 {
+		return errors.New("replica health check interval should be > 0 if replica is configured")
+	}
+
+	if r.MaxLag < 0 {
+		return errors.New("replica max lag should be >= 0")
+	}
+
+	return nil
+}
+
+func validateRetention(r *Retention) error {
+	if r.Enabled && (r.MaxAge <= 0 || r.Mode == "") {
+		return errors.New("retention max age and mode are required if retention is enabled")
+	}
+
 	return nil
 }
@@ -0,0 +1,137 @@
+// Package envelope implements envelope encryption of separate values: each record is encrypted
+// with its own random data key, which is stored next to the record encrypted by a key encryption key.
+package envelope
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// prefix marks encrypted values, so that values written before encryption was enabled are told apart.
+const prefix = "enc:v1:"
+
+var ErrUnknownKey = errors.New("unknown key")
+
+// Sealed is a record encrypted with a data key.
+type Sealed struct {
+	// KeyID is the key encryption key the data key is encrypted with.
+	KeyID string
+	// DataKey is the encrypted data key.
+	DataKey []byte
+	Values  []string
+}
+
+type Cipher struct {
+	keys     KeyProvider
+	indexKey []byte
+}
+
+// NewCipher creates a cipher encrypting data keys with the provider and computing blind indexes with indexKey.
+func NewCipher(keys KeyProvider, indexKey []byte) *Cipher {
+	return &Cipher{keys: keys, indexKey: indexKey}
+}
+
+// CurrentKeyID returns the key encryption key of the new records.
+func (c *Cipher) CurrentKeyID() string {
+	return c.keys.CurrentKeyID()
+}
+
+// Encrypt encrypts the values of a record with a new data key. Each value is bound to the record
+// and to its position by record, so that encrypted values could not be swapped between records or fields.
+// Empty values are left empty.
+func (c *Cipher) Encrypt(ctx context.Context, record string, values ...string) (*Sealed, error) {
+	dataKey := make([]byte, keySize)
+	if _, err := rand.Read(dataKey); err != nil {
+		return nil, err
+	}
+
+	keyID := c.keys.CurrentKeyID()
+	encryptedKey, err := c.keys.EncryptKey(ctx, keyID, dataKey)
+	if err != nil {
+		return nil, fmt.Errorf("could not encrypt data key: %w", err)
+	}
+
+	aead, err := newAEAD(dataKey)
+	if err != nil {
+		return nil, err
+	}
+
+	sealed := &Sealed{
+		KeyID:   keyID,
+		DataKey: encryptedKey,
+		Values:  make([]string, len(values)),
+	}
+
+	for i, v := range values {
+		if v == "" {
+			continue
+		}
+
+		ciphertext, err := seal(aead, []byte(v), additionalData(record, i))
+		if err != nil {
+			return nil, err
+		}
+
+		sealed.Values[i] = prefix + base64.RawStdEncoding.EncodeToString(ciphertext)
+	}
+
+	return sealed, nil
+}
+
+// Decrypt decrypts the values of a record in place. Values without the encryption prefix are left intact.
+func (c *Cipher) Decrypt(ctx context.Context, record string, keyID string, encryptedKey []byte, values ...*string) error {
+	dataKey, err := c.keys.DecryptKey(ctx, keyID, encryptedKey)
+	if err != nil {
+		return fmt.Errorf("could not decrypt data key: %w", err)
+	}
+
+	aead, err := newAEAD(dataKey)
+	if err != nil {
+		return err
+	}
+
+	for i, v := range values {
+		encoded, ok := strings.CutPrefix(*v, prefix)
+		if !ok {
+			continue
+		}
+
+		ciphertext, err := base64.RawStdEncoding.DecodeString(encoded)
+		if err != nil {
+			return fmt.Errorf("value %d: %w", i, err)
+		}
+
+		plaintext, err := open(aead, ciphertext, additionalData(record, i))
+		if err != nil {
+			return fmt.Errorf("value %d: %w", i, err)
+		}
+
+		*v = string(plaintext)
+	}
+
+	return nil
+}
+
+// BlindIndex returns a keyed digest of the value, allowing exact-match search without decryption.
+// Values are compared case-insensitively.
+func (c *Cipher) BlindIndex(v string) string {
+	if v == "" {
+		return ""
+	}
+
+	mac := hmac.New(sha256.New, c.indexKey)
+	mac.Write([]byte(strings.ToLower(strings.TrimSpace(v))))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func additionalData(record string, i int) []byte {
+	return []byte(record + "\x00" + strconv.Itoa(i))
+}
@@ -0,0 +1,141 @@
+package envelope
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func randomKey(t *testing.T) string {
+	t.Helper()
+
+	key := make([]byte, keySize)
+	if _, err := rand.Read(key); err != nil {
+		t.Fatal(err)
+	}
+
+	return base64.StdEncoding.EncodeToString(key)
+}
+
+func writeKeyFile(t *testing.T, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "keys.yaml")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	return path
+}
+
+func TestCipher(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	oldKey, newKey, indexKey := randomKey(t), randomKey(t), randomKey(t)
+
+	before, err := LoadKeyFile(writeKeyFile(t, "current: k1\nkeys:\n  k1: "+oldKey+"\nindex_key: "+indexKey+"\n"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	rotated, err := LoadKeyFile(writeKeyFile(t, "current: k2\nkeys:\n  k1: "+oldKey+"\n  k2: "+newKey+"\nindex_key: "+indexKey+"\n"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	c := NewCipher(before, before.IndexKey())
+	sealed, err := c.Encrypt(ctx, "order-1", "Test Testov", "", "test@gmail.com")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if sealed.KeyID != "k1" || sealed.Values[1] != "" || strings.Contains(sealed.Values[2], "test") {
+		t.Fatalf("unexpected sealed record: %+v", sealed)
+	}
+
+	t.Run("decrypts with rotated keys", func(t *testing.T) {
+		t.Parallel()
+
+		values := append([]string(nil), sealed.Values...)
+		err := NewCipher(rotated, rotated.IndexKey()).Decrypt(ctx, "order-1", sealed.KeyID, sealed.DataKey, &values[0], &values[1], &values[2])
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if values[0] != "Test Testov" || values[1] != "" || values[2] != "test@gmail.com" {
+			t.Fatalf("unexpected values: %v", values)
+		}
+	})
+
+	t.Run("rejects values of another record", func(t *testing.T) {
+		t.Parallel()
+
+		value := sealed.Values[0]
+		if err := c.Decrypt(ctx, "order-2", sealed.KeyID, sealed.DataKey, &value); err == nil {
+			t.Fatal("expected error")
+		}
+	})
+
+	t.Run("leaves plain values intact", func(t *testing.T) {
+		t.Parallel()
+
+		value := "Test Testov"
+		if err := c.Decrypt(ctx, "order-1", sealed.KeyID, sealed.DataKey, &value); err != nil || value != "Test Testov" {
+			t.Fatalf("unexpected result: %q, %v", value, err)
+		}
+	})
+
+	t.Run("fails with removed key", func(t *testing.T) {
+		t.Parallel()
+
+		other, err := LoadKeyFile(writeKeyFile(t, "current: k2\nkeys:\n  k2: "+newKey+"\nindex_key: "+indexKey+"\n"))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		value := sealed.Values[0]
+		err = NewCipher(other, other.IndexKey()).Decrypt(ctx, "order-1", sealed.KeyID, sealed.DataKey, &value)
+		if !errors.Is(err, ErrUnknownKey) {
+			t.Fatalf("expected unknown key error, got %v", err)
+		}
+	})
+
+	t.Run("blind index ignores case and does not depend on key rotation", func(t *testing.T) {
+		t.Parallel()
+
+		index := c.BlindIndex("test@gmail.com")
+		if index != NewCipher(rotated, rotated.IndexKey()).BlindIndex(" Test@Gmail.com") {
+			t.Fatal("expected equal indexes")
+		}
+
+		if index == c.BlindIndex("test2@gmail.com") {
+			t.Fatal("expected different indexes")
+		}
+	})
+}
+
+func TestLoadKeyFile(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]string{
+		"missing current key": "current: k2\nkeys:\n  k1: " + randomKey(t) + "\nindex_key: " + randomKey(t) + "\n",
+		"short key":           "current: k1\nkeys:\n  k1: " + base64.StdEncoding.EncodeToString([]byte("short")) + "\nindex_key: " + randomKey(t) + "\n",
+		"missing index key":   "current: k1\nkeys:\n  k1: " + randomKey(t) + "\n",
+	}
+
+	for name, content := range cases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			if _, err := LoadKeyFile(writeKeyFile(t, content)); err == nil {
+				t.Fatal("expected error")
+			}
+		})
+	}
+}
@@ -0,0 +1,159 @@
+package envelope
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+const keySize = 32
+
+// KeyProvider encrypts data keys with key encryption keys, the way KMS services do.
+// Key encryption keys never leave the provider, so it may be backed by a remote KMS as well as by a local key file.
+type KeyProvider interface {
+	// CurrentKeyID returns the key new data keys are encrypted with.
+	CurrentKeyID() string
+	EncryptKey(ctx context.Context, keyID string, dataKey []byte) ([]byte, error)
+	DecryptKey(ctx context.Context, keyID string, encrypted []byte) ([]byte, error)
+}
+
+// KeyFile is a KeyProvider of the keys stored in a local YAML file:
+//
+//	current: "2026-10"
+//	keys:
+//	  "2026-10": <base64 of 32 random bytes>
+//	  "2026-01": <base64 of 32 random bytes>
+//	index_key: <base64 of 32 random bytes>
+//
+// Keys are rotated by adding a new key and making it current. Previous keys are kept
+// to decrypt the data until it is re-encrypted.
+type KeyFile struct {
+	current  string
+	keys     map[string]cipher.AEAD
+	indexKey []byte
+}
+
+var _ KeyProvider = &KeyFile{}
+
+type keyFileContent struct {
+	Current  string            `yaml:"current"`
+	Keys     map[string]string `yaml:"keys"`
+	IndexKey string            `yaml:"index_key"`
+}
+
+// LoadKeyFile reads the key file at path.
+func LoadKeyFile(path string) (*KeyFile, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not read key file: %w", err)
+	}
+
+	var content keyFileContent
+	if err := yaml.Unmarshal(raw, &content); err != nil {
+		return nil, fmt.Errorf("could not parse key file: %w", err)
+	}
+
+	if _, ok := content.Keys[content.Current]; !ok {
+		return nil, fmt.Errorf("current key %q is missing in key file", content.Current)
+	}
+
+	kf := &KeyFile{
+		current: content.Current,
+		keys:    make(map[string]cipher.AEAD, len(content.Keys)),
+	}
+
+	for id, encoded := range content.Keys {
+		key, err := decodeKey(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("key %q: %w", id, err)
+		}
+
+		kf.keys[id], err = newAEAD(key)
+		if err != nil {
+			return nil, fmt.Errorf("key %q: %w", id, err)
+		}
+	}
+
+	kf.indexKey, err = decodeKey(content.IndexKey)
+	if err != nil {
+		return nil, fmt.Errorf("index key: %w", err)
+	}
+
+	return kf, nil
+}
+
+func (kf *KeyFile) CurrentKeyID() string {
+	return kf.current
+}
+
+// IndexKey returns the key of blind indexes. Unlike the key encryption keys it can not be rotated
+// without recomputing all the indexes, see `reencrypt --all`.
+func (kf *KeyFile) IndexKey() []byte {
+	return kf.indexKey
+}
+
+func (kf *KeyFile) EncryptKey(_ context.Context, keyID string, dataKey []byte) ([]byte, error) {
+	aead, ok := kf.keys[keyID]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownKey, keyID)
+	}
+
+	return seal(aead, dataKey, []byte(keyID))
+}
+
+func (kf *KeyFile) DecryptKey(_ context.Context, keyID string, encrypted []byte) ([]byte, error) {
+	aead, ok := kf.keys[keyID]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownKey, keyID)
+	}
+
+	return open(aead, encrypted, []byte(keyID))
+}
+
+func decodeKey(encoded string) ([]byte, error) {
+	key, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(key) != keySize {
+		return nil, fmt.Errorf("key should be %d bytes, got %d", keySize, len(key))
+	}
+
+	return key, nil
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+// seal encrypts plaintext with a random nonce, which is prepended to the result.
+func seal(aead cipher.AEAD, plaintext, additional []byte) ([]byte, error) {
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(plaintext)+aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	return aead.Seal(nonce, nonce, plaintext, additional), nil
+}
+
+func open(aead cipher.AEAD, ciphertext, additional []byte) ([]byte, error) {
+	if len(ciphertext) < aead.NonceSize() {
+		return nil, errors.New("ciphertext is too short")
+	}
+
+	nonce, ciphertext := ciphertext[:aead.NonceSize()], ciphertext[aead.NonceSize():]
+	return aead.Open(nil, nonce, ciphertext, additional)
+}
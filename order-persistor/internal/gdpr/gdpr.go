@@ -0,0 +1,159 @@
+// Package gdpr handles requests of data subjects: export of their orders and erasure of their personal data.
+package gdpr
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"order-persistor/internal/orders"
+	"strings"
+)
+
+// Audit actions.
+const (
+	ActionExport = "export"
+	ActionErase  = "erase"
+)
+
+// maxOrders limits the orders of a single subject, which is far beyond what a real customer has.
+const maxOrders = 100_000
+
+var ErrInvalidSubject = errors.New("exactly one of customer_id and email is required")
+
+// Subject identifies the customer by either customer ID or delivery email.
+type Subject struct {
+	CustomerID string `json:"customer_id,omitempty"`
+	Email      string `json:"email,omitempty"`
+} // @name gdpr.Subject
+
+func (s Subject) Validate() error {
+	if (s.CustomerID == "") == (s.Email == "") {
+		return ErrInvalidSubject
+	}
+
+	return nil
+}
+
+// AuditEntry records an export or erasure. The subject is hashed with a secret key, so the audit does not keep
+// the erased data and the subject can not be recovered from the hash by brute force.
+type AuditEntry struct {
+	Action      string
+	SubjectType string
+	SubjectHash string
+	// Actor is the authenticated caller who made the request.
+	Actor    string
+	OrderIDs []string
+}
+
+type Repository interface {
+	ListByCustomerID(ctx context.Context, customerID string, n int) ([]orders.Order, error)
+	ListByEmail(ctx context.Context, email string, n int) ([]orders.Order, error)
+	// Anonymize erases delivery personal data of the orders and records the entry in the same transaction.
+	Anonymize(ctx context.Context, ids []string, entry AuditEntry) (int, error)
+	RecordAudit(ctx context.Context, entry AuditEntry) error
+}
+
+type Evictor interface {
+	Evict(id string)
+}
+
+// Forgetter drops orders from the events kept for replay to the stream subscribers.
+type Forgetter interface {
+	Forget(ids ...string)
+}
+
+type Service struct {
+	repository Repository
+	cache      Evictor
+	replay     Forgetter
+	hashKey    []byte
+	logger     *slog.Logger
+}
+
+// NewService creates a service evicting erased orders from the cache and the replayed events.
+// The hash key keys the subject hashes of the audit.
+func NewService(repository Repository, cache Evictor, replay Forgetter, hashKey string, logger *slog.Logger) *Service {
+	return &Service{
+		repository: repository,
+		cache:      cache,
+		replay:     replay,
+		hashKey:    []byte(hashKey),
+		logger:     logger,
+	}
+}
+
+// Export returns all the orders of the subject, recording the export in the audit.
+func (s *Service) Export(ctx context.Context, subject Subject, actor string) ([]orders.Order, error) {
+	found, err := s.find(ctx, subject)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repository.RecordAudit(ctx, s.newAuditEntry(ActionExport, subject, actor, found)); err != nil {
+		return nil, fmt.Errorf("recording audit: %w", err)
+	}
+
+	return found, nil
+}
+
+// Erase anonymizes delivery data of all the orders of the subject, keeping the financial records,
+// and evicts them from the cache and the replayed events. It returns IDs of the anonymized orders.
+func (s *Service) Erase(ctx context.Context, subject Subject, actor string) ([]string, error) {
+	found, err := s.find(ctx, subject)
+	if err != nil {
+		return nil, err
+	}
+
+	entry := s.newAuditEntry(ActionErase, subject, actor, found)
+	if _, err := s.repository.Anonymize(ctx, entry.OrderIDs, entry); err != nil {
+		return nil, fmt.Errorf("anonymizing orders: %w", err)
+	}
+
+	for _, id := range entry.OrderIDs {
+		s.cache.Evict(id)
+	}
+
+	s.replay.Forget(entry.OrderIDs...)
+
+	s.logger.InfoContext(ctx, "gdpr: erased personal data", "orders", len(entry.OrderIDs), "actor", actor, "subject_hash", entry.SubjectHash)
+	return entry.OrderIDs, nil
+}
+
+func (s *Service) find(ctx context.Context, subject Subject) ([]orders.Order, error) {
+	if err := subject.Validate(); err != nil {
+		return nil, err
+	}
+
+	if subject.CustomerID != "" {
+		return s.repository.ListByCustomerID(ctx, subject.CustomerID, maxOrders)
+	}
+
+	return s.repository.ListByEmail(ctx, subject.Email, maxOrders)
+}
+
+func (s *Service) newAuditEntry(action string, subject Subject, actor string, found []orders.Order) AuditEntry {
+	subjectType, value := "customer_id", subject.CustomerID
+	if subject.Email != "" {
+		subjectType, value = "email", strings.ToLower(strings.TrimSpace(subject.Email))
+	}
+
+	mac := hmac.New(sha256.New, s.hashKey)
+	mac.Write([]byte(value))
+
+	ids := make([]string, 0, len(found))
+	for _, o := range found {
+		ids = append(ids, o.ID)
+	}
+
+	return AuditEntry{
+		Action:      action,
+		SubjectType: subjectType,
+		SubjectHash: hex.EncodeToString(mac.Sum(nil)),
+		Actor:       actor,
+		OrderIDs:    ids,
+	}
+}
@@ -0,0 +1,98 @@
+package gdpr_test
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"order-persistor/internal/gdpr"
+	"order-persistor/internal/mocks"
+	"order-persistor/internal/orders"
+	"testing"
+
+	"go.uber.org/mock/gomock"
+
+	"github.com/lezzercringe/some-assignment/orderschema"
+)
+
+func TestService(t *testing.T) {
+	t.Parallel()
+
+	const hashKey = "secret"
+
+	log := slog.New(slog.DiscardHandler)
+	found := []orders.Order{{Order: orderschema.Order{ID: "first"}}, {Order: orderschema.Order{ID: "second"}}}
+
+	t.Run("erase anonymizes and evicts the orders", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		rep := mocks.NewMockGDPRRepository(ctrl)
+		cache := mocks.NewMockGDPREvictor(ctrl)
+		replay := mocks.NewMockGDPRForgetter(ctrl)
+
+		rep.EXPECT().ListByEmail(gomock.Any(), "Test@Gmail.com", gomock.Any()).Return(found, nil)
+		rep.EXPECT().
+			Anonymize(gomock.Any(), []string{"first", "second"}, gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ []string, entry gdpr.AuditEntry) (int, error) {
+				// HMAC of the normalized email, so erasures of the same email are correlated in the audit
+				const hash = "f816c5ca6d01f2949af51978cfd26d74c69436de4d41dd864a24ab58a3962027"
+				if entry.Action != gdpr.ActionErase || entry.SubjectType != "email" || entry.SubjectHash != hash || entry.Actor != "support" {
+					t.Errorf("unexpected audit entry: %+v", entry)
+				}
+
+				return 2, nil
+			})
+		cache.EXPECT().Evict("first")
+		cache.EXPECT().Evict("second")
+		replay.EXPECT().Forget("first", "second")
+
+		ids, err := gdpr.NewService(rep, cache, replay, hashKey, log).Erase(context.Background(), gdpr.Subject{Email: "Test@Gmail.com"}, "support")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if len(ids) != 2 {
+			t.Fatalf("unexpected ids: %v", ids)
+		}
+	})
+
+	t.Run("failed erase neither evicts nor forgets", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		rep := mocks.NewMockGDPRRepository(ctrl)
+		cache := mocks.NewMockGDPREvictor(ctrl)
+		replay := mocks.NewMockGDPRForgetter(ctrl)
+
+		rep.EXPECT().ListByCustomerID(gomock.Any(), "test", gomock.Any()).Return(found, nil)
+		rep.EXPECT().Anonymize(gomock.Any(), gomock.Any(), gomock.Any()).Return(0, orders.ErrInternalFailure)
+
+		_, err := gdpr.NewService(rep, cache, replay, hashKey, log).Erase(context.Background(), gdpr.Subject{CustomerID: "test"}, "support")
+		if !errors.Is(err, orders.ErrInternalFailure) {
+			t.Fatalf("expected internal failure, got: %v", err)
+		}
+	})
+
+	t.Run("export is audited", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		rep := mocks.NewMockGDPRRepository(ctrl)
+
+		rep.EXPECT().ListByCustomerID(gomock.Any(), "test", gomock.Any()).Return(found, nil)
+		rep.EXPECT().RecordAudit(gomock.Any(), gomock.Any()).Return(nil)
+
+		exported, err := gdpr.NewService(rep, nil, nil, hashKey, log).Export(context.Background(), gdpr.Subject{CustomerID: "test"}, "support")
+		if err != nil || len(exported) != 2 {
+			t.Fatalf("unexpected result: %v, %v", exported, err)
+		}
+	})
+
+	t.Run("subject requires exactly one identifier", func(t *testing.T) {
+		for _, s := range []gdpr.Subject{{}, {CustomerID: "test", Email: "test@gmail.com"}} {
+			if _, err := gdpr.NewService(nil, nil, nil, hashKey, log).Export(context.Background(), s, "support"); !errors.Is(err, gdpr.ErrInvalidSubject) {
+				t.Fatalf("expected invalid subject error for %+v, got: %v", s, err)
+			}
+		}
+	})
+}
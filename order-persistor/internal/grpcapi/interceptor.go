@@ -0,0 +1,143 @@
+package grpcapi
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"order-persistor/internal/api"
+	"order-persistor/internal/grpcapi/ordersv1"
+	"order-persistor/internal/orders"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// tenantMetadata is the metadata with the tenant whose orders are read, like the tenant header of the REST API.
+const tenantMetadata = "x-tenant"
+
+// credentialMetadata are passed to the authenticator as request headers.
+var credentialMetadata = []string{"authorization", "x-api-key"}
+
+type principalKey struct{}
+
+func principalFrom(ctx context.Context) *api.Principal {
+	p, _ := ctx.Value(principalKey{}).(*api.Principal)
+	return p
+}
+
+// ordersMethods prefixes the methods of the orders service. Health and reflection are not authenticated.
+var ordersMethods = "/" + ordersv1.OrdersService_ServiceDesc.ServiceName + "/"
+
+// interceptor authenticates and logs calls of the orders service, limiting their reads to the tenant.
+type interceptor struct {
+	auth   api.Authenticator
+	logger *slog.Logger
+}
+
+func (i *interceptor) unary(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	if !strings.HasPrefix(info.FullMethod, ordersMethods) {
+		return handler(ctx, req)
+	}
+
+	start := time.Now()
+
+	ctx, err := i.authorize(ctx)
+	var resp any
+	if err == nil {
+		resp, err = handler(ctx, req)
+	}
+
+	i.log(ctx, info.FullMethod, start, err)
+	return resp, err
+}
+
+func (i *interceptor) stream(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if !strings.HasPrefix(info.FullMethod, ordersMethods) {
+		return handler(srv, ss)
+	}
+
+	start := time.Now()
+
+	ctx, err := i.authorize(ss.Context())
+	if err == nil {
+		err = handler(srv, &contextStream{ServerStream: ss, ctx: ctx})
+	}
+
+	i.log(ctx, info.FullMethod, start, err)
+	return err
+}
+
+// authorize authenticates the caller the same way the REST API does and limits the reads to the tenant.
+func (i *interceptor) authorize(ctx context.Context) (context.Context, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	p := api.Anonymous
+	if i.auth != nil {
+		r := &http.Request{Header: http.Header{}}
+		for _, key := range credentialMetadata {
+			if v := md.Get(key); len(v) > 0 {
+				r.Header.Set(key, v[0])
+			}
+		}
+
+		var err error
+		p, err = i.auth.Authenticate(r)
+		if err != nil {
+			i.logger.WarnContext(ctx, "gRPC authentication failed", "err", err)
+			return ctx, status.Error(codes.Unauthenticated, "unauthenticated")
+		}
+	}
+
+	if !p.HasScope(api.ScopeRead) {
+		return ctx, status.Errorf(codes.PermissionDenied, "missing scope %s", api.ScopeRead)
+	}
+
+	tenant := orders.DefaultTenant
+	if v := md.Get(tenantMetadata); len(v) > 0 && v[0] != "" {
+		tenant = v[0]
+	}
+
+	if p.Tenant != "" {
+		if len(md.Get(tenantMetadata)) > 0 && tenant != p.Tenant {
+			return ctx, status.Errorf(codes.PermissionDenied, "tenant %s is not allowed", tenant)
+		}
+
+		tenant = p.Tenant
+	}
+
+	ctx = context.WithValue(ctx, principalKey{}, p)
+	return orders.WithTenant(ctx, tenant), nil
+}
+
+func (i *interceptor) log(ctx context.Context, method string, start time.Time, err error) {
+	fields := []any{
+		"method", method,
+		"code", status.Code(err).String(),
+		"duration", time.Since(start).String(),
+	}
+
+	if p := principalFrom(ctx); p != nil {
+		fields = append(fields, "principal", p.Name)
+	}
+
+	switch status.Code(err) {
+	case codes.OK, codes.NotFound, codes.Canceled:
+		i.logger.InfoContext(ctx, "gRPC", fields...)
+	default:
+		i.logger.ErrorContext(ctx, "gRPC", append(fields, "err", err)...)
+	}
+}
+
+// contextStream replaces the context of the stream.
+type contextStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *contextStream) Context() context.Context {
+	return s.ctx
+}
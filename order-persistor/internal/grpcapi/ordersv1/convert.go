@@ -0,0 +1,67 @@
+package ordersv1
+
+import (
+	"order-persistor/internal/orders"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// FromOrder converts the order to its protobuf message.
+func FromOrder(o *orders.Order) *Order {
+	res := &Order{
+		OrderUid:          o.ID,
+		TrackNumber:       o.TrackNumber,
+		Entry:             o.Entry,
+		Locale:            o.Locale,
+		InternalSignature: o.Signature,
+		CustomerId:        o.CustomerID,
+		DeliveryService:   o.DeliveryService,
+		Shardkey:          o.ShardKey,
+		SmId:              int64(o.SMID),
+		DateCreated:       timestamppb.New(o.CreatedAt),
+		OofShard:          o.OOFShard,
+		Delivery: &Delivery{
+			Name:    o.Delivery.Name,
+			Phone:   o.Delivery.Phone,
+			Zip:     o.Delivery.Zip,
+			City:    o.Delivery.City,
+			Address: o.Delivery.Address,
+			Region:  o.Delivery.Region,
+			Email:   o.Delivery.Email,
+		},
+		Items: make([]*Item, 0, len(o.Items)),
+	}
+
+	if p := o.Payment; p != nil {
+		res.Payment = &Payment{
+			Transaction:  p.Transaction,
+			RequestId:    p.RequestID,
+			Currency:     p.Currency,
+			Provider:     p.Provider,
+			PaymentDt:    p.PaymentDT,
+			Bank:         p.Bank,
+			GoodsTotal:   int64(p.GoodsTotal),
+			Amount:       p.Amount.String(),
+			DeliveryCost: p.DeliveryCost.String(),
+			CustomFee:    p.CustomFee.String(),
+		}
+	}
+
+	for _, item := range o.Items {
+		res.Items = append(res.Items, &Item{
+			ChrtId:      int64(item.CHRTID),
+			TrackNumber: item.TrackNumber,
+			Rid:         item.RID,
+			Name:        item.Name,
+			Size:        item.Size,
+			NmId:        int64(item.NMID),
+			Brand:       item.Brand,
+			Status:      int64(item.Status),
+			Price:       item.Price.String(),
+			Sale:        item.Sale.String(),
+			TotalPrice:  item.TotalPrice.String(),
+		})
+	}
+
+	return res
+}
@@ -0,0 +1,105 @@
+// Package grpcapi serves orders over gRPC, see proto/orders/v1/orders.proto.
+package grpcapi
+
+import (
+	"context"
+	"crypto/tls"
+	"log/slog"
+	"net"
+	"order-persistor/internal/api"
+	"order-persistor/internal/broadcast"
+	"order-persistor/internal/config"
+	"order-persistor/internal/grpcapi/ordersv1"
+	"order-persistor/internal/orders"
+	"order-persistor/internal/redact"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+)
+
+type Params struct {
+	Logger           *slog.Logger
+	OrdersRepository orders.Repository
+	// Hub delivers the orders streamed by WatchOrders.
+	Hub *broadcast.Hub
+	// Redaction masks personal data in responses to callers without the PII scope.
+	Redaction *redact.Policy
+	// Authenticator identifies callers by authorization and x-api-key metadata. If nil, calls are not authenticated.
+	Authenticator api.Authenticator
+	// TLSConfig makes the server serve over TLS. If nil, the connections are not encrypted.
+	TLSConfig *tls.Config
+}
+
+type Server struct {
+	addr   string
+	server *grpc.Server
+	health *health.Server
+}
+
+// NewServer creates the gRPC server of the orders service along with health and reflection services.
+func NewServer(cfg config.GRPC, p Params) *Server {
+	service := &ordersService{
+		repository: p.OrdersRepository,
+		hub:        p.Hub,
+		redaction:  p.Redaction,
+		logger:     p.Logger,
+	}
+
+	i := &interceptor{auth: p.Authenticator, logger: p.Logger}
+	opts := []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(i.unary),
+		grpc.ChainStreamInterceptor(i.stream),
+	}
+
+	if p.TLSConfig != nil {
+		opts = append(opts, grpc.Creds(credentials.NewTLS(p.TLSConfig)))
+	}
+
+	s := &Server{
+		addr:   net.JoinHostPort(cfg.Host, cfg.Port),
+		server: grpc.NewServer(opts...),
+		health: health.NewServer(),
+	}
+
+	ordersv1.RegisterOrdersServiceServer(s.server, service)
+	healthpb.RegisterHealthServer(s.server, s.health)
+	reflection.Register(s.server)
+
+	s.health.SetServingStatus(ordersv1.OrdersService_ServiceDesc.ServiceName, healthpb.HealthCheckResponse_SERVING)
+	return s
+}
+
+// ListenAndServe serves the configured address, blocking until the server is stopped.
+func (s *Server) ListenAndServe() error {
+	ln, err := net.Listen("tcp", s.addr)
+	if err != nil {
+		return err
+	}
+
+	return s.Serve(ln)
+}
+
+func (s *Server) Serve(ln net.Listener) error {
+	return s.server.Serve(ln)
+}
+
+// Shutdown reports the service as not serving and waits for the running calls to finish until ctx is done.
+// Watch streams are ended along with the contexts of their calls.
+func (s *Server) Shutdown(ctx context.Context) {
+	s.health.Shutdown()
+
+	stopped := make(chan struct{})
+	go func() {
+		s.server.GracefulStop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+	case <-ctx.Done():
+		s.server.Stop()
+	}
+}
@@ -0,0 +1,240 @@
+package grpcapi
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net"
+	"order-persistor/internal/api"
+	"order-persistor/internal/broadcast"
+	"order-persistor/internal/config"
+	"order-persistor/internal/grpcapi/ordersv1"
+	"order-persistor/internal/mocks"
+	"order-persistor/internal/orders"
+	"order-persistor/internal/redact"
+	"testing"
+	"time"
+
+	"go.uber.org/mock/gomock"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/lezzercringe/some-assignment/orderschema"
+)
+
+const testKey = "0123456789abcdef-support"
+
+func newTestClient(t *testing.T, p Params) ordersv1.OrdersServiceClient {
+	t.Helper()
+
+	if p.Logger == nil {
+		p.Logger = slog.New(slog.DiscardHandler)
+	}
+
+	if p.Hub == nil {
+		p.Hub = broadcast.NewHub(0, 0)
+	}
+
+	if p.Redaction == nil {
+		p.Redaction, _ = redact.NewPolicy(redact.Remove, "")
+	}
+
+	srv := NewServer(config.GRPC{}, p)
+	ln := bufconn.Listen(1 << 20)
+	go srv.Serve(ln)
+	t.Cleanup(func() {
+		srv.Shutdown(context.Background())
+	})
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return ln.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	healthResp, err := healthpb.NewHealthClient(conn).Check(context.Background(), &healthpb.HealthCheckRequest{
+		Service: ordersv1.OrdersService_ServiceDesc.ServiceName,
+	})
+	if err != nil || healthResp.GetStatus() != healthpb.HealthCheckResponse_SERVING {
+		t.Fatalf("expected orders service to be serving, got %v, %v", healthResp, err)
+	}
+
+	return ordersv1.NewOrdersServiceClient(conn)
+}
+
+func TestGetOrder(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	repo := mocks.NewMockRepository(ctrl)
+
+	order := &orders.Order{Order: orderschema.Order{ID: "b563feb7b2b84b6test", CustomerID: "test"}}
+	order.Delivery.Phone = "+9720000000"
+
+	repo.EXPECT().GetByID(gomock.Any(), order.ID).Return(order, nil)
+	repo.EXPECT().GetByID(gomock.Any(), "missing").Return(nil, orders.ErrNotFound)
+
+	client := newTestClient(t, Params{OrdersRepository: repo})
+
+	resp, err := client.GetOrder(context.Background(), &ordersv1.GetOrderRequest{OrderUid: order.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if resp.GetOrder().GetOrderUid() != order.ID {
+		t.Errorf("unexpected order %v", resp.GetOrder())
+	}
+
+	_, err = client.GetOrder(context.Background(), &ordersv1.GetOrderRequest{OrderUid: "missing"})
+	if status.Code(err) != codes.NotFound {
+		t.Errorf("expected NotFound, got %v", err)
+	}
+}
+
+func TestGetOrder_auth(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	repo := mocks.NewMockRepository(ctrl)
+
+	order := &orders.Order{Order: orderschema.Order{ID: "b563feb7b2b84b6test", CustomerID: "test"}, Tenant: "acme"}
+	order.Delivery.Phone = "+9720000000"
+
+	repo.EXPECT().GetByID(gomock.Any(), order.ID).DoAndReturn(func(ctx context.Context, _ string) (*orders.Order, error) {
+		if tenant, _ := orders.TenantFrom(ctx); tenant != "acme" {
+			t.Errorf("expected reads to be limited to the key tenant, got %q", tenant)
+		}
+
+		return order, nil
+	})
+
+	client := newTestClient(t, Params{
+		OrdersRepository: repo,
+		Authenticator: api.NewAPIKeyAuthenticator([]config.APIKey{
+			{Name: "support", Key: testKey, Scopes: []string{api.ScopeRead}, Tenant: "acme"},
+		}),
+	})
+
+	req := &ordersv1.GetOrderRequest{OrderUid: order.ID}
+
+	_, err := client.GetOrder(context.Background(), req)
+	if status.Code(err) != codes.Unauthenticated {
+		t.Errorf("expected Unauthenticated, got %v", err)
+	}
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "x-api-key", testKey)
+
+	_, err = client.GetOrder(metadata.AppendToOutgoingContext(ctx, tenantMetadata, "other"), req)
+	if status.Code(err) != codes.PermissionDenied {
+		t.Errorf("expected PermissionDenied for another tenant, got %v", err)
+	}
+
+	resp, err := client.GetOrder(ctx, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// personal data is masked without the PII scope
+	if resp.GetOrder().GetCustomerId() != "" || resp.GetOrder().GetDelivery().GetPhone() != "" {
+		t.Errorf("expected personal data to be removed, got %v", resp.GetOrder())
+	}
+}
+
+func TestListOrders(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	repo := mocks.NewMockRepository(ctrl)
+
+	createdAt := time.Date(2026, 10, 19, 12, 0, 0, 123, time.UTC)
+	page := []orders.Order{
+		{Order: orderschema.Order{ID: "b", CreatedAt: createdAt.Add(time.Second)}},
+		{Order: orderschema.Order{ID: "a", CreatedAt: createdAt}},
+	}
+
+	repo.EXPECT().ListRecent(gomock.Any(), 2).Return(page, nil)
+	repo.EXPECT().ListBefore(gomock.Any(), orders.Cursor{CreatedAt: createdAt, ID: "a"}, 2).Return(nil, nil)
+
+	client := newTestClient(t, Params{OrdersRepository: repo})
+
+	resp, err := client.ListOrders(context.Background(), &ordersv1.ListOrdersRequest{PageSize: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(resp.GetOrders()) != 2 || resp.GetNextPageToken() == "" {
+		t.Fatalf("expected full page with next page token, got %v", resp)
+	}
+
+	resp, err = client.ListOrders(context.Background(), &ordersv1.ListOrdersRequest{PageSize: 2, PageToken: resp.GetNextPageToken()})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(resp.GetOrders()) != 0 || resp.GetNextPageToken() != "" {
+		t.Errorf("expected empty last page, got %v", resp)
+	}
+
+	_, err = client.ListOrders(context.Background(), &ordersv1.ListOrdersRequest{PageToken: "not a token"})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("expected InvalidArgument, got %v", err)
+	}
+}
+
+func TestWatchOrders(t *testing.T) {
+	t.Parallel()
+
+	hub := broadcast.NewHub(0, 0)
+	client := newTestClient(t, Params{Hub: hub})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	stream, err := client.WatchOrders(metadata.AppendToOutgoingContext(ctx, tenantMetadata, "acme"), &ordersv1.WatchOrdersRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the subscription is made once the call reaches the server, publish until it is
+	received := make(chan *ordersv1.WatchOrdersResponse)
+	go func() {
+		resp, err := stream.Recv()
+		if err != nil && err != io.EOF {
+			t.Error(err)
+		}
+
+		received <- resp
+	}()
+
+	ticker := time.NewTicker(10 * time.Millisecond)
+	defer ticker.Stop()
+
+	timeout := time.After(5 * time.Second)
+
+	for {
+		hub.Publish(&orders.Order{Order: orderschema.Order{ID: "other"}, Tenant: "other"})
+		hub.Publish(&orders.Order{Order: orderschema.Order{ID: "acme"}, Tenant: "acme"})
+
+		select {
+		case resp := <-received:
+			if resp.GetOrder().GetOrderUid() != "acme" {
+				t.Errorf("expected only orders of the tenant, got %v", resp.GetOrder())
+			}
+
+			return
+		case <-ticker.C:
+		case <-timeout:
+			t.Fatal("no order received")
+		}
+	}
+}
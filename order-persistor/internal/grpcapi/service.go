@@ -0,0 +1,155 @@
+package grpcapi
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"log/slog"
+	"order-persistor/internal/api"
+	"order-persistor/internal/broadcast"
+	"order-persistor/internal/grpcapi/ordersv1"
+	"order-persistor/internal/orders"
+	"order-persistor/internal/redact"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	defaultPageSize = 50
+	maxPageSize     = 1000
+)
+
+type ordersService struct {
+	ordersv1.UnimplementedOrdersServiceServer
+
+	repository orders.Repository
+	hub        *broadcast.Hub
+	redaction  *redact.Policy
+	logger     *slog.Logger
+}
+
+func (s *ordersService) GetOrder(ctx context.Context, req *ordersv1.GetOrderRequest) (*ordersv1.GetOrderResponse, error) {
+	if req.GetOrderUid() == "" {
+		return nil, status.Error(codes.InvalidArgument, "order_uid is required")
+	}
+
+	order, err := s.repository.GetByID(ctx, req.GetOrderUid())
+	if err != nil {
+		return nil, s.describeError(ctx, err)
+	}
+
+	return &ordersv1.GetOrderResponse{Order: s.message(ctx, order)}, nil
+}
+
+func (s *ordersService) ListOrders(ctx context.Context, req *ordersv1.ListOrdersRequest) (*ordersv1.ListOrdersResponse, error) {
+	n := int(req.GetPageSize())
+	switch {
+	case n < 0:
+		return nil, status.Error(codes.InvalidArgument, "page_size must not be negative")
+	case n == 0:
+		n = defaultPageSize
+	case n > maxPageSize:
+		n = maxPageSize
+	}
+
+	var (
+		list []orders.Order
+		err  error
+	)
+
+	if req.GetPageToken() == "" {
+		list, err = s.repository.ListRecent(ctx, n)
+	} else {
+		c, ok := decodePageToken(req.GetPageToken())
+		if !ok {
+			return nil, status.Error(codes.InvalidArgument, "invalid page_token")
+		}
+
+		list, err = s.repository.ListBefore(ctx, c, n)
+	}
+
+	if err != nil {
+		return nil, s.describeError(ctx, err)
+	}
+
+	res := &ordersv1.ListOrdersResponse{Orders: make([]*ordersv1.Order, 0, len(list))}
+	for i := range list {
+		res.Orders = append(res.Orders, s.message(ctx, &list[i]))
+	}
+
+	if len(list) == n {
+		res.NextPageToken = encodePageToken(orders.CursorOf(&list[len(list)-1]))
+	}
+
+	return res, nil
+}
+
+func (s *ordersService) WatchOrders(_ *ordersv1.WatchOrdersRequest, stream ordersv1.OrdersService_WatchOrdersServer) error {
+	ctx := stream.Context()
+	tenant, limited := orders.TenantFrom(ctx)
+
+	for e := range s.hub.Subscribe(ctx, "") {
+		if limited && e.Order.Tenant != tenant {
+			continue
+		}
+
+		if err := stream.Send(&ordersv1.WatchOrdersResponse{Order: s.message(ctx, &e.Order)}); err != nil {
+			return err
+		}
+	}
+
+	if ctx.Err() != nil {
+		return status.FromContextError(ctx.Err()).Err()
+	}
+
+	// the hub drops subscribers falling behind, the client has to resubscribe and catch up with ListOrders
+	return status.Error(codes.ResourceExhausted, "watcher fell behind")
+}
+
+// message converts the order, masking personal data unless the caller is allowed to read it.
+func (s *ordersService) message(ctx context.Context, o *orders.Order) *ordersv1.Order {
+	if p := principalFrom(ctx); p == nil || !p.HasScope(api.ScopeReadPII) {
+		o = s.redaction.Order(o)
+	}
+
+	return ordersv1.FromOrder(o)
+}
+
+func (s *ordersService) describeError(ctx context.Context, err error) error {
+	switch {
+	case errors.Is(err, orders.ErrNotFound):
+		return status.Error(codes.NotFound, "order not found")
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return status.FromContextError(err).Err()
+	}
+
+	s.logger.ErrorContext(ctx, "retrieving orders from repository", "err", err)
+	return status.Error(codes.Internal, "internal error")
+}
+
+// encodePageToken makes an opaque token of the cursor pointing at the last order of the page.
+func encodePageToken(c orders.Cursor) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(c.CreatedAt.Format(time.RFC3339Nano) + "|" + c.ID))
+}
+
+func decodePageToken(token string) (orders.Cursor, bool) {
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return orders.Cursor{}, false
+	}
+
+	createdAt, id, ok := strings.Cut(string(raw), "|")
+	if !ok || id == "" {
+		return orders.Cursor{}, false
+	}
+
+	t, err := time.Parse(time.RFC3339Nano, createdAt)
+	if err != nil {
+		return orders.Cursor{}, false
+	}
+
+	return orders.Cursor{CreatedAt: t, ID: id}, true
+}
@@ -0,0 +1,206 @@
+package importer
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"order-persistor/internal/orders"
+)
+
+// maxLineSize limits the size of a single order in the NDJSON input.
+const maxLineSize = 16 << 20
+
+type Repository interface {
+	CreateBatch(ctx context.Context, batch []orders.Order) ([]error, error)
+}
+
+// Failure describes an order which could not be imported.
+// Line is a line number for NDJSON input and an element number for JSON array input, both starting from 1.
+type Failure struct {
+	Line    int    `json:"line"`
+	OrderID string `json:"order_uid,omitempty"`
+	Error   string `json:"error"`
+}
+
+type Result struct {
+	Read     int
+	Imported int
+	Failed   int
+}
+
+// Importer reads orders from NDJSON or JSON array input, validates them the same way
+// the kafka consumer does and writes the valid ones by batches.
+type Importer struct {
+	repository Repository
+	batchSize  int
+	tenant     string
+	logger     *slog.Logger
+}
+
+// NewImporter creates an importer assigning all the imported orders to the tenant.
+func NewImporter(repository Repository, batchSize int, tenant string, logger *slog.Logger) *Importer {
+	return &Importer{
+		repository: repository,
+		batchSize:  batchSize,
+		tenant:     tenant,
+		logger:     logger,
+	}
+}
+
+type record struct {
+	line  int
+	order orders.Order
+}
+
+// Run imports all the orders from r, writing every failure to failures as a JSON line.
+// Returned error means the import was aborted, e.g. because the input is not a valid JSON array
+// or the database failed, while failures of separate orders do not stop it.
+func (im *Importer) Run(ctx context.Context, r io.Reader, failures io.Writer) (*Result, error) {
+	res := &Result{}
+	enc := json.NewEncoder(failures)
+	batch := make([]record, 0, im.batchSize)
+
+	fail := func(line int, orderID string, err error) error {
+		res.Failed++
+		return enc.Encode(Failure{Line: line, OrderID: orderID, Error: err.Error()})
+	}
+
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+
+		toCreate := make([]orders.Order, 0, len(batch))
+		for _, rec := range batch {
+			toCreate = append(toCreate, rec.order)
+		}
+
+		errs, err := im.repository.CreateBatch(ctx, toCreate)
+		if err != nil {
+			return fmt.Errorf("could not write batch: %w", err)
+		}
+
+		for i, err := range errs {
+			if err == nil {
+				res.Imported++
+				continue
+			}
+
+			if err := fail(batch[i].line, batch[i].order.ID, err); err != nil {
+				return err
+			}
+		}
+
+		im.logger.Info("import: batch written", "read", res.Read, "imported", res.Imported, "failed", res.Failed)
+		batch = batch[:0]
+		return nil
+	}
+
+	err := readOrders(r, func(line int, raw []byte) error {
+		res.Read++
+
+		var order orders.Order
+		if err := json.Unmarshal(raw, &order); err != nil {
+			return fail(line, "", err)
+		}
+
+		if err := orders.Validate(ctx, &order); err != nil {
+			return fail(line, order.ID, err)
+		}
+
+		order.Tenant = im.tenant
+		batch = append(batch, record{line: line, order: order})
+		if len(batch) < im.batchSize {
+			return nil
+		}
+
+		return flush()
+	})
+
+	if err != nil {
+		return res, err
+	}
+
+	return res, flush()
+}
+
+// readOrders calls fn with every raw order of the input, which is treated as a JSON array
+// if it starts with '[', and as newline-delimited JSON otherwise. Blank lines are skipped.
+func readOrders(r io.Reader, fn func(line int, raw []byte) error) error {
+	br := bufio.NewReader(r)
+
+	first, err := peekNonSpace(br)
+	if errors.Is(err, io.EOF) {
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if first == '[' {
+		return readArray(br, fn)
+	}
+
+	return readLines(br, fn)
+}
+
+func readArray(r io.Reader, fn func(line int, raw []byte) error) error {
+	dec := json.NewDecoder(r)
+	if _, err := dec.Token(); err != nil {
+		return err
+	}
+
+	for n := 1; dec.More(); n++ {
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			return fmt.Errorf("invalid JSON array at element %d: %w", n, err)
+		}
+
+		if err := fn(n, raw); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func readLines(r io.Reader, fn func(line int, raw []byte) error) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, maxLineSize)
+
+	for n := 1; scanner.Scan(); n++ {
+		raw := bytes.TrimSpace(scanner.Bytes())
+		if len(raw) == 0 {
+			continue
+		}
+
+		if err := fn(n, raw); err != nil {
+			return err
+		}
+	}
+
+	return scanner.Err()
+}
+
+// peekNonSpace returns the first non-whitespace byte without consuming the input.
+func peekNonSpace(r *bufio.Reader) (byte, error) {
+	for n := 1; ; n++ {
+		buf, err := r.Peek(n)
+		if err != nil {
+			return 0, err
+		}
+
+		switch b := buf[n-1]; b {
+		case ' ', '\t', '\r', '\n':
+			continue
+		default:
+			return b, nil
+		}
+	}
+}
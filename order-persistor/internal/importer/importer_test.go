@@ -0,0 +1,226 @@
+package importer
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"order-persistor/internal/mocks"
+	"order-persistor/internal/orders"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lezzercringe/some-assignment/orderschema"
+	"github.com/shopspring/decimal"
+	"go.uber.org/mock/gomock"
+)
+
+func newOrder(id string) orders.Order {
+	return orders.Order{
+		Order: orderschema.Order{
+			ID:          id,
+			TrackNumber: "TRK123456789",
+			Entry:       "ENTRY-ABC-1",
+			Delivery: orders.Delivery{
+				Name:    "Jane Doe",
+				Phone:   "+358401234567",
+				Zip:     "00100",
+				City:    "Helsinki",
+				Address: "Testintie 1 A 2",
+				Region:  "Uusimaa",
+				Email:   "jane.doe@example.com",
+			},
+			Payment: &orders.Payment{
+				Transaction:  "txn_" + id,
+				Currency:     "EUR",
+				Provider:     "stripe",
+				PaymentDT:    time.Date(2021, 11, 14, 8, 27, 53, 0, time.UTC).Unix(),
+				Bank:         "Test Bank Oy",
+				GoodsTotal:   199,
+				DeliveryCost: decimal.NewFromFloat(9.99),
+				CustomFee:    decimal.Zero,
+				Amount:       decimal.NewFromFloat(209.98),
+			},
+			Items: []orders.Item{
+				{
+					CHRTID:      1001,
+					TrackNumber: "ITM-TRK-1",
+					RID:         "rid-" + id,
+					Name:        "Comfort Sneakers",
+					Size:        "42",
+					NMID:        5001,
+					Brand:       "SneakerCo",
+					Status:      1,
+					Price:       decimal.NewFromFloat(199.99),
+					Sale:        decimal.Zero,
+					TotalPrice:  decimal.NewFromFloat(199.99),
+				},
+			},
+			Locale:          "en-US",
+			CustomerID:      "cust-007",
+			DeliveryService: "DHL",
+			ShardKey:        "shard-1",
+			SMID:            42,
+			CreatedAt:       time.Date(2021, 11, 14, 8, 27, 53, 0, time.UTC),
+			OOFShard:        "1",
+		},
+	}
+}
+
+func encode(t *testing.T, v any) string {
+	t.Helper()
+
+	b, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("could not encode: %v", err)
+	}
+
+	return string(b)
+}
+
+func decodeFailures(t *testing.T, b []byte) []Failure {
+	t.Helper()
+
+	var res []Failure
+	dec := json.NewDecoder(bytes.NewReader(b))
+	for dec.More() {
+		var f Failure
+		if err := dec.Decode(&f); err != nil {
+			t.Fatalf("could not decode failure: %v", err)
+		}
+
+		res = append(res, f)
+	}
+
+	return res
+}
+
+func ids(batch []orders.Order) []string {
+	res := make([]string, 0, len(batch))
+	for _, o := range batch {
+		res = append(res, o.ID)
+	}
+
+	return res
+}
+
+func TestImporter_Run(t *testing.T) {
+	t.Parallel()
+
+	log := slog.New(slog.DiscardHandler)
+
+	t.Run("ndjson - failures are reported with line numbers", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		invalid := newOrder("")
+		input := strings.Join([]string{
+			encode(t, newOrder("first")),
+			"{not a json",
+			"",
+			encode(t, invalid),
+			encode(t, newOrder("second")),
+			encode(t, newOrder("third")),
+		}, "\n")
+
+		var batches [][]string
+		rep := mocks.NewMockImportRepository(ctrl)
+		rep.EXPECT().
+			CreateBatch(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, batch []orders.Order) ([]error, error) {
+				batches = append(batches, ids(batch))
+
+				errs := make([]error, len(batch))
+				for i, o := range batch {
+					if o.ID == "second" {
+						errs[i] = errors.New("order already exists")
+					}
+				}
+
+				return errs, nil
+			}).
+			Times(2)
+
+		var failures bytes.Buffer
+		res, err := NewImporter(rep, 2, orders.DefaultTenant, log).Run(context.Background(), strings.NewReader(input), &failures)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if *res != (Result{Read: 5, Imported: 2, Failed: 3}) {
+			t.Fatalf("unexpected result: %+v", res)
+		}
+
+		if len(batches) != 2 || strings.Join(batches[0], ",") != "first,second" || strings.Join(batches[1], ",") != "third" {
+			t.Fatalf("unexpected batches: %v", batches)
+		}
+
+		got := decodeFailures(t, failures.Bytes())
+		if len(got) != 3 {
+			t.Fatalf("expected 3 failures, got: %+v", got)
+		}
+
+		for i, want := range []Failure{{Line: 2}, {Line: 4}, {Line: 5, OrderID: "second"}} {
+			if got[i].Line != want.Line || got[i].OrderID != want.OrderID || got[i].Error == "" {
+				t.Fatalf("unexpected failure %d: %+v", i, got[i])
+			}
+		}
+	})
+
+	t.Run("json array", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		input := "\n  " + encode(t, []orders.Order{newOrder("first"), newOrder("second")})
+
+		rep := mocks.NewMockImportRepository(ctrl)
+		rep.EXPECT().
+			CreateBatch(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, batch []orders.Order) ([]error, error) {
+				if strings.Join(ids(batch), ",") != "first,second" {
+					t.Fatalf("unexpected batch: %v", ids(batch))
+				}
+
+				for _, o := range batch {
+					if o.Tenant != "market-a" {
+						t.Fatalf("order %s is assigned to tenant %q", o.ID, o.Tenant)
+					}
+				}
+
+				return make([]error, len(batch)), nil
+			}).
+			Times(1)
+
+		var failures bytes.Buffer
+		res, err := NewImporter(rep, 10, "market-a", log).Run(context.Background(), strings.NewReader(input), &failures)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if *res != (Result{Read: 2, Imported: 2}) || failures.Len() != 0 {
+			t.Fatalf("unexpected result: %+v, failures: %s", res, failures.String())
+		}
+	})
+
+	t.Run("database failure aborts import", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		wantErr := orders.ErrInternalFailure
+		rep := mocks.NewMockImportRepository(ctrl)
+		rep.EXPECT().
+			CreateBatch(gomock.Any(), gomock.Any()).
+			Return(nil, wantErr).
+			Times(1)
+
+		input := encode(t, newOrder("first")) + "\n" + encode(t, newOrder("second"))
+
+		var failures bytes.Buffer
+		_, err := NewImporter(rep, 1, orders.DefaultTenant, log).Run(context.Background(), strings.NewReader(input), &failures)
+		if !errors.Is(err, wantErr) {
+			t.Fatalf("expected internal failure, got: %v", err)
+		}
+	})
+}
@@ -5,7 +5,9 @@ import (
 	"fmt"
 	"log/slog"
 	"order-persistor/internal/config"
+	"order-persistor/internal/metrics"
 	"order-persistor/internal/orders"
+	"sync/atomic"
 	"time"
 
 	lru "github.com/hashicorp/golang-lru/v2"
@@ -13,11 +15,21 @@ import (
 
 var _ orders.Repository = &OrdersCache{}
 
+type PrefillState int32
+
+const (
+	PrefillPending PrefillState = iota
+	PrefillRunning
+	PrefillDone
+	PrefillFailed
+)
+
 type OrdersCache struct {
-	decoratee orders.Repository
-	size      int
-	lru       *lru.Cache[string, *orders.Order]
-	logger    *slog.Logger
+	decoratee    orders.Repository
+	size         int
+	lru          *lru.Cache[string, *orders.Order]
+	logger       *slog.Logger
+	prefillState atomic.Int32
 }
 
 // NewOrdersCache creates a ready-to-use LRU cache.
@@ -38,19 +50,36 @@ func NewOrdersCache(cfg config.Cache, decoratee orders.Repository, logger *slog.
 	}, nil
 }
 
-// Prefill fills up the cache with the most fresh orders.
-func (c *OrdersCache) Prefill(ctx context.Context) error {
+// Prefill fills up the cache with the most fresh orders, loading them by pages of the given size.
+// It is safe to serve requests while prefill is running: misses are served by the decoratee.
+// Progress is logged after every page and reported through metrics.
+func (c *OrdersCache) Prefill(ctx context.Context, pageSize int) error {
+	c.setPrefillState(PrefillRunning)
+	metrics.PrefillTargetOrders.Set(float64(c.size))
+
 	now := time.Now()
-	qtyLoaded, err := c.load(ctx, c.size)
+	qtyLoaded, err := c.load(ctx, pageSize)
 	took := time.Since(now)
 	if err != nil {
+		c.setPrefillState(PrefillFailed)
 		return err
 	}
 
+	c.setPrefillState(PrefillDone)
 	c.logger.Info("cache: prefilled", "orders_loaded", qtyLoaded, "took", took.String())
 	return nil
 }
 
+func (c *OrdersCache) PrefillState() PrefillState {
+	return PrefillState(c.prefillState.Load())
+}
+
+// PrefillFinished reports whether prefill has either completed or failed.
+func (c *OrdersCache) PrefillFinished() bool {
+	state := c.PrefillState()
+	return state == PrefillDone || state == PrefillFailed
+}
+
 func (c *OrdersCache) Create(ctx context.Context, o *orders.Order) (*orders.Order, error) {
 	inserted, err := c.decoratee.Create(ctx, o)
 	if err != nil {
@@ -64,6 +93,11 @@ func (c *OrdersCache) Create(ctx context.Context, o *orders.Order) (*orders.Orde
 func (c *OrdersCache) GetByID(ctx context.Context, id string) (*orders.Order, error) {
 	order, hit := c.lru.Get(id)
 	if hit {
+		// cache is shared between tenants, so an order of another tenant is treated as missing
+		if tenant, ok := orders.TenantFrom(ctx); ok && order.Tenant != tenant {
+			return nil, orders.ErrNotFound
+		}
+
 		return order, nil
 	}
 
@@ -86,15 +120,70 @@ func (c *OrdersCache) ListRecent(ctx context.Context, n int) ([]orders.Order, er
 	return c.decoratee.ListRecent(ctx, n)
 }
 
-func (c *OrdersCache) load(ctx context.Context, n int) (loaded int, err error) {
-	recents, err := c.decoratee.ListRecent(ctx, n)
-	if err != nil {
-		return 0, fmt.Errorf("could not load recent orders: %w", err)
+func (c *OrdersCache) ListBefore(ctx context.Context, cursor orders.Cursor, n int) ([]orders.Order, error) {
+	return c.decoratee.ListBefore(ctx, cursor, n)
+}
+
+// Contains reports whether the order is cached, so reading it does not reach the decoratee.
+func (c *OrdersCache) Contains(id string) bool {
+	return c.lru.Contains(id)
+}
+
+// Evict drops the order from the cache, so the next read goes to the decoratee.
+func (c *OrdersCache) Evict(id string) {
+	c.lru.Remove(id)
+}
+
+// Purge drops all the cached orders.
+func (c *OrdersCache) Purge() {
+	c.lru.Purge()
+}
+
+// load walks the orders feed page by page until the cache is full, the feed is exhausted
+// or the cache size worth of orders is walked.
+// Orders already present in the cache are left untouched, since they might be fresher than the loaded ones.
+func (c *OrdersCache) load(ctx context.Context, pageSize int) (loaded int, err error) {
+	var cursor *orders.Cursor
+
+	for loaded < c.size {
+		free := min(c.size-c.lru.Len(), c.size-loaded)
+		if free <= 0 {
+			return loaded, nil
+		}
+
+		page, err := c.loadPage(ctx, cursor, min(pageSize, free))
+		if err != nil {
+			return loaded, fmt.Errorf("could not load recent orders: %w", err)
+		}
+
+		for _, order := range page {
+			c.lru.ContainsOrAdd(order.ID, &order)
+		}
+
+		loaded += len(page)
+		metrics.PrefillLoadedOrders.Set(float64(loaded))
+		c.logger.Info("cache: prefill progress", "orders_loaded", loaded, "target", c.size)
+
+		if len(page) < min(pageSize, free) {
+			return loaded, nil
+		}
+
+		next := orders.CursorOf(&page[len(page)-1])
+		cursor = &next
 	}
 
-	for _, order := range recents {
-		c.lru.Add(order.ID, &order)
+	return loaded, nil
+}
+
+func (c *OrdersCache) loadPage(ctx context.Context, cursor *orders.Cursor, n int) ([]orders.Order, error) {
+	if cursor == nil {
+		return c.decoratee.ListRecent(ctx, n)
 	}
 
-	return len(recents), nil
+	return c.decoratee.ListBefore(ctx, *cursor, n)
+}
+
+func (c *OrdersCache) setPrefillState(s PrefillState) {
+	c.prefillState.Store(int32(s))
+	metrics.PrefillState.Set(float64(s))
 }
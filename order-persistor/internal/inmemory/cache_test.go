@@ -12,6 +12,8 @@ import (
 	"time"
 
 	"go.uber.org/mock/gomock"
+
+	"github.com/lezzercringe/some-assignment/orderschema"
 )
 
 func TestOrdersCache_GetByID(t *testing.T) {
@@ -19,8 +21,10 @@ func TestOrdersCache_GetByID(t *testing.T) {
 
 	log := slog.New(slog.DiscardHandler)
 	testOrder := &orders.Order{
-		ID:        "someid",
-		CreatedAt: time.Now(),
+		Order: orderschema.Order{
+			ID:        "someid",
+			CreatedAt: time.Now(),
+		},
 	}
 
 	t.Run("cache miss", func(t *testing.T) {
@@ -72,6 +76,30 @@ func TestOrdersCache_GetByID(t *testing.T) {
 			t.Fatal("unexpected order extracted from cache")
 		}
 	})
+
+	t.Run("cache hit of another tenant", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		rep := mocks.NewMockRepository(ctrl)
+
+		cache, err := NewOrdersCache(config.Cache{Size: 1}, rep, log)
+		if err != nil {
+			t.Fatalf("error creating cache: %v", err)
+		}
+
+		cache.lru.Add(testOrder.ID, &orders.Order{Order: orderschema.Order{ID: testOrder.ID}, Tenant: "first"})
+
+		_, err = cache.GetByID(orders.WithTenant(context.Background(), "second"), testOrder.ID)
+		if !errors.Is(err, orders.ErrNotFound) {
+			t.Fatalf("expected not found error, got: %v", err)
+		}
+
+		order, err := cache.GetByID(orders.WithTenant(context.Background(), "first"), testOrder.ID)
+		if err != nil || order.Tenant != "first" {
+			t.Fatalf("expected order of the tenant, got: %v, %v", order, err)
+		}
+	})
 }
 
 func TestOrdersCache_Create(t *testing.T) {
@@ -79,8 +107,10 @@ func TestOrdersCache_Create(t *testing.T) {
 
 	log := slog.New(slog.DiscardHandler)
 	testOrder := &orders.Order{
-		ID:        "someid",
-		CreatedAt: time.Now(),
+		Order: orderschema.Order{
+			ID:        "someid",
+			CreatedAt: time.Now(),
+		},
 	}
 
 	t.Run("error gets propagated", func(t *testing.T) {
@@ -113,7 +143,9 @@ func TestOrdersCache_Create(t *testing.T) {
 
 	t.Run("success - eviction on lru size limit achieved", func(t *testing.T) {
 		prevOrder := &orders.Order{
-			ID: "prev-order",
+			Order: orderschema.Order{
+				ID: "prev-order",
+			},
 		}
 
 		ctrl := gomock.NewController(t)
@@ -180,7 +212,7 @@ func TestOrdersCache_Prefill(t *testing.T) {
 			t.Fatalf("error creating cache: %v", err)
 		}
 
-		err = cache.Prefill(context.Background())
+		err = cache.Prefill(context.Background(), size)
 		if err == nil {
 			t.Fatal("cache did not return any error")
 		}
@@ -188,14 +220,20 @@ func TestOrdersCache_Prefill(t *testing.T) {
 		if !errors.Is(err, decorateeErr) {
 			t.Fatal("returned error does not wrap decoratee error")
 		}
+
+		if cache.PrefillState() != PrefillFailed || !cache.PrefillFinished() {
+			t.Fatal("prefill was not marked as failed")
+		}
 	})
 
 	t.Run("caches values on decoratee success", func(t *testing.T) {
 		const size = 1
 
 		testOrder := orders.Order{
-			ID:        "someid",
-			CreatedAt: time.Now(),
+			Order: orderschema.Order{
+				ID:        "someid",
+				CreatedAt: time.Now(),
+			},
 		}
 
 		ctrl := gomock.NewController(t)
@@ -213,7 +251,7 @@ func TestOrdersCache_Prefill(t *testing.T) {
 			t.Fatalf("error creating cache: %v", err)
 		}
 
-		if err := cache.Prefill(context.Background()); err != nil {
+		if err := cache.Prefill(context.Background(), size); err != nil {
 			t.Fatal("cache errored")
 		}
 
@@ -226,4 +264,129 @@ func TestOrdersCache_Prefill(t *testing.T) {
 			t.Fatal("cache stored unexpected order")
 		}
 	})
+
+	t.Run("loads orders page by page", func(t *testing.T) {
+		const (
+			size     = 3
+			pageSize = 2
+		)
+
+		now := time.Now()
+		recent := []orders.Order{
+			{Order: orderschema.Order{ID: "first", CreatedAt: now}},
+			{Order: orderschema.Order{ID: "second", CreatedAt: now.Add(-time.Minute)}},
+		}
+		older := []orders.Order{
+			{Order: orderschema.Order{ID: "third", CreatedAt: now.Add(-2 * time.Minute)}},
+		}
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		rep := mocks.NewMockRepository(ctrl)
+
+		gomock.InOrder(
+			rep.EXPECT().
+				ListRecent(gomock.Any(), pageSize).
+				Return(recent, nil),
+			rep.EXPECT().
+				ListBefore(gomock.Any(), orders.CursorOf(&recent[1]), 1).
+				Return(older, nil),
+		)
+
+		cache, err := NewOrdersCache(config.Cache{Size: size}, rep, log)
+		if err != nil {
+			t.Fatalf("error creating cache: %v", err)
+		}
+
+		if err := cache.Prefill(context.Background(), pageSize); err != nil {
+			t.Fatalf("cache errored: %v", err)
+		}
+
+		for _, id := range []string{"first", "second", "third"} {
+			if !cache.lru.Contains(id) {
+				t.Fatalf("cache does not contain order %s", id)
+			}
+		}
+
+		if cache.PrefillState() != PrefillDone {
+			t.Fatal("prefill was not marked as done")
+		}
+	})
+
+	t.Run("does not overwrite orders cached meanwhile", func(t *testing.T) {
+		const size = 2
+
+		fresh := &orders.Order{Order: orderschema.Order{ID: "someid", Locale: "fresh"}}
+		stale := orders.Order{Order: orderschema.Order{ID: "someid", Locale: "stale"}}
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		rep := mocks.NewMockRepository(ctrl)
+
+		cache, err := NewOrdersCache(config.Cache{Size: size}, rep, log)
+		if err != nil {
+			t.Fatalf("error creating cache: %v", err)
+		}
+
+		cache.lru.Add(fresh.ID, fresh)
+
+		gomock.InOrder(
+			rep.EXPECT().
+				ListRecent(gomock.Any(), 1).
+				Return([]orders.Order{stale}, nil),
+			rep.EXPECT().
+				ListBefore(gomock.Any(), orders.CursorOf(&stale), 1).
+				Return(nil, nil),
+		)
+
+		if err := cache.Prefill(context.Background(), size); err != nil {
+			t.Fatalf("cache errored: %v", err)
+		}
+
+		order, _ := cache.lru.Get(fresh.ID)
+		if order != fresh {
+			t.Fatal("prefill has overwritten fresh order")
+		}
+	})
+}
+
+func TestOrdersCache_Evict(t *testing.T) {
+	t.Parallel()
+
+	log := slog.New(slog.DiscardHandler)
+	testOrder := &orders.Order{
+		Order: orderschema.Order{
+			ID:        "someid",
+			CreatedAt: time.Now(),
+		},
+	}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	rep := mocks.NewMockRepository(ctrl)
+
+	// evicted order has to be read from decoratee again
+	rep.EXPECT().
+		GetByID(gomock.Any(), gomock.Eq(testOrder.ID)).
+		Return(testOrder, nil).
+		Times(1)
+
+	cache, err := NewOrdersCache(config.Cache{Size: 1}, rep, log)
+	if err != nil {
+		t.Fatalf("error creating cache: %v", err)
+	}
+
+	cache.lru.Add(testOrder.ID, testOrder)
+	cache.Evict(testOrder.ID)
+
+	if cache.lru.Contains(testOrder.ID) {
+		t.Fatal("order was not evicted")
+	}
+
+	if _, err := cache.GetByID(context.Background(), testOrder.ID); err != nil {
+		t.Fatalf("error while extracting order: %v", err)
+	}
 }
@@ -0,0 +1,66 @@
+package invalidation
+
+import (
+	"context"
+	"log/slog"
+)
+
+var _ Evictor = &PublishingEvictor{}
+
+// PublishingEvictor evicts orders from the local cache and announces the eviction to the other replicas.
+// It is used for changes made bypassing PublishingRepository, like erasure of personal data.
+type PublishingEvictor struct {
+	cache     Evictor
+	publisher Publisher
+	origin    string
+	logger    *slog.Logger
+}
+
+func NewPublishingEvictor(cache Evictor, publisher Publisher, origin string, logger *slog.Logger) *PublishingEvictor {
+	return &PublishingEvictor{
+		cache:     cache,
+		publisher: publisher,
+		origin:    origin,
+		logger:    logger,
+	}
+}
+
+func (e *PublishingEvictor) Evict(id string) {
+	e.cache.Evict(id)
+	publish(context.Background(), e.publisher, Event{OrderID: id, Origin: e.origin}, e.logger)
+}
+
+func (e *PublishingEvictor) Purge() {
+	e.cache.Purge()
+}
+
+var _ Forgetter = &PublishingForgetter{}
+
+// PublishingForgetter forgets erased orders in the locally replayed events and announces the erasure
+// to the other replicas, so that they forget the orders as well.
+type PublishingForgetter struct {
+	replay    Forgetter
+	publisher Publisher
+	origin    string
+	logger    *slog.Logger
+}
+
+func NewPublishingForgetter(replay Forgetter, publisher Publisher, origin string, logger *slog.Logger) *PublishingForgetter {
+	return &PublishingForgetter{
+		replay:    replay,
+		publisher: publisher,
+		origin:    origin,
+		logger:    logger,
+	}
+}
+
+func (f *PublishingForgetter) Forget(ids ...string) {
+	f.replay.Forget(ids...)
+	for _, id := range ids {
+		publish(context.Background(), f.publisher, Event{OrderID: id, Origin: f.origin, Erased: true}, f.logger)
+	}
+}
+
+func (f *PublishingForgetter) Purge() {
+	f.replay.Purge()
+}
@@ -0,0 +1,93 @@
+package invalidation
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+	"os"
+	"time"
+)
+
+// Event announces that the order with the given ID has changed,
+// so every replica has to drop its cached copy.
+type Event struct {
+	OrderID string `json:"order_id"`
+	// Origin identifies the replica which made the change.
+	Origin string `json:"origin"`
+	// Erased tells that personal data of the order was erased, so every replica also has to forget
+	// the order in the events it keeps for replay to stream subscribers.
+	Erased bool `json:"erased,omitempty"`
+}
+
+type Publisher interface {
+	Publish(ctx context.Context, e Event) error
+}
+
+type Subscriber interface {
+	// Subscribe calls handle for every received event, blocking until ctx is done or the channel fails.
+	Subscribe(ctx context.Context, handle func(Event)) error
+}
+
+type Channel interface {
+	Publisher
+	Subscriber
+}
+
+type Evictor interface {
+	Evict(id string)
+	Purge()
+}
+
+// Forgetter drops orders from the events kept for replay to stream subscribers.
+type Forgetter interface {
+	Forget(ids ...string)
+	Purge()
+}
+
+// NewOrigin generates an identifier unique for the running replica.
+func NewOrigin() string {
+	host, err := os.Hostname()
+	if err != nil {
+		host = "unknown"
+	}
+
+	suffix := make([]byte, 4)
+	rand.Read(suffix)
+
+	return host + "-" + hex.EncodeToString(suffix)
+}
+
+// Run evicts orders announced by other replicas from the cache, and forgets the erased ones in the replayed events,
+// blocking the calling coroutine. On channel failure it purges the whole cache and the replayed events,
+// since events could have been missed, and resubscribes after backoff.
+func Run(ctx context.Context, s Subscriber, origin string, cache Evictor, replay Forgetter, backoff time.Duration, logger *slog.Logger) error {
+	handle := func(e Event) {
+		if e.Origin == origin {
+			return
+		}
+
+		logger.DebugContext(ctx, "invalidation: evicting order", "order_id", e.OrderID, "origin", e.Origin, "erased", e.Erased)
+		cache.Evict(e.OrderID)
+		if e.Erased {
+			replay.Forget(e.OrderID)
+		}
+	}
+
+	for {
+		err := s.Subscribe(ctx, handle)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		logger.Error("invalidation channel failed", "err", err, "will resubscribe in", backoff.String())
+		cache.Purge()
+		replay.Purge()
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+	}
+}
@@ -0,0 +1,187 @@
+package invalidation_test
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"order-persistor/internal/invalidation"
+	"order-persistor/internal/mocks"
+	"order-persistor/internal/orders"
+	"testing"
+	"time"
+
+	"go.uber.org/mock/gomock"
+
+	"github.com/lezzercringe/some-assignment/orderschema"
+)
+
+func TestPublishingRepository_Create(t *testing.T) {
+	t.Parallel()
+
+	log := slog.New(slog.DiscardHandler)
+	testOrder := &orders.Order{
+		Order: orderschema.Order{
+			ID:        "someid",
+			CreatedAt: time.Now(),
+		},
+	}
+
+	t.Run("publishes id of created order", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		rep := mocks.NewMockRepository(ctrl)
+		publisher := mocks.NewMockPublisher(ctrl)
+
+		rep.EXPECT().
+			Create(gomock.Any(), gomock.Eq(testOrder)).
+			Return(testOrder, nil).
+			Times(1)
+
+		publisher.EXPECT().
+			Publish(gomock.Any(), gomock.Eq(invalidation.Event{OrderID: testOrder.ID, Origin: "replica-1"})).
+			Return(nil).
+			Times(1)
+
+		repository := invalidation.NewPublishingRepository(rep, publisher, "replica-1", log)
+
+		if _, err := repository.Create(context.Background(), testOrder); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("does not publish on decoratee error", func(t *testing.T) {
+		decorateeErr := errors.New("some obscure error")
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		rep := mocks.NewMockRepository(ctrl)
+		publisher := mocks.NewMockPublisher(ctrl)
+
+		rep.EXPECT().
+			Create(gomock.Any(), gomock.Eq(testOrder)).
+			Return(nil, decorateeErr).
+			Times(1)
+
+		repository := invalidation.NewPublishingRepository(rep, publisher, "replica-1", log)
+
+		_, err := repository.Create(context.Background(), testOrder)
+		if !errors.Is(err, decorateeErr) {
+			t.Fatal("decoratee error was not propagated")
+		}
+	})
+
+	t.Run("publishing failure does not fail the write", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		rep := mocks.NewMockRepository(ctrl)
+		publisher := mocks.NewMockPublisher(ctrl)
+
+		rep.EXPECT().
+			Create(gomock.Any(), gomock.Eq(testOrder)).
+			Return(testOrder, nil).
+			Times(1)
+
+		publisher.EXPECT().
+			Publish(gomock.Any(), gomock.Any()).
+			Return(errors.New("channel is down")).
+			Times(1)
+
+		repository := invalidation.NewPublishingRepository(rep, publisher, "replica-1", log)
+
+		if _, err := repository.Create(context.Background(), testOrder); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+}
+
+func TestRun(t *testing.T) {
+	t.Parallel()
+
+	log := slog.New(slog.DiscardHandler)
+
+	t.Run("evicts orders changed by other replicas only and forgets erased ones", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		subscriber := mocks.NewMockSubscriber(ctrl)
+		cache := mocks.NewMockEvictor(ctrl)
+		replay := mocks.NewMockForgetter(ctrl)
+
+		subscriber.EXPECT().
+			Subscribe(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(ctx context.Context, handle func(invalidation.Event)) error {
+				handle(invalidation.Event{OrderID: "own", Origin: "replica-1"})
+				handle(invalidation.Event{OrderID: "foreign", Origin: "replica-2"})
+				handle(invalidation.Event{OrderID: "own-erased", Origin: "replica-1", Erased: true})
+				handle(invalidation.Event{OrderID: "foreign-erased", Origin: "replica-2", Erased: true})
+				cancel()
+				return ctx.Err()
+			}).
+			Times(1)
+
+		cache.EXPECT().Evict("foreign").Times(1)
+		cache.EXPECT().Evict("foreign-erased").Times(1)
+		replay.EXPECT().Forget("foreign-erased").Times(1)
+
+		err := invalidation.Run(ctx, subscriber, "replica-1", cache, replay, time.Millisecond, log)
+		if !errors.Is(err, context.Canceled) {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("purges cache and replayed events and resubscribes on channel failure", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		subscriber := mocks.NewMockSubscriber(ctrl)
+		cache := mocks.NewMockEvictor(ctrl)
+		replay := mocks.NewMockForgetter(ctrl)
+
+		gomock.InOrder(
+			subscriber.EXPECT().
+				Subscribe(gomock.Any(), gomock.Any()).
+				Return(errors.New("connection lost")),
+			cache.EXPECT().Purge(),
+			replay.EXPECT().Purge(),
+			subscriber.EXPECT().
+				Subscribe(gomock.Any(), gomock.Any()).
+				DoAndReturn(func(ctx context.Context, handle func(invalidation.Event)) error {
+					cancel()
+					return ctx.Err()
+				}),
+		)
+
+		err := invalidation.Run(ctx, subscriber, "replica-1", cache, replay, time.Millisecond, log)
+		if !errors.Is(err, context.Canceled) {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+}
+
+func TestPublishingForgetter(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	replay := mocks.NewMockForgetter(ctrl)
+	publisher := mocks.NewMockPublisher(ctrl)
+
+	replay.EXPECT().Forget("first", "second")
+	publisher.EXPECT().Publish(gomock.Any(), invalidation.Event{OrderID: "first", Origin: "replica-1", Erased: true})
+	publisher.EXPECT().
+		Publish(gomock.Any(), invalidation.Event{OrderID: "second", Origin: "replica-1", Erased: true}).
+		Return(errors.New("connection lost"))
+
+	// publishing failure of one order does not stop announcing the others
+	invalidation.NewPublishingForgetter(replay, publisher, "replica-1", slog.New(slog.DiscardHandler)).Forget("first", "second")
+}
@@ -0,0 +1,65 @@
+package invalidation
+
+import (
+	"context"
+	"log/slog"
+	"order-persistor/internal/orders"
+)
+
+var _ orders.Repository = &PublishingRepository{}
+
+// PublishingRepository announces every successful write to the other replicas.
+type PublishingRepository struct {
+	decoratee orders.Repository
+	publisher Publisher
+	origin    string
+	logger    *slog.Logger
+}
+
+func NewPublishingRepository(decoratee orders.Repository, publisher Publisher, origin string, logger *slog.Logger) *PublishingRepository {
+	return &PublishingRepository{
+		decoratee: decoratee,
+		publisher: publisher,
+		origin:    origin,
+		logger:    logger,
+	}
+}
+
+// Create persists the order and publishes its ID.
+// Publishing failure is only logged, because the order is already written at that point.
+func (r *PublishingRepository) Create(ctx context.Context, o *orders.Order) (*orders.Order, error) {
+	inserted, err := r.decoratee.Create(ctx, o)
+	if err != nil {
+		return nil, err
+	}
+
+	r.publish(ctx, inserted.ID)
+	return inserted, nil
+}
+
+func (r *PublishingRepository) GetByID(ctx context.Context, id string) (*orders.Order, error) {
+	return r.decoratee.GetByID(ctx, id)
+}
+
+func (r *PublishingRepository) ListRecent(ctx context.Context, n int) ([]orders.Order, error) {
+	return r.decoratee.ListRecent(ctx, n)
+}
+
+func (r *PublishingRepository) ListBefore(ctx context.Context, c orders.Cursor, n int) ([]orders.Order, error) {
+	return r.decoratee.ListBefore(ctx, c, n)
+}
+
+func (r *PublishingRepository) publish(ctx context.Context, orderID string) {
+	publish(ctx, r.publisher, Event{OrderID: orderID, Origin: r.origin}, r.logger)
+}
+
+// publish announces the event, only logging failures.
+func publish(ctx context.Context, publisher Publisher, e Event, logger *slog.Logger) {
+	if err := publisher.Publish(ctx, e); err != nil {
+		logger.ErrorContext(ctx,
+			"failed publishing order invalidation",
+			"err", err,
+			"order_id", e.OrderID,
+		)
+	}
+}
@@ -8,16 +8,16 @@ import (
 	"log/slog"
 	"order-persistor/internal/config"
 	"order-persistor/internal/orders"
+	"order-persistor/internal/redact"
 	"sync"
 	"time"
 
 	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
-	"github.com/go-playground/validator/v10"
 )
 
 type Client interface {
 	ReadMessage(timeout time.Duration) (*kafka.Message, error)
-	Subscribe(topic string, rebalanceCb kafka.RebalanceCb) error
+	SubscribeTopics(topics []string, rebalanceCb kafka.RebalanceCb) error
 	Commit() ([]kafka.TopicPartition, error)
 	Close() error
 }
@@ -28,13 +28,22 @@ type OrdersConsumer struct {
 
 	ordersRepository orders.Repository
 	logger           *slog.Logger
+	routes           []*route
+	// redaction masks personal data of the messages which are logged
+	redaction *redact.Policy
 
 	shutdownOnce sync.Once
 }
 
 // NewOrdersConsumer creates a ready-to-use kafka orders consumer, however at the point of creation no subscription is being done.
 // Subscription only starts with an explicit call of Run function.
-func NewOrdersConsumer(cfg config.KafkaConsumer, ordersRepository orders.Repository, logger *slog.Logger) (*OrdersConsumer, error) {
+// Messages are logged on failures with personal data masked by the redaction policy.
+func NewOrdersConsumer(cfg config.KafkaConsumer, ordersRepository orders.Repository, redaction *redact.Policy, logger *slog.Logger) (*OrdersConsumer, error) {
+	routes, err := newRoutes(cfg)
+	if err != nil {
+		return nil, err
+	}
+
 	c, err := kafka.NewConsumer(&kafka.ConfigMap{
 		"bootstrap.servers":  cfg.Servers,
 		"group.id":           cfg.GroupID,
@@ -51,15 +60,22 @@ func NewOrdersConsumer(cfg config.KafkaConsumer, ordersRepository orders.Reposit
 		cfg:              cfg,
 		ordersRepository: ordersRepository,
 		logger:           logger,
+		routes:           routes,
+		redaction:        redaction,
 	}, nil
 }
 
-// Run subcribes to the topic and starts processing it, blocking the calling coroutine.
+// Run subcribes to the topics and starts processing them, blocking the calling coroutine.
 func (c *OrdersConsumer) Run(ctx context.Context) error {
 	c.logger.Info("started kafka consumer", "cfg", c.cfg)
 
-	if err := c.client.Subscribe(c.cfg.Topic, nil); err != nil {
-		return fmt.Errorf("could not subscribe to a topic: %w", err)
+	topics := make([]string, 0, len(c.routes))
+	for _, r := range c.routes {
+		topics = append(topics, r.topic)
+	}
+
+	if err := c.client.SubscribeTopics(topics, nil); err != nil {
+		return fmt.Errorf("could not subscribe to topics: %w", err)
 	}
 	defer c.closeConsumer()
 
@@ -96,13 +112,14 @@ func (c *OrdersConsumer) Run(ctx context.Context) error {
 				}
 			}
 
-			c.logger.Debug("consumed order from kafka")
+			topic := *msg.TopicPartition.Topic
+			c.logger.Debug("consumed order from kafka", "topic", topic)
 
 			// process message inside a closure to be able to use defer cancel()
 			err = func() error {
 				ctx, cancel := context.WithTimeout(ctx, c.cfg.ProcessTimeout)
 				defer cancel()
-				return c.handleMessage(ctx, msg.Value)
+				return c.handleMessage(ctx, c.routeOf(topic), msg.Value)
 			}()
 
 			// do not commit in case of internal error (if order was valid)
@@ -127,21 +144,57 @@ func (c *OrdersConsumer) Run(ctx context.Context) error {
 
 var errMalformedOrder = errors.New("message was malformed")
 
-// handleMessage processes JSON-encoded order message
+// routeOf returns the route of the first configured topic matching the given one.
+func (c *OrdersConsumer) routeOf(topic string) *route {
+	for _, r := range c.routes {
+		if r.matches(topic) {
+			return r
+		}
+	}
+
+	// subscription only delivers configured topics, so this is never expected to happen
+	c.logger.Warn("message from unexpected topic is processed with the first topic settings", "topic", topic)
+	return c.routes[0]
+}
+
+// handleMessage processes order message encoded as configured for its topic
 // In case of bad json/order returns errMalformedOrder
-func (c *OrdersConsumer) handleMessage(ctx context.Context, body []byte) error {
+func (c *OrdersConsumer) handleMessage(ctx context.Context, r *route, body []byte) error {
+	body, err := r.decode(body)
+	if err != nil {
+		c.logger.ErrorContext(ctx,
+			"could not decode order from kafka",
+			"err", err,
+			"topic", r.topic,
+		)
+
+		return errors.Join(errMalformedOrder, err)
+	}
+
+	if r.schema != nil {
+		if err := r.schema.Validate(body); err != nil {
+			c.logger.ErrorContext(ctx,
+				"order from kafka does not match schema",
+				"err", err,
+				"message", c.redaction.Message(body),
+			)
+
+			return errors.Join(errMalformedOrder, err)
+		}
+	}
+
 	var order orders.Order
 	if err := json.Unmarshal(body, &order); err != nil {
 		c.logger.ErrorContext(ctx,
 			"bad json order from kafka",
 			"err", err,
-			"message", string(body),
+			"message", c.redaction.Message(body),
 		)
 
 		return errors.Join(errMalformedOrder, err)
 	}
 
-	if err := validator.New().StructCtx(ctx, order); err != nil {
+	if err := orders.Validate(ctx, &order); err != nil {
 		if ctx.Err() != nil {
 			return ctx.Err()
 		}
@@ -149,12 +202,13 @@ func (c *OrdersConsumer) handleMessage(ctx context.Context, body []byte) error {
 		c.logger.ErrorContext(ctx,
 			"consumed malformed order from kafka",
 			"err", err,
-			"message", string(body),
+			"message", c.redaction.Message(body),
 		)
 
 		return errors.Join(errMalformedOrder, err)
 	}
 
+	order.Tenant = r.tenant
 	if _, err := c.ordersRepository.Create(ctx, &order); err != nil {
 		if errors.Is(err, orders.ErrInternalFailure) {
 			return fmt.Errorf("failed creating new order: %w", err)
@@ -163,7 +217,7 @@ func (c *OrdersConsumer) handleMessage(ctx context.Context, body []byte) error {
 		c.logger.ErrorContext(
 			ctx, "failed creating new order",
 			"err", err,
-			"message", string(body),
+			"message", c.redaction.Message(body),
 		)
 
 		if ctx.Err() != nil {
@@ -8,25 +8,34 @@ import (
 	"order-persistor/internal/config"
 	"order-persistor/internal/mocks"
 	"order-persistor/internal/orders"
+	"order-persistor/internal/redact"
 	"sync"
 	"testing"
 	"time"
 
+	"github.com/lezzercringe/some-assignment/orderschema"
 	"github.com/shopspring/decimal"
 	"go.uber.org/mock/gomock"
 )
 
 func newTestConsumer(client Client, repository orders.Repository) *OrdersConsumer {
+	cfg := config.KafkaConsumer{
+		Topic:              "test-topic",
+		ReadTimeout:        1 * time.Second,
+		ProcessTimeout:     1 * time.Second,
+		ReadFailureBackoff: 1 * time.Second,
+	}
+
+	routes, _ := newRoutes(cfg)
+	redaction, _ := redact.NewPolicy(redact.Partial, "")
+
 	return &OrdersConsumer{
-		client: client,
-		cfg: config.KafkaConsumer{
-			Topic:              "test-topic",
-			ReadTimeout:        1 * time.Second,
-			ProcessTimeout:     1 * time.Second,
-			ReadFailureBackoff: 1 * time.Second,
-		},
+		client:           client,
+		cfg:              cfg,
 		ordersRepository: repository,
 		logger:           slog.New(slog.DiscardHandler),
+		routes:           routes,
+		redaction:        redaction,
 		shutdownOnce:     sync.Once{},
 	}
 }
@@ -53,6 +62,7 @@ func TestOrdersConsumer_handleMessage(t *testing.T) {
 
 		err := consumer.handleMessage(
 			context.Background(),
+			consumer.routes[0],
 			jsonEncoded,
 		)
 
@@ -79,6 +89,7 @@ func TestOrdersConsumer_handleMessage(t *testing.T) {
 
 		err := consumer.handleMessage(
 			context.Background(),
+			consumer.routes[0],
 			[]byte("some bad json"),
 		)
 
@@ -99,6 +110,7 @@ func TestOrdersConsumer_handleMessage(t *testing.T) {
 
 		err := consumer.handleMessage(
 			context.Background(),
+			consumer.routes[0],
 			jsonEncoded,
 		)
 
@@ -107,6 +119,53 @@ func TestOrdersConsumer_handleMessage(t *testing.T) {
 		}
 	})
 
+	t.Run("strict mode - unknown field returns malformed message error", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		repository := mocks.NewMockRepository(ctrl)
+		client := mocks.NewMockClient(ctrl)
+		consumer := newTestConsumer(client, repository)
+		consumer.routes[0].schema, _ = orderschema.NewSchemaValidator(true)
+
+		var fields map[string]any
+		jsonEncoded, _ := json.Marshal(validOrder)
+		_ = json.Unmarshal(jsonEncoded, &fields)
+		fields["comment"] = "unknown field"
+		jsonEncoded, _ = json.Marshal(fields)
+
+		err := consumer.handleMessage(
+			context.Background(),
+			consumer.routes[0],
+			jsonEncoded,
+		)
+
+		if err == nil || !errors.Is(err, errMalformedOrder) || !errors.Is(err, orderschema.ErrSchemaViolation) {
+			t.Fatalf("did not return malformed message error, got: %v", err)
+		}
+	})
+
+	t.Run("strict mode - valid order is created", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		repository := mocks.NewMockRepository(ctrl)
+		client := mocks.NewMockClient(ctrl)
+		consumer := newTestConsumer(client, repository)
+		consumer.routes[0].schema, _ = orderschema.NewSchemaValidator(true)
+
+		repository.EXPECT().
+			Create(gomock.Any(), gomock.Eq(&validOrder)).
+			Return(&validOrder, nil).
+			Times(1)
+
+		jsonEncoded, _ := json.Marshal(validOrder)
+
+		if err := consumer.handleMessage(context.Background(), consumer.routes[0], jsonEncoded); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+
 	t.Run("context cancellation - does return ctx error", func(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		defer ctrl.Finish()
@@ -129,6 +188,7 @@ func TestOrdersConsumer_handleMessage(t *testing.T) {
 
 		err := consumer.handleMessage(
 			ctx,
+			consumer.routes[0],
 			jsonEncoded,
 		)
 
@@ -143,137 +203,134 @@ func TestOrdersConsumer_handleMessage(t *testing.T) {
 }
 
 var validOrder = orders.Order{
-	ID:          "order-0001",
-	TrackNumber: "TRK123456789",
-	Entry:       "ENTRY-ABC-1",
-
-	Delivery: orders.Delivery{
-		Name:    "Jane Doe",
-		Phone:   "+358401234567",
-		Zip:     "00100",
-		City:    "Helsinki",
-		Address: "Testintie 1 A 2",
-		Region:  "Uusimaa",
-		Email:   "jane.doe@example.com",
-	},
-
-	Payment: &orders.Payment{
-		Transaction: "txn_20250809_01",
-		RequestID:   "req-2025-08-09-01",
-		Currency:    "EUR",
-		Provider:    "stripe",
-		PaymentDT:   time.Now().Unix(),
-
-		Bank:         "Test Bank Oy",
-		GoodsTotal:   249,
-		DeliveryCost: decimal.NewFromFloat(9.99),
-		CustomFee:    decimal.NewFromFloat(0.00),
-		Amount:       decimal.NewFromFloat(249.49).Add(decimal.NewFromFloat(9.99)),
-	},
-
-	Items: []orders.Item{
-		{
-			CHRTID:      1001,
-			TrackNumber: "ITM-TRK-1",
-			RID:         "rid-1",
-			Name:        "Comfort Sneakers",
-			Size:        "42",
-			NMID:        5001,
-			Brand:       "SneakerCo",
-			Status:      1,
-			Price:       decimal.NewFromFloat(199.99),
-			Sale:        decimal.NewFromFloat(0.00),
-			TotalPrice:  decimal.NewFromFloat(199.99),
+	Order: orderschema.Order{
+		ID:          "order-0001",
+		TrackNumber: "TRK123456789",
+		Entry:       "ENTRY-ABC-1",
+		Delivery: orders.Delivery{
+			Name:    "Jane Doe",
+			Phone:   "+358401234567",
+			Zip:     "00100",
+			City:    "Helsinki",
+			Address: "Testintie 1 A 2",
+			Region:  "Uusimaa",
+			Email:   "jane.doe@example.com",
+		},
+		Payment: &orders.Payment{
+			Transaction: "txn_20250809_01",
+			RequestID:   "req-2025-08-09-01",
+			Currency:    "EUR",
+			Provider:    "stripe",
+			PaymentDT:   time.Now().Unix(),
+
+			Bank:         "Test Bank Oy",
+			GoodsTotal:   249,
+			DeliveryCost: decimal.NewFromFloat(9.99),
+			CustomFee:    decimal.NewFromFloat(0.00),
+			Amount:       decimal.NewFromFloat(249.49).Add(decimal.NewFromFloat(9.99)),
 		},
-		{
-			CHRTID:      1002,
-			TrackNumber: "ITM-TRK-2",
-			RID:         "rid-2",
-			Name:        "Everyday Socks (3-pack)",
-			Size:        "L",
-			NMID:        5002,
-			Brand:       "SockMakers",
-			Status:      1,
-			Price:       decimal.NewFromFloat(49.50),
-			Sale:        decimal.NewFromFloat(0.00),
-			TotalPrice:  decimal.NewFromFloat(49.50),
+		Items: []orders.Item{
+			{
+				CHRTID:      1001,
+				TrackNumber: "ITM-TRK-1",
+				RID:         "rid-1",
+				Name:        "Comfort Sneakers",
+				Size:        "42",
+				NMID:        5001,
+				Brand:       "SneakerCo",
+				Status:      1,
+				Price:       decimal.NewFromFloat(199.99),
+				Sale:        decimal.NewFromFloat(0.00),
+				TotalPrice:  decimal.NewFromFloat(199.99),
+			},
+			{
+				CHRTID:      1002,
+				TrackNumber: "ITM-TRK-2",
+				RID:         "rid-2",
+				Name:        "Everyday Socks (3-pack)",
+				Size:        "L",
+				NMID:        5002,
+				Brand:       "SockMakers",
+				Status:      1,
+				Price:       decimal.NewFromFloat(49.50),
+				Sale:        decimal.NewFromFloat(0.00),
+				TotalPrice:  decimal.NewFromFloat(49.50),
+			},
 		},
+		Locale:          "en-US",
+		Signature:       "sig-example-base64==",
+		CustomerID:      "cust-007",
+		DeliveryService: "DHL",
+		ShardKey:        "shard-1",
+		SMID:            42,
+		CreatedAt:       time.Date(2021, 11, 14, 8, 27, 53, 123456000, time.UTC),
+		OOFShard:        "1",
 	},
-
-	Locale:          "en-US",
-	Signature:       "sig-example-base64==",
-	CustomerID:      "cust-007",
-	DeliveryService: "DHL",
-	ShardKey:        "shard-1",
-	SMID:            42,
-	CreatedAt:       time.Date(2021, 11, 14, 8, 27, 53, 123456000, time.UTC),
-	OOFShard:        "1",
+	Tenant: orders.DefaultTenant,
 }
 
 // no id stated
 var invalidOrder = orders.Order{
-	TrackNumber: "TRK123456789",
-	Entry:       "ENTRY-ABC-1",
-
-	Delivery: orders.Delivery{
-		Name:    "Jane Doe",
-		Phone:   "+358401234567",
-		Zip:     "00100",
-		City:    "Helsinki",
-		Address: "Testintie 1 A 2",
-		Region:  "Uusimaa",
-		Email:   "jane.doe@example.com",
-	},
-
-	Payment: &orders.Payment{
-		Transaction: "txn_20250809_01",
-		RequestID:   "req-2025-08-09-01",
-		Currency:    "EUR",
-		Provider:    "stripe",
-		PaymentDT:   time.Now().Unix(),
-
-		Bank:         "Test Bank Oy",
-		GoodsTotal:   249,
-		DeliveryCost: decimal.NewFromFloat(9.99),
-		CustomFee:    decimal.NewFromFloat(0.00),
-		Amount:       decimal.NewFromFloat(249.49).Add(decimal.NewFromFloat(9.99)),
-	},
-
-	Items: []orders.Item{
-		{
-			CHRTID:      1001,
-			TrackNumber: "ITM-TRK-1",
-			RID:         "rid-1",
-			Name:        "Comfort Sneakers",
-			Size:        "42",
-			NMID:        5001,
-			Brand:       "SneakerCo",
-			Status:      1,
-			Price:       decimal.NewFromFloat(199.99),
-			Sale:        decimal.NewFromFloat(0.00),
-			TotalPrice:  decimal.NewFromFloat(199.99),
+	Order: orderschema.Order{
+		TrackNumber: "TRK123456789",
+		Entry:       "ENTRY-ABC-1",
+		Delivery: orders.Delivery{
+			Name:    "Jane Doe",
+			Phone:   "+358401234567",
+			Zip:     "00100",
+			City:    "Helsinki",
+			Address: "Testintie 1 A 2",
+			Region:  "Uusimaa",
+			Email:   "jane.doe@example.com",
+		},
+		Payment: &orders.Payment{
+			Transaction: "txn_20250809_01",
+			RequestID:   "req-2025-08-09-01",
+			Currency:    "EUR",
+			Provider:    "stripe",
+			PaymentDT:   time.Now().Unix(),
+
+			Bank:         "Test Bank Oy",
+			GoodsTotal:   249,
+			DeliveryCost: decimal.NewFromFloat(9.99),
+			CustomFee:    decimal.NewFromFloat(0.00),
+			Amount:       decimal.NewFromFloat(249.49).Add(decimal.NewFromFloat(9.99)),
 		},
-		{
-			CHRTID:      1002,
-			TrackNumber: "ITM-TRK-2",
-			RID:         "rid-2",
-			Name:        "Everyday Socks (3-pack)",
-			Size:        "L",
-			NMID:        5002,
-			Brand:       "SockMakers",
-			Status:      1,
-			Price:       decimal.NewFromFloat(49.50),
-			Sale:        decimal.NewFromFloat(0.00),
-			TotalPrice:  decimal.NewFromFloat(49.50),
+		Items: []orders.Item{
+			{
+				CHRTID:      1001,
+				TrackNumber: "ITM-TRK-1",
+				RID:         "rid-1",
+				Name:        "Comfort Sneakers",
+				Size:        "42",
+				NMID:        5001,
+				Brand:       "SneakerCo",
+				Status:      1,
+				Price:       decimal.NewFromFloat(199.99),
+				Sale:        decimal.NewFromFloat(0.00),
+				TotalPrice:  decimal.NewFromFloat(199.99),
+			},
+			{
+				CHRTID:      1002,
+				TrackNumber: "ITM-TRK-2",
+				RID:         "rid-2",
+				Name:        "Everyday Socks (3-pack)",
+				Size:        "L",
+				NMID:        5002,
+				Brand:       "SockMakers",
+				Status:      1,
+				Price:       decimal.NewFromFloat(49.50),
+				Sale:        decimal.NewFromFloat(0.00),
+				TotalPrice:  decimal.NewFromFloat(49.50),
+			},
 		},
+		Locale:          "en-US",
+		Signature:       "sig-example-base64==",
+		CustomerID:      "cust-007",
+		DeliveryService: "DHL",
+		ShardKey:        "shard-1",
+		SMID:            42,
+		CreatedAt:       time.Date(2021, 11, 14, 8, 27, 53, 123456000, time.UTC),
+		OOFShard:        "1",
 	},
-
-	Locale:          "en-US",
-	Signature:       "sig-example-base64==",
-	CustomerID:      "cust-007",
-	DeliveryService: "DHL",
-	ShardKey:        "shard-1",
-	SMID:            42,
-	CreatedAt:       time.Date(2021, 11, 14, 8, 27, 53, 123456000, time.UTC),
-	OOFShard:        "1",
 }
@@ -0,0 +1,164 @@
+package kafka
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"order-persistor/internal/config"
+	"order-persistor/internal/invalidation"
+	"time"
+
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+)
+
+var _ invalidation.Channel = &InvalidationChannel{}
+
+const invalidationReadTimeout = time.Second
+
+// InvalidationChannel delivers invalidation events through a compacted kafka topic keyed by order ID.
+// Every replica reads all partitions of the topic on its own, without joining a consumer group.
+type InvalidationChannel struct {
+	producer *kafka.Producer
+	servers  string
+	topic    string
+	origin   string
+}
+
+// NewInvalidationChannel creates the producing side of the channel and ensures the compacted topic exists.
+func NewInvalidationChannel(ctx context.Context, cfg config.InvalidationKafka, origin string) (*InvalidationChannel, error) {
+	p, err := kafka.NewProducer(&kafka.ConfigMap{
+		"bootstrap.servers": cfg.Servers,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	ch := &InvalidationChannel{
+		producer: p,
+		servers:  cfg.Servers,
+		topic:    cfg.Topic,
+		origin:   origin,
+	}
+
+	if err := ch.ensureTopic(ctx); err != nil {
+		p.Close()
+		return nil, fmt.Errorf("could not create invalidation topic: %w", err)
+	}
+
+	return ch, nil
+}
+
+func (c *InvalidationChannel) Publish(ctx context.Context, e invalidation.Event) error {
+	payload, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	delivery := make(chan kafka.Event, 1)
+	err = c.producer.Produce(&kafka.Message{
+		TopicPartition: kafka.TopicPartition{
+			Topic:     &c.topic,
+			Partition: kafka.PartitionAny,
+		},
+		Key:   []byte(e.OrderID),
+		Value: payload,
+	}, delivery)
+	if err != nil {
+		return err
+	}
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case ev := <-delivery:
+		if msg, ok := ev.(*kafka.Message); ok && msg.TopicPartition.Error != nil {
+			return msg.TopicPartition.Error
+		}
+
+		return nil
+	}
+}
+
+// Subscribe assigns all topic partitions starting from their end, so only fresh events are handled.
+func (c *InvalidationChannel) Subscribe(ctx context.Context, handle func(invalidation.Event)) error {
+	consumer, err := kafka.NewConsumer(&kafka.ConfigMap{
+		"bootstrap.servers":  c.servers,
+		"group.id":           "invalidation-" + c.origin,
+		"enable.auto.commit": false,
+	})
+	if err != nil {
+		return err
+	}
+	defer consumer.Close()
+
+	meta, err := consumer.GetMetadata(&c.topic, false, int(invalidationReadTimeout.Milliseconds()))
+	if err != nil {
+		return fmt.Errorf("could not get topic metadata: %w", err)
+	}
+
+	var partitions []kafka.TopicPartition
+	for _, p := range meta.Topics[c.topic].Partitions {
+		partitions = append(partitions, kafka.TopicPartition{
+			Topic:     &c.topic,
+			Partition: p.ID,
+			Offset:    kafka.OffsetEnd,
+		})
+	}
+
+	if err := consumer.Assign(partitions); err != nil {
+		return fmt.Errorf("could not assign partitions: %w", err)
+	}
+
+	for ctx.Err() == nil {
+		msg, err := consumer.ReadMessage(invalidationReadTimeout)
+		if err != nil {
+			if err.(kafka.Error).IsTimeout() {
+				continue
+			}
+
+			return err
+		}
+
+		var e invalidation.Event
+		if err := json.Unmarshal(msg.Value, &e); err != nil {
+			continue
+		}
+
+		handle(e)
+	}
+
+	return ctx.Err()
+}
+
+func (c *InvalidationChannel) Close() {
+	c.producer.Flush(int(invalidationReadTimeout.Milliseconds()))
+	c.producer.Close()
+}
+
+func (c *InvalidationChannel) ensureTopic(ctx context.Context) error {
+	admin, err := kafka.NewAdminClientFromProducer(c.producer)
+	if err != nil {
+		return err
+	}
+	defer admin.Close()
+
+	results, err := admin.CreateTopics(ctx, []kafka.TopicSpecification{{
+		Topic:             c.topic,
+		NumPartitions:     1,
+		ReplicationFactor: 1,
+		Config: map[string]string{
+			"cleanup.policy": "compact",
+		},
+	}})
+	if err != nil {
+		return err
+	}
+
+	for _, res := range results {
+		if res.Error.Code() != kafka.ErrNoError && res.Error.Code() != kafka.ErrTopicAlreadyExists {
+			return res.Error
+		}
+	}
+
+	return nil
+}
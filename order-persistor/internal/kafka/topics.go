@@ -0,0 +1,112 @@
+package kafka
+
+import (
+	"bytes"
+	"cmp"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"order-persistor/internal/config"
+	"order-persistor/internal/orders"
+	"regexp"
+	"strings"
+
+	"github.com/lezzercringe/some-assignment/orderschema"
+)
+
+// maxDecodedSize limits the size of a decompressed message.
+const maxDecodedSize = 16 << 20
+
+// decoders turn a message of the given encoding into a JSON-encoded order.
+var decoders = map[string]func(body []byte) ([]byte, error){
+	"json": func(body []byte) ([]byte, error) {
+		return body, nil
+	},
+	"gzip+json": func(body []byte) ([]byte, error) {
+		r, err := gzip.NewReader(bytes.NewReader(body))
+		if err != nil {
+			return nil, err
+		}
+		defer r.Close()
+
+		decoded, err := io.ReadAll(io.LimitReader(r, maxDecodedSize+1))
+		if err != nil {
+			return nil, err
+		}
+
+		if len(decoded) > maxDecodedSize {
+			return nil, fmt.Errorf("decompressed message exceeds %d bytes", maxDecodedSize)
+		}
+
+		return decoded, nil
+	},
+}
+
+// route holds the settings messages of a topic are processed with.
+type route struct {
+	topic   string
+	pattern *regexp.Regexp
+	tenant  string
+	decode  func(body []byte) ([]byte, error)
+	// schema is nil for the default validation profile
+	schema *orderschema.SchemaValidator
+}
+
+func (r *route) matches(topic string) bool {
+	if r.pattern != nil {
+		return r.pattern.MatchString(topic)
+	}
+
+	return r.topic == topic
+}
+
+// newRoutes creates routes of the configured topics, in the order they are configured.
+// The single topic is routed with the default settings.
+func newRoutes(cfg config.KafkaConsumer) ([]*route, error) {
+	topics := cfg.Topics
+	if cfg.Topic != "" {
+		topics = append([]config.KafkaTopic{{Name: cfg.Topic}}, topics...)
+	}
+
+	defaultProfile := "default"
+	if cfg.Strict {
+		defaultProfile = "strict"
+	}
+
+	routes := make([]*route, 0, len(topics))
+	for _, t := range topics {
+		r := &route{
+			topic:  t.Name,
+			tenant: cmp.Or(t.Tenant, orders.DefaultTenant),
+			decode: decoders[cmp.Or(t.Decoder, "json")],
+		}
+
+		if r.decode == nil {
+			return nil, fmt.Errorf("unknown decoder %q of topic %q", t.Decoder, t.Name)
+		}
+
+		if strings.HasPrefix(t.Name, "^") {
+			var err error
+			r.pattern, err = regexp.Compile(t.Name)
+			if err != nil {
+				return nil, fmt.Errorf("invalid topic pattern %q: %w", t.Name, err)
+			}
+		}
+
+		switch profile := cmp.Or(t.Profile, defaultProfile); profile {
+		case "default":
+		case "schema", "strict":
+			var err error
+			r.schema, err = orderschema.NewSchemaValidator(profile == "strict")
+			if err != nil {
+				return nil, err
+			}
+		default:
+			return nil, fmt.Errorf("unknown validation profile %q of topic %q", profile, t.Name)
+		}
+
+		routes = append(routes, r)
+	}
+
+	return routes, nil
+}
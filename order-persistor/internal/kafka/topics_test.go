@@ -0,0 +1,89 @@
+package kafka
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"order-persistor/internal/config"
+	"order-persistor/internal/mocks"
+	"order-persistor/internal/orders"
+	"order-persistor/internal/redact"
+	"testing"
+
+	"go.uber.org/mock/gomock"
+)
+
+func TestOrdersConsumer_routeOf(t *testing.T) {
+	t.Parallel()
+
+	routes, err := newRoutes(config.KafkaConsumer{
+		Topic: "orders",
+		Topics: []config.KafkaTopic{
+			{Name: "^market-a\\..*", Tenant: "market-a", Profile: "strict"},
+			{Name: "market-b.orders", Tenant: "market-b", Decoder: "gzip+json"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("could not create routes: %v", err)
+	}
+
+	redaction, _ := redact.NewPolicy(redact.Partial, "")
+	consumer := &OrdersConsumer{routes: routes, redaction: redaction, logger: slog.New(slog.DiscardHandler)}
+
+	cases := map[string]struct {
+		tenant string
+		strict bool
+	}{
+		"orders":          {tenant: orders.DefaultTenant},
+		"market-a.orders": {tenant: "market-a", strict: true},
+		"market-a.legacy": {tenant: "market-a", strict: true},
+		"market-b.orders": {tenant: "market-b"},
+	}
+
+	for topic, want := range cases {
+		r := consumer.routeOf(topic)
+		if r.tenant != want.tenant || (r.schema != nil) != want.strict {
+			t.Errorf("topic %s: unexpected route %+v", topic, r)
+		}
+	}
+}
+
+func TestOrdersConsumer_handleMessage_route(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	routes, err := newRoutes(config.KafkaConsumer{
+		Topics: []config.KafkaTopic{{Name: "market-b.orders", Tenant: "market-b", Decoder: "gzip+json"}},
+	})
+	if err != nil {
+		t.Fatalf("could not create routes: %v", err)
+	}
+
+	repository := mocks.NewMockRepository(ctrl)
+	consumer := newTestConsumer(mocks.NewMockClient(ctrl), repository)
+	consumer.routes = routes
+
+	repository.EXPECT().
+		Create(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, o *orders.Order) (*orders.Order, error) {
+			if o.ID != validOrder.ID || o.Tenant != "market-b" {
+				t.Fatalf("unexpected order created: %s of tenant %s", o.ID, o.Tenant)
+			}
+
+			return o, nil
+		}).
+		Times(1)
+
+	var compressed bytes.Buffer
+	gz := gzip.NewWriter(&compressed)
+	_ = json.NewEncoder(gz).Encode(validOrder)
+	_ = gz.Close()
+
+	if err := consumer.handleMessage(context.Background(), consumer.routeOf("market-b.orders"), compressed.Bytes()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,59 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const namespace = "order_persistor"
+
+var (
+	PrefillLoadedOrders = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "cache_prefill",
+		Name:      "loaded_orders",
+		Help:      "Number of orders loaded into the cache by prefill so far.",
+	})
+
+	PrefillTargetOrders = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "cache_prefill",
+		Name:      "target_orders",
+		Help:      "Number of orders prefill aims to load into the cache.",
+	})
+
+	PrefillState = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "cache_prefill",
+		Name:      "state",
+		Help:      "Prefill state: 0 - pending, 1 - running, 2 - done, 3 - failed.",
+	})
+
+	ReplicaHealthy = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "postgres",
+		Name:      "replica_healthy",
+		Help:      "Whether the read replica is considered healthy (1) or not (0).",
+	})
+
+	Reads = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "postgres",
+		Name:      "reads_total",
+		Help:      "Number of read operations by the database they were routed to.",
+	}, []string{"target"})
+
+	RetentionDefaultPartitionOrders = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "retention",
+		Name:      "default_partition_orders_total",
+		Help:      "Number of orders moved out of the default partition into their monthly partitions.",
+	})
+
+	RetentionFailures = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "retention",
+		Name:      "failures_total",
+		Help:      "Number of maintenance runs having a failed step.",
+	})
+)
@@ -0,0 +1,88 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/archive/archiver.go
+//
+// Generated by this command:
+//
+//	mockgen -source internal/archive/archiver.go -destination internal/mocks/archive.go -package mocks -mock_names Repository=MockArchiveRepository
+//
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	orders "order-persistor/internal/orders"
+	reflect "reflect"
+	time "time"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockArchiveRepository is a mock of Repository interface.
+type MockArchiveRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockArchiveRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockArchiveRepositoryMockRecorder is the mock recorder for MockArchiveRepository.
+type MockArchiveRepositoryMockRecorder struct {
+	mock *MockArchiveRepository
+}
+
+// NewMockArchiveRepository creates a new mock instance.
+func NewMockArchiveRepository(ctrl *gomock.Controller) *MockArchiveRepository {
+	mock := &MockArchiveRepository{ctrl: ctrl}
+	mock.recorder = &MockArchiveRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockArchiveRepository) EXPECT() *MockArchiveRepositoryMockRecorder {
+	return m.recorder
+}
+
+// CountBefore mocks base method.
+func (m *MockArchiveRepository) CountBefore(ctx context.Context, before time.Time) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountBefore", ctx, before)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountBefore indicates an expected call of CountBefore.
+func (mr *MockArchiveRepositoryMockRecorder) CountBefore(ctx, before any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountBefore", reflect.TypeOf((*MockArchiveRepository)(nil).CountBefore), ctx, before)
+}
+
+// DeleteByIDs mocks base method.
+func (m *MockArchiveRepository) DeleteByIDs(ctx context.Context, ids []string) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteByIDs", ctx, ids)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteByIDs indicates an expected call of DeleteByIDs.
+func (mr *MockArchiveRepositoryMockRecorder) DeleteByIDs(ctx, ids any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteByIDs", reflect.TypeOf((*MockArchiveRepository)(nil).DeleteByIDs), ctx, ids)
+}
+
+// ListBefore mocks base method.
+func (m *MockArchiveRepository) ListBefore(ctx context.Context, c orders.Cursor, n int) ([]orders.Order, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListBefore", ctx, c, n)
+	ret0, _ := ret[0].([]orders.Order)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListBefore indicates an expected call of ListBefore.
+func (mr *MockArchiveRepositoryMockRecorder) ListBefore(ctx, c, n any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListBefore", reflect.TypeOf((*MockArchiveRepository)(nil).ListBefore), ctx, c, n)
+}
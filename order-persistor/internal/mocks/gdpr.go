@@ -0,0 +1,178 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/gdpr/gdpr.go
+//
+// Generated by this command:
+//
+//	mockgen -source internal/gdpr/gdpr.go -destination internal/mocks/gdpr.go -package mocks -mock_names Repository=MockGDPRRepository,Evictor=MockGDPREvictor,Forgetter=MockGDPRForgetter
+//
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	gdpr "order-persistor/internal/gdpr"
+	orders "order-persistor/internal/orders"
+	reflect "reflect"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockGDPRRepository is a mock of Repository interface.
+type MockGDPRRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockGDPRRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockGDPRRepositoryMockRecorder is the mock recorder for MockGDPRRepository.
+type MockGDPRRepositoryMockRecorder struct {
+	mock *MockGDPRRepository
+}
+
+// NewMockGDPRRepository creates a new mock instance.
+func NewMockGDPRRepository(ctrl *gomock.Controller) *MockGDPRRepository {
+	mock := &MockGDPRRepository{ctrl: ctrl}
+	mock.recorder = &MockGDPRRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockGDPRRepository) EXPECT() *MockGDPRRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Anonymize mocks base method.
+func (m *MockGDPRRepository) Anonymize(ctx context.Context, ids []string, entry gdpr.AuditEntry) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Anonymize", ctx, ids, entry)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Anonymize indicates an expected call of Anonymize.
+func (mr *MockGDPRRepositoryMockRecorder) Anonymize(ctx, ids, entry any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Anonymize", reflect.TypeOf((*MockGDPRRepository)(nil).Anonymize), ctx, ids, entry)
+}
+
+// ListByCustomerID mocks base method.
+func (m *MockGDPRRepository) ListByCustomerID(ctx context.Context, customerID string, n int) ([]orders.Order, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListByCustomerID", ctx, customerID, n)
+	ret0, _ := ret[0].([]orders.Order)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListByCustomerID indicates an expected call of ListByCustomerID.
+func (mr *MockGDPRRepositoryMockRecorder) ListByCustomerID(ctx, customerID, n any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListByCustomerID", reflect.TypeOf((*MockGDPRRepository)(nil).ListByCustomerID), ctx, customerID, n)
+}
+
+// ListByEmail mocks base method.
+func (m *MockGDPRRepository) ListByEmail(ctx context.Context, email string, n int) ([]orders.Order, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListByEmail", ctx, email, n)
+	ret0, _ := ret[0].([]orders.Order)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListByEmail indicates an expected call of ListByEmail.
+func (mr *MockGDPRRepositoryMockRecorder) ListByEmail(ctx, email, n any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListByEmail", reflect.TypeOf((*MockGDPRRepository)(nil).ListByEmail), ctx, email, n)
+}
+
+// RecordAudit mocks base method.
+func (m *MockGDPRRepository) RecordAudit(ctx context.Context, entry gdpr.AuditEntry) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecordAudit", ctx, entry)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RecordAudit indicates an expected call of RecordAudit.
+func (mr *MockGDPRRepositoryMockRecorder) RecordAudit(ctx, entry any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordAudit", reflect.TypeOf((*MockGDPRRepository)(nil).RecordAudit), ctx, entry)
+}
+
+// MockGDPREvictor is a mock of Evictor interface.
+type MockGDPREvictor struct {
+	ctrl     *gomock.Controller
+	recorder *MockGDPREvictorMockRecorder
+	isgomock struct{}
+}
+
+// MockGDPREvictorMockRecorder is the mock recorder for MockGDPREvictor.
+type MockGDPREvictorMockRecorder struct {
+	mock *MockGDPREvictor
+}
+
+// NewMockGDPREvictor creates a new mock instance.
+func NewMockGDPREvictor(ctrl *gomock.Controller) *MockGDPREvictor {
+	mock := &MockGDPREvictor{ctrl: ctrl}
+	mock.recorder = &MockGDPREvictorMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockGDPREvictor) EXPECT() *MockGDPREvictorMockRecorder {
+	return m.recorder
+}
+
+// Evict mocks base method.
+func (m *MockGDPREvictor) Evict(id string) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Evict", id)
+}
+
+// Evict indicates an expected call of Evict.
+func (mr *MockGDPREvictorMockRecorder) Evict(id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Evict", reflect.TypeOf((*MockGDPREvictor)(nil).Evict), id)
+}
+
+// MockGDPRForgetter is a mock of Forgetter interface.
+type MockGDPRForgetter struct {
+	ctrl     *gomock.Controller
+	recorder *MockGDPRForgetterMockRecorder
+	isgomock struct{}
+}
+
+// MockGDPRForgetterMockRecorder is the mock recorder for MockGDPRForgetter.
+type MockGDPRForgetterMockRecorder struct {
+	mock *MockGDPRForgetter
+}
+
+// NewMockGDPRForgetter creates a new mock instance.
+func NewMockGDPRForgetter(ctrl *gomock.Controller) *MockGDPRForgetter {
+	mock := &MockGDPRForgetter{ctrl: ctrl}
+	mock.recorder = &MockGDPRForgetterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockGDPRForgetter) EXPECT() *MockGDPRForgetterMockRecorder {
+	return m.recorder
+}
+
+// Forget mocks base method.
+func (m *MockGDPRForgetter) Forget(ids ...string) {
+	m.ctrl.T.Helper()
+	varargs := []any{}
+	for _, a := range ids {
+		varargs = append(varargs, a)
+	}
+	m.ctrl.Call(m, "Forget", varargs...)
+}
+
+// Forget indicates an expected call of Forget.
+func (mr *MockGDPRForgetterMockRecorder) Forget(ids ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Forget", reflect.TypeOf((*MockGDPRForgetter)(nil).Forget), ids...)
+}
@@ -85,16 +85,16 @@ func (mr *MockClientMockRecorder) ReadMessage(timeout any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadMessage", reflect.TypeOf((*MockClient)(nil).ReadMessage), timeout)
 }
 
-// Subscribe mocks base method.
-func (m *MockClient) Subscribe(topic string, rebalanceCb kafka.RebalanceCb) error {
+// SubscribeTopics mocks base method.
+func (m *MockClient) SubscribeTopics(topics []string, rebalanceCb kafka.RebalanceCb) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Subscribe", topic, rebalanceCb)
+	ret := m.ctrl.Call(m, "SubscribeTopics", topics, rebalanceCb)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// Subscribe indicates an expected call of Subscribe.
-func (mr *MockClientMockRecorder) Subscribe(topic, rebalanceCb any) *gomock.Call {
+// SubscribeTopics indicates an expected call of SubscribeTopics.
+func (mr *MockClientMockRecorder) SubscribeTopics(topics, rebalanceCb any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Subscribe", reflect.TypeOf((*MockClient)(nil).Subscribe), topic, rebalanceCb)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SubscribeTopics", reflect.TypeOf((*MockClient)(nil).SubscribeTopics), topics, rebalanceCb)
 }
@@ -71,6 +71,21 @@ func (mr *MockRepositoryMockRecorder) GetByID(ctx, id any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByID", reflect.TypeOf((*MockRepository)(nil).GetByID), ctx, id)
 }
 
+// ListBefore mocks base method.
+func (m *MockRepository) ListBefore(ctx context.Context, c orders.Cursor, n int) ([]orders.Order, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListBefore", ctx, c, n)
+	ret0, _ := ret[0].([]orders.Order)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListBefore indicates an expected call of ListBefore.
+func (mr *MockRepositoryMockRecorder) ListBefore(ctx, c, n any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListBefore", reflect.TypeOf((*MockRepository)(nil).ListBefore), ctx, c, n)
+}
+
 // ListRecent mocks base method.
 func (m *MockRepository) ListRecent(ctx context.Context, n int) ([]orders.Order, error) {
 	m.ctrl.T.Helper()
@@ -0,0 +1,100 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/retention/job.go
+//
+// Generated by this command:
+//
+//	mockgen -source internal/retention/job.go -destination internal/mocks/retention.go -package mocks
+//
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockPartitions is a mock of Partitions interface.
+type MockPartitions struct {
+	ctrl     *gomock.Controller
+	recorder *MockPartitionsMockRecorder
+	isgomock struct{}
+}
+
+// MockPartitionsMockRecorder is the mock recorder for MockPartitions.
+type MockPartitionsMockRecorder struct {
+	mock *MockPartitions
+}
+
+// NewMockPartitions creates a new mock instance.
+func NewMockPartitions(ctrl *gomock.Controller) *MockPartitions {
+	mock := &MockPartitions{ctrl: ctrl}
+	mock.recorder = &MockPartitionsMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPartitions) EXPECT() *MockPartitionsMockRecorder {
+	return m.recorder
+}
+
+// CreateAhead mocks base method.
+func (m *MockPartitions) CreateAhead(ctx context.Context, from time.Time, months int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAhead", ctx, from, months)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateAhead indicates an expected call of CreateAhead.
+func (mr *MockPartitionsMockRecorder) CreateAhead(ctx, from, months any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAhead", reflect.TypeOf((*MockPartitions)(nil).CreateAhead), ctx, from, months)
+}
+
+// Detach mocks base method.
+func (m *MockPartitions) Detach(ctx context.Context, month time.Time, drop bool) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Detach", ctx, month, drop)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Detach indicates an expected call of Detach.
+func (mr *MockPartitionsMockRecorder) Detach(ctx, month, drop any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Detach", reflect.TypeOf((*MockPartitions)(nil).Detach), ctx, month, drop)
+}
+
+// DrainDefault mocks base method.
+func (m *MockPartitions) DrainDefault(ctx context.Context) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DrainDefault", ctx)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DrainDefault indicates an expected call of DrainDefault.
+func (mr *MockPartitionsMockRecorder) DrainDefault(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DrainDefault", reflect.TypeOf((*MockPartitions)(nil).DrainDefault), ctx)
+}
+
+// ListMonths mocks base method.
+func (m *MockPartitions) ListMonths(ctx context.Context) ([]time.Time, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListMonths", ctx)
+	ret0, _ := ret[0].([]time.Time)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListMonths indicates an expected call of ListMonths.
+func (mr *MockPartitionsMockRecorder) ListMonths(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListMonths", reflect.TypeOf((*MockPartitions)(nil).ListMonths), ctx)
+}
@@ -1,20 +1,41 @@
 package orders
 
-import "time"
+import (
+	"time"
 
+	"github.com/lezzercringe/some-assignment/orderschema"
+)
+
+// Order is the order as it is stored: the shared schema along with the fields internal to the persistor.
+// It is encoded to JSON exactly as the shared schema.
 type Order struct {
-	ID              string    `json:"order_uid" validate:"required"`
-	TrackNumber     string    `json:"track_number" validate:"required"`
-	Entry           string    `json:"entry" validate:"required"`
-	Delivery        Delivery  `json:"delivery"`
-	Payment         *Payment  `json:"payment"`
-	Items           []Item    `json:"items"`
-	Locale          string    `json:"locale" validate:"required"`
-	Signature       string    `json:"internal_signature"`
-	CustomerID      string    `json:"customer_id" validate:"required"`
-	DeliveryService string    `json:"delivery_service" validate:"required"`
-	ShardKey        string    `json:"shardkey" validate:"required"`
-	SMID            int       `json:"sm_id" validate:"required"`
-	CreatedAt       time.Time `json:"date_created" validate:"required"`
-	OOFShard        string    `json:"oof_shard" validate:"required,numeric"`
+	orderschema.Order
+	// Tenant is the marketplace the order belongs to. It is assigned by the consumer
+	// and is never read from or written to messages.
+	Tenant string `json:"-"`
+	// AnonymizedAt is when personal data of the order was erased, zero if it was not.
+	AnonymizedAt time.Time `json:"-"`
+	// Sealed is set if the delivery name, phone, address and email were read encrypted, as they are stored.
+	Sealed *SealedDelivery `json:"-"`
+}
+
+// SealedDelivery is the encryption of the delivery data: the data key, encrypted with the key encryption key of the ID.
+type SealedDelivery struct {
+	KeyID   string `json:"key_id"`
+	DataKey []byte `json:"data_key"`
 }
+
+// ModifiedAt returns when the order was last changed: created or, later, anonymized.
+func (o *Order) ModifiedAt() time.Time {
+	if o.AnonymizedAt.After(o.CreatedAt) {
+		return o.AnonymizedAt
+	}
+
+	return o.CreatedAt
+}
+
+type (
+	Delivery = orderschema.Delivery
+	Payment  = orderschema.Payment
+	Item     = orderschema.Item
+)
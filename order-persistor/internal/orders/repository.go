@@ -3,15 +3,33 @@ package orders
 import (
 	"context"
 	"errors"
+	"time"
 )
 
 var (
 	ErrInternalFailure = errors.New("internal db failure")
 	ErrNotFound        = errors.New("not found")
+	ErrAlreadyExists   = errors.New("already exists")
 )
 
+// Cursor points at an order in the feed sorted by creation date, most fresh first.
+type Cursor struct {
+	CreatedAt time.Time
+	ID        string
+}
+
+// CursorOf returns the cursor pointing at the given order.
+func CursorOf(o *Order) Cursor {
+	return Cursor{
+		CreatedAt: o.CreatedAt,
+		ID:        o.ID,
+	}
+}
+
 type Repository interface {
 	GetByID(ctx context.Context, id string) (*Order, error)
 	ListRecent(ctx context.Context, n int) ([]Order, error)
+	// ListBefore returns up to n most fresh orders placed strictly after the cursor in the feed.
+	ListBefore(ctx context.Context, c Cursor, n int) ([]Order, error)
 	Create(ctx context.Context, o *Order) (*Order, error)
 }
@@ -0,0 +1,19 @@
+package orders
+
+import "context"
+
+// DefaultTenant owns the orders consumed from topics without a configured tenant.
+const DefaultTenant = "default"
+
+type tenantKey struct{}
+
+// WithTenant limits the repository reads made with the returned context to the orders of the tenant.
+func WithTenant(ctx context.Context, tenant string) context.Context {
+	return context.WithValue(ctx, tenantKey{}, tenant)
+}
+
+// TenantFrom returns the tenant reads are limited to. Reads span all the tenants if there is none.
+func TenantFrom(ctx context.Context) (string, bool) {
+	tenant, ok := ctx.Value(tenantKey{}).(string)
+	return tenant, ok
+}
@@ -0,0 +1,12 @@
+package orders
+
+import (
+	"context"
+
+	"github.com/lezzercringe/some-assignment/orderschema"
+)
+
+// Validate checks the order against the rules every persisted order has to satisfy.
+func Validate(ctx context.Context, o *Order) error {
+	return orderschema.Validate(ctx, &o.Order)
+}
@@ -0,0 +1,133 @@
+package postgres
+
+import (
+	"context"
+	"order-persistor/internal/orders"
+	"order-persistor/internal/postgres/sqlc"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// storedDelivery is the delivery data of an order as it is stored, encrypted or not.
+type storedDelivery struct {
+	Name       string
+	Phone      string
+	Address    string
+	Email      string
+	KeyID      pgtype.Text
+	DataKey    []byte
+	EmailIndex pgtype.Text
+}
+
+// encryptDelivery encrypts the delivery data of the order with a new data key, if encryption is configured.
+func (r *OrdersRepository) encryptDelivery(ctx context.Context, o *orders.Order) (*storedDelivery, error) {
+	if r.Cipher == nil {
+		return &storedDelivery{
+			Name:    o.Delivery.Name,
+			Phone:   o.Delivery.Phone,
+			Address: o.Delivery.Address,
+			Email:   o.Delivery.Email,
+		}, nil
+	}
+
+	sealed, err := r.Cipher.Encrypt(ctx, o.ID, o.Delivery.Name, o.Delivery.Phone, o.Delivery.Address, o.Delivery.Email)
+	if err != nil {
+		return nil, err
+	}
+
+	emailIndex := r.Cipher.BlindIndex(o.Delivery.Email)
+	return &storedDelivery{
+		Name:       sealed.Values[0],
+		Phone:      sealed.Values[1],
+		Address:    sealed.Values[2],
+		Email:      sealed.Values[3],
+		KeyID:      pgtype.Text{String: sealed.KeyID, Valid: true},
+		DataKey:    sealed.DataKey,
+		EmailIndex: pgtype.Text{String: emailIndex, Valid: emailIndex != ""},
+	}, nil
+}
+
+// ListByEmail returns the latest n orders delivered to the email, compared case-insensitively when encryption is enabled.
+// With encryption enabled the orders stored in plain text, not re-encrypted yet, are found as well.
+// Orders are read from the primary, since data subject requests must not miss the latest ones.
+func (r *OrdersRepository) ListByEmail(ctx context.Context, email string, n int) ([]orders.Order, error) {
+	ctx = WithPrimary(ctx)
+
+	return r.list(ctx, func(q *sqlc.Queries) ([]sqlc.Order, error) {
+		if r.Cipher != nil {
+			return q.GetOrdersByEmailIndex(ctx, sqlc.GetOrdersByEmailIndexParams{
+				EmailIndex: r.Cipher.BlindIndex(email),
+				Email:      email,
+				Tenant:     tenantParam(ctx),
+				N:          int32(n),
+			})
+		}
+
+		return q.GetOrdersByEmail(ctx, sqlc.GetOrdersByEmailParams{
+			Email:  email,
+			Tenant: tenantParam(ctx),
+			N:      int32(n),
+		})
+	})
+}
+
+// ReencryptBatch encrypts the delivery data of up to n orders following the cursor in ascending order
+// with the current key, including orders stored in plain text. Unless all is set, orders already encrypted
+// with the current key are skipped. It returns the cursor of the last processed order and the number of processed orders,
+// which is less than n only when there are no more orders.
+func (r *OrdersRepository) ReencryptBatch(ctx context.Context, after orders.Cursor, n int, all bool) (orders.Cursor, int, error) {
+	var processed int
+
+	err := withTx(ctx, r.Pool, func(ctx context.Context) error {
+		q := sqlc.New(ctx.Value(txKey{}).(pgx.Tx))
+		dtos, err := q.GetOrdersToReencrypt(ctx, sqlc.GetOrdersToReencryptParams{
+			DateCreated: after.CreatedAt,
+			ID:          after.ID,
+			AllKeys:     all,
+			KeyID:       r.Cipher.CurrentKeyID(),
+			N:           int32(n),
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, dto := range dtos {
+			order, err := r.mapDtoToOrder(ctx, dto)
+			if err != nil {
+				return err
+			}
+
+			delivery, err := r.encryptDelivery(ctx, order)
+			if err != nil {
+				return err
+			}
+
+			err = q.UpdateOrderDelivery(ctx, sqlc.UpdateOrderDeliveryParams{
+				DeliveryName:       delivery.Name,
+				DeliveryPhone:      delivery.Phone,
+				DeliveryAddress:    delivery.Address,
+				DeliveryEmail:      delivery.Email,
+				DeliveryKeyID:      delivery.KeyID,
+				DeliveryDataKey:    delivery.DataKey,
+				DeliveryEmailIndex: delivery.EmailIndex,
+				ID:                 order.ID,
+				DateCreated:        order.CreatedAt,
+			})
+			if err != nil {
+				return err
+			}
+
+			after = orders.CursorOf(order)
+		}
+
+		processed = len(dtos)
+		return nil
+	})
+
+	if err != nil {
+		return orders.Cursor{}, 0, describeError(err)
+	}
+
+	return after, processed, nil
+}
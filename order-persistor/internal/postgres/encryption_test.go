@@ -0,0 +1,73 @@
+package postgres
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"order-persistor/internal/envelope"
+	"order-persistor/internal/postgres/sqlc"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+func TestMapDtoToOrder_encrypted(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	key := base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{1}, 32))
+	path := filepath.Join(t.TempDir(), "keys.yaml")
+	content := "current: \"2026-10\"\nkeys:\n  \"2026-10\": " + key + "\nindex_key: " + key + "\n"
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	keys, err := envelope.LoadKeyFile(path)
+	if err != nil {
+		t.Fatalf("could not load keys: %v", err)
+	}
+
+	cipher := envelope.NewCipher(keys, keys.IndexKey())
+	sealed, err := cipher.Encrypt(ctx, "b563feb7b2b84b6test", "Test Testov", "+9720000000", "Ploshad Mira 15", "test@gmail.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dto := sqlc.Order{
+		ID:              "b563feb7b2b84b6test",
+		DeliveryName:    sealed.Values[0],
+		DeliveryPhone:   sealed.Values[1],
+		DeliveryAddress: sealed.Values[2],
+		DeliveryEmail:   sealed.Values[3],
+		DeliveryKeyID:   pgtype.Text{String: sealed.KeyID, Valid: true},
+		DeliveryDataKey: sealed.DataKey,
+	}
+
+	t.Run("decrypted", func(t *testing.T) {
+		order, err := (&OrdersRepository{Cipher: cipher}).mapDtoToOrder(ctx, dto)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if order.Delivery.Name != "Test Testov" || order.Delivery.Email != "test@gmail.com" || order.Sealed != nil {
+			t.Errorf("expected decrypted delivery, got %+v sealed with %+v", order.Delivery, order.Sealed)
+		}
+	})
+
+	t.Run("kept encrypted", func(t *testing.T) {
+		order, err := (&OrdersRepository{Cipher: cipher, KeepEncrypted: true}).mapDtoToOrder(ctx, dto)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if order.Delivery.Name != sealed.Values[0] || order.Delivery.Email != sealed.Values[3] {
+			t.Errorf("expected delivery as stored, got %+v", order.Delivery)
+		}
+
+		if order.Sealed == nil || order.Sealed.KeyID != sealed.KeyID || !bytes.Equal(order.Sealed.DataKey, sealed.DataKey) {
+			t.Errorf("expected encryption of delivery, got %+v", order.Sealed)
+		}
+	})
+}
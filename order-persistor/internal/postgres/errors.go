@@ -18,11 +18,15 @@ func describeError(err error) error {
 		return orders.ErrNotFound
 	}
 
+	if errors.Is(err, orders.ErrAlreadyExists) {
+		return err
+	}
+
 	var pgErr *pgconn.PgError
 	if errors.As(err, &pgErr) {
 		switch pgErr.Code {
 		case "23505":
-			return fmt.Errorf("already exists (unique violation): %w", err)
+			return fmt.Errorf("%w (unique violation): %w", orders.ErrAlreadyExists, err)
 		case "23514":
 			return fmt.Errorf("validation failed (check violation): %w", err)
 		case "23502":
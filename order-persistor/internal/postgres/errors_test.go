@@ -0,0 +1,36 @@
+package postgres
+
+import (
+	"errors"
+	"fmt"
+	"order-persistor/internal/orders"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+func TestDescribeError(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		err  error
+		want error
+	}{
+		{name: "no rows", err: fmt.Errorf("query: %w", pgx.ErrNoRows), want: orders.ErrNotFound},
+		{name: "id taken", err: orders.ErrAlreadyExists, want: orders.ErrAlreadyExists},
+		{name: "unique violation", err: &pgconn.PgError{Code: "23505"}, want: orders.ErrAlreadyExists},
+		{name: "connection failure", err: errors.New("connection refused"), want: orders.ErrInternalFailure},
+	}
+
+	for _, tt := range tests {
+		if got := describeError(tt.err); !errors.Is(got, tt.want) {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.want, got)
+		}
+	}
+
+	if err := describeError(&pgconn.PgError{Code: "23505"}); errors.Is(err, orders.ErrInternalFailure) {
+		t.Errorf("expected unique violation not to be internal failure, got %v", err)
+	}
+}
@@ -0,0 +1,59 @@
+package postgres
+
+import (
+	"context"
+	"order-persistor/internal/gdpr"
+	"order-persistor/internal/orders"
+	"order-persistor/internal/postgres/sqlc"
+)
+
+var _ gdpr.Repository = &OrdersRepository{}
+
+// ListByCustomerID returns the latest n orders of the customer.
+// Orders are read from the primary, since data subject requests must not miss the latest ones.
+func (r *OrdersRepository) ListByCustomerID(ctx context.Context, customerID string, n int) ([]orders.Order, error) {
+	ctx = WithPrimary(ctx)
+
+	return r.list(ctx, func(q *sqlc.Queries) ([]sqlc.Order, error) {
+		return q.GetOrdersByCustomerID(ctx, sqlc.GetOrdersByCustomerIDParams{
+			CustomerID: customerID,
+			Tenant:     tenantParam(ctx),
+			N:          int32(n),
+		})
+	})
+}
+
+// Anonymize erases delivery personal data of the orders, recording the audit entry in the same transaction.
+func (r *OrdersRepository) Anonymize(ctx context.Context, ids []string, entry gdpr.AuditEntry) (int, error) {
+	var anonymized int64
+
+	err := withTx(ctx, r.Pool, func(ctx context.Context) error {
+		var err error
+		anonymized, err = sqlc.New(extractExecutor(ctx, r.Pool)).AnonymizeOrders(ctx, ids)
+		if err != nil {
+			return err
+		}
+
+		return r.recordAudit(ctx, entry)
+	})
+
+	if err != nil {
+		return 0, describeError(err)
+	}
+
+	return int(anonymized), nil
+}
+
+func (r *OrdersRepository) RecordAudit(ctx context.Context, entry gdpr.AuditEntry) error {
+	return describeError(r.recordAudit(ctx, entry))
+}
+
+func (r *OrdersRepository) recordAudit(ctx context.Context, entry gdpr.AuditEntry) error {
+	return sqlc.New(extractExecutor(ctx, r.Pool)).CreateGDPRAuditEntry(ctx, sqlc.CreateGDPRAuditEntryParams{
+		Action:      entry.Action,
+		SubjectType: entry.SubjectType,
+		SubjectHash: entry.SubjectHash,
+		Actor:       entry.Actor,
+		OrderIds:    entry.OrderIDs,
+	})
+}
@@ -0,0 +1,61 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"order-persistor/internal/invalidation"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var _ invalidation.Channel = &InvalidationChannel{}
+
+// InvalidationChannel delivers invalidation events through postgres LISTEN/NOTIFY.
+type InvalidationChannel struct {
+	Pool    *pgxpool.Pool
+	Channel string
+}
+
+func (c *InvalidationChannel) Publish(ctx context.Context, e invalidation.Event) error {
+	payload, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	if _, err := c.Pool.Exec(ctx, "SELECT pg_notify($1, $2)", c.Channel, string(payload)); err != nil {
+		return fmt.Errorf("could not notify: %w", err)
+	}
+
+	return nil
+}
+
+// Subscribe holds a dedicated pool connection listening on the channel until ctx is done.
+func (c *InvalidationChannel) Subscribe(ctx context.Context, handle func(invalidation.Event)) error {
+	pooled, err := c.Pool.Acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("could not acquire connection: %w", err)
+	}
+	// connection is closed rather than released, so it is not returned to the pool still listening
+	conn := pooled.Hijack()
+	defer conn.Close(context.Background())
+
+	if _, err := conn.Exec(ctx, "LISTEN "+pgx.Identifier{c.Channel}.Sanitize()); err != nil {
+		return fmt.Errorf("could not listen: %w", err)
+	}
+
+	for {
+		n, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return fmt.Errorf("waiting for notification: %w", err)
+		}
+
+		var e invalidation.Event
+		if err := json.Unmarshal([]byte(n.Payload), &e); err != nil {
+			continue
+		}
+
+		handle(e)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"order-persistor/internal/orders"
 	"order-persistor/internal/postgres/sqlc"
+	"time"
 
 	"github.com/jackc/pgx/v5/pgxpool"
 )
@@ -12,7 +13,8 @@ type ItemsDAO struct {
 	Pool *pgxpool.Pool
 }
 
-func (r *ItemsDAO) Create(ctx context.Context, orderID string, i *orders.Item) (*orders.Item, error) {
+// Create inserts the item of the order, orderCreatedAt is needed to place the item into the partition of its order.
+func (r *ItemsDAO) Create(ctx context.Context, orderID string, orderCreatedAt time.Time, i *orders.Item) (*orders.Item, error) {
 	exec := extractExecutor(ctx, r.Pool)
 	item, err := sqlc.New(exec).CreateItem(ctx, sqlc.CreateItemParams{
 		OrderID:     orderID,
@@ -27,6 +29,7 @@ func (r *ItemsDAO) Create(ctx context.Context, orderID string, i *orders.Item) (
 		NmID:        int32(i.NMID),
 		Brand:       i.Brand,
 		Status:      int32(i.Status),
+		DateCreated: orderCreatedAt,
 	})
 
 	if err != nil {
@@ -36,22 +39,28 @@ func (r *ItemsDAO) Create(ctx context.Context, orderID string, i *orders.Item) (
 	return mapDtoToItem(item), nil
 }
 
-func (r *ItemsDAO) GetByOrderID(ctx context.Context, orderID string) ([]orders.Item, error) {
+// GetByOrderIDs loads items of all the given orders at once, grouping them by order ID.
+func (r *ItemsDAO) GetByOrderIDs(ctx context.Context, orderIDs []string) (map[string][]orders.Item, error) {
 	exec := extractExecutor(ctx, r.Pool)
-	dtos, err := sqlc.New(exec).GetItemsByOrderID(ctx, orderID)
+	dtos, err := sqlc.New(exec).GetItemsByOrderIDs(ctx, orderIDs)
 
 	if err != nil {
 		return nil, err
 	}
 
-	items := make([]orders.Item, 0, len(dtos))
+	items := make(map[string][]orders.Item, len(orderIDs))
 	for _, dto := range dtos {
-		items = append(items, *mapDtoToItem(dto))
+		items[dto.OrderID] = append(items[dto.OrderID], *mapDtoToItem(dto))
 	}
 
 	return items, nil
 }
 
+func (r *ItemsDAO) DeleteByOrderIDs(ctx context.Context, orderIDs []string) error {
+	exec := extractExecutor(ctx, r.Pool)
+	return sqlc.New(exec).DeleteItemsByOrderIDs(ctx, orderIDs)
+}
+
 func mapDtoToItem(i sqlc.Item) *orders.Item {
 	return &orders.Item{
 		CHRTID:      int(i.ChrtID),
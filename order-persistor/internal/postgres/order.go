@@ -1,12 +1,18 @@
 package postgres
 
 import (
+	"cmp"
 	"context"
+	"fmt"
+	"order-persistor/internal/envelope"
 	"order-persistor/internal/orders"
 	"order-persistor/internal/postgres/sqlc"
+	"time"
 
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/lezzercringe/some-assignment/orderschema"
 )
 
 var _ orders.Repository = &OrdersRepository{}
@@ -15,25 +21,48 @@ type OrdersRepository struct {
 	ItemsDAO    *ItemsDAO
 	PaymentsDAO *PaymentsDAO
 	Pool        *pgxpool.Pool
+	// Replica serves reads if set, writes always go to Pool.
+	Replica *Replica
+	// Cipher encrypts delivery name, phone, address and email if set, otherwise they are stored in plain text.
+	// Orders stored in plain text are read regardless of it.
+	Cipher *envelope.Cipher
+	// KeepEncrypted leaves the delivery data of encrypted orders as it is stored on reads, along with its
+	// encryption, for the reads which must not expose it, like archiving.
+	KeepEncrypted bool
 }
 
 func (r *OrdersRepository) Create(ctx context.Context, order *orders.Order) (*orders.Order, error) {
 	inserted := &orders.Order{}
 
 	err := withTx(ctx, r.Pool, func(ctx context.Context) error {
-		var err error
+		// orders are partitioned by creation date, so primary key does not guarantee id uniqueness on its own:
+		// concurrent writers of the same id are serialized until commit for the check below to hold
+		q := sqlc.New(extractExecutor(ctx, r.Pool))
+		if err := q.LockOrderID(ctx, order.ID); err != nil {
+			return err
+		}
+
+		exists, err := q.OrderExists(ctx, order.ID)
+		if err != nil {
+			return err
+		}
+
+		if exists {
+			return orders.ErrAlreadyExists
+		}
+
 		inserted, err = r.insertOrder(ctx, order)
 		if err != nil {
 			return err
 		}
 
-		inserted.Items, err = r.insertItems(ctx, order.ID, order.Items)
+		inserted.Items, err = r.insertItems(ctx, order.ID, order.CreatedAt, order.Items)
 		if err != nil {
 			return err
 		}
 
 		if order.Payment != nil {
-			inserted.Payment, err = r.PaymentsDAO.Create(ctx, order.ID, order.Payment)
+			inserted.Payment, err = r.PaymentsDAO.Create(ctx, order.ID, order.CreatedAt, order.Payment)
 			if err != nil {
 				return err
 			}
@@ -49,19 +78,52 @@ func (r *OrdersRepository) Create(ctx context.Context, order *orders.Order) (*or
 	return inserted, nil
 }
 
+// CreateBatch inserts the orders in a single transaction, isolating each of them in a savepoint.
+// Failure of a single order is reported at its index in the returned slice and does not affect the others,
+// while the error is only returned if the whole batch could not be committed.
+func (r *OrdersRepository) CreateBatch(ctx context.Context, batch []orders.Order) ([]error, error) {
+	errs := make([]error, len(batch))
+
+	err := withTx(ctx, r.Pool, func(ctx context.Context) error {
+		for i := range batch {
+			_, errs[i] = r.Create(ctx, &batch[i])
+
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, describeError(err)
+	}
+
+	return errs, nil
+}
+
 func (r *OrdersRepository) GetByID(ctx context.Context, id string) (*orders.Order, error) {
 	var order orders.Order
 
-	err := withTx(ctx, r.Pool, func(ctx context.Context) error {
+	// order might be missing on the replica due to replication lag, so absence is double-checked on the primary
+	err := r.read(ctx, func(ctx context.Context) error {
 		tx := ctx.Value(txKey{}).(pgx.Tx)
-		var err error
-		dto, err := sqlc.New(tx).GetOrderByID(ctx, id)
+		dto, err := sqlc.New(tx).GetOrderByID(ctx, sqlc.GetOrderByIDParams{
+			ID:     id,
+			Tenant: tenantParam(ctx),
+		})
 		if err != nil {
 			return err
 		}
 
-		order = *mapDtoToOrder(dto)
-		return r.assembleOrder(ctx, &order)
+		assembled, err := r.assembleOrders(ctx, []sqlc.Order{dto})
+		if err != nil {
+			return err
+		}
+
+		order = assembled[0]
+		return nil
 	})
 
 	if err != nil {
@@ -72,22 +134,81 @@ func (r *OrdersRepository) GetByID(ctx context.Context, id string) (*orders.Orde
 }
 
 func (r *OrdersRepository) ListRecent(ctx context.Context, n int) ([]orders.Order, error) {
-	var orders []orders.Order
+	return r.list(ctx, func(q *sqlc.Queries) ([]sqlc.Order, error) {
+		return q.GetRecentOrders(ctx, sqlc.GetRecentOrdersParams{
+			Tenant: tenantParam(ctx),
+			N:      int32(n),
+		})
+	})
+}
+
+func (r *OrdersRepository) ListBefore(ctx context.Context, c orders.Cursor, n int) ([]orders.Order, error) {
+	return r.list(ctx, func(q *sqlc.Queries) ([]sqlc.Order, error) {
+		return q.GetOrdersBefore(ctx, sqlc.GetOrdersBeforeParams{
+			DateCreated: c.CreatedAt,
+			ID:          c.ID,
+			Tenant:      tenantParam(ctx),
+			N:           int32(n),
+		})
+	})
+}
+
+// CountBefore counts orders created strictly before the given moment.
+func (r *OrdersRepository) CountBefore(ctx context.Context, before time.Time) (int, error) {
+	var count int64
 
 	err := withTx(ctx, r.Pool, func(ctx context.Context) error {
 		tx := ctx.Value(txKey{}).(pgx.Tx)
-		dtos, err := sqlc.New(tx).GetRecentOrders(ctx, int32(n))
-		if err != nil {
+		var err error
+		count, err = sqlc.New(tx).CountOrdersBefore(ctx, before)
+		return err
+	})
+
+	if err != nil {
+		return 0, describeError(err)
+	}
+
+	return int(count), nil
+}
+
+// DeleteByIDs deletes orders along with their items and payments, returning the number of deleted orders.
+func (r *OrdersRepository) DeleteByIDs(ctx context.Context, ids []string) (int, error) {
+	var deleted int64
+
+	err := withTx(ctx, r.Pool, func(ctx context.Context) error {
+		if err := r.ItemsDAO.DeleteByOrderIDs(ctx, ids); err != nil {
+			return err
+		}
+
+		if err := r.PaymentsDAO.DeleteByOrderIDs(ctx, ids); err != nil {
 			return err
 		}
 
-		for _, dto := range dtos {
-			order := *mapDtoToOrder(dto)
-			r.assembleOrder(ctx, &order)
-			orders = append(orders, order)
+		var err error
+		deleted, err = sqlc.New(extractExecutor(ctx, r.Pool)).DeleteOrdersByIDs(ctx, ids)
+		return err
+	})
+
+	if err != nil {
+		return 0, describeError(err)
+	}
+
+	return int(deleted), nil
+}
+
+// list loads the orders selected by query along with their items and payments in three queries total.
+func (r *OrdersRepository) list(ctx context.Context, query func(q *sqlc.Queries) ([]sqlc.Order, error)) ([]orders.Order, error) {
+	var orders []orders.Order
+
+	err := r.read(ctx, func(ctx context.Context) error {
+		tx := ctx.Value(txKey{}).(pgx.Tx)
+		dtos, err := query(sqlc.New(tx))
+		if err != nil {
+			return err
 		}
 
-		return nil
+		orders, err = r.assembleOrders(ctx, dtos)
+		return err
 	})
 
 	if err != nil {
@@ -97,56 +218,100 @@ func (r *OrdersRepository) ListRecent(ctx context.Context, n int) ([]orders.Orde
 	return orders, nil
 }
 
-func (r *OrdersRepository) assembleOrder(ctx context.Context, o *orders.Order) error {
-	var err error
-	o.Items, err = r.ItemsDAO.GetByOrderID(ctx, o.ID)
+// assembleOrders fetches items and payments of all the orders with set-based queries and stitches them together.
+func (r *OrdersRepository) assembleOrders(ctx context.Context, dtos []sqlc.Order) ([]orders.Order, error) {
+	if len(dtos) == 0 {
+		return nil, nil
+	}
+
+	ids := make([]string, 0, len(dtos))
+	for _, dto := range dtos {
+		ids = append(ids, dto.ID)
+	}
+
+	items, err := r.ItemsDAO.GetByOrderIDs(ctx, ids)
 	if err != nil {
-		return err
+		return nil, fmt.Errorf("loading items: %w", err)
 	}
 
-	payment, err := r.PaymentsDAO.GetByOrderID(ctx, o.ID)
+	payments, err := r.PaymentsDAO.GetByOrderIDs(ctx, ids)
 	if err != nil {
-		return err
+		return nil, fmt.Errorf("loading payments: %w", err)
+	}
+
+	assembled := make([]orders.Order, 0, len(dtos))
+	for _, dto := range dtos {
+		order, err := r.mapDtoToOrder(ctx, dto)
+		if err != nil {
+			return nil, err
+		}
+
+		order.Items = items[order.ID]
+		if order.Items == nil {
+			order.Items = []orders.Item{}
+		}
+
+		order.Payment = payments[order.ID]
+		assembled = append(assembled, *order)
 	}
 
-	o.Payment = payment
-	return nil
+	return assembled, nil
+}
+
+// read runs fn in a transaction on the replica when it is usable, otherwise on the primary, see routeRead.
+func (r *OrdersRepository) read(ctx context.Context, fn func(ctx context.Context) error) error {
+	return routeRead(ctx, r.Replica, func(onReplica bool) error {
+		if onReplica {
+			return withTx(ctx, r.Replica.Pool, fn)
+		}
+
+		return withTx(ctx, r.Pool, fn)
+	})
 }
 
 func (r *OrdersRepository) insertOrder(ctx context.Context, o *orders.Order) (*orders.Order, error) {
+	delivery, err := r.encryptDelivery(ctx, o)
+	if err != nil {
+		return nil, err
+	}
+
 	executor := extractExecutor(ctx, r.Pool)
 	inserted, err := sqlc.New(executor).CreateOrder(ctx, sqlc.CreateOrderParams{
-		ID:                o.ID,
-		TrackNumber:       o.TrackNumber,
-		Entry:             o.Entry,
-		Locale:            o.Locale,
-		InternalSignature: o.Signature,
-		CustomerID:        o.CustomerID,
-		DeliveryService:   o.DeliveryService,
-		Shardkey:          o.ShardKey,
-		SmID:              int32(o.SMID),
-		DateCreated:       o.CreatedAt,
-		OofShard:          o.OOFShard,
-		DeliveryName:      o.Delivery.Name,
-		DeliveryPhone:     o.Delivery.Phone,
-		DeliveryZip:       o.Delivery.Zip,
-		DeliveryAddress:   o.Delivery.Address,
-		DeliveryRegion:    o.Delivery.Region,
-		DeliveryEmail:     o.Delivery.Email,
-		DeliveryCity:      o.Delivery.City,
+		ID:                 o.ID,
+		TrackNumber:        o.TrackNumber,
+		Entry:              o.Entry,
+		Locale:             o.Locale,
+		InternalSignature:  o.Signature,
+		CustomerID:         o.CustomerID,
+		DeliveryService:    o.DeliveryService,
+		Shardkey:           o.ShardKey,
+		SmID:               int32(o.SMID),
+		DateCreated:        o.CreatedAt,
+		OofShard:           o.OOFShard,
+		DeliveryName:       delivery.Name,
+		DeliveryPhone:      delivery.Phone,
+		DeliveryZip:        o.Delivery.Zip,
+		DeliveryAddress:    delivery.Address,
+		DeliveryRegion:     o.Delivery.Region,
+		DeliveryEmail:      delivery.Email,
+		DeliveryCity:       o.Delivery.City,
+		Tenant:             cmp.Or(o.Tenant, orders.DefaultTenant),
+		DeliveryKeyID:      delivery.KeyID,
+		DeliveryDataKey:    delivery.DataKey,
+		DeliveryEmailIndex: delivery.EmailIndex,
 	})
 
 	if err != nil {
 		return nil, err
 	}
 
-	return mapDtoToOrder(inserted), nil
+	return r.mapDtoToOrder(ctx, inserted)
 }
 
-func (r *OrdersRepository) insertItems(ctx context.Context, orderID string, items []orders.Item) ([]orders.Item, error) {
+func (r *OrdersRepository) insertItems(ctx context.Context, orderID string, orderCreatedAt time.Time, items []orders.Item) ([]orders.Item, error) {
 	inserted := make([]orders.Item, 0, len(items))
 	for _, item := range items {
-		item, err := r.ItemsDAO.Create(ctx, orderID, &item)
+		item, err := r.ItemsDAO.Create(ctx, orderID, orderCreatedAt, &item)
 		if err != nil {
 			return nil, err
 		}
@@ -157,27 +322,56 @@ func (r *OrdersRepository) insertItems(ctx context.Context, orderID string, item
 	return inserted, nil
 }
 
-func mapDtoToOrder(o sqlc.Order) *orders.Order {
+// mapDtoToOrder maps the row to an order, decrypting its delivery data if it is encrypted.
+func (r *OrdersRepository) mapDtoToOrder(ctx context.Context, o sqlc.Order) (*orders.Order, error) {
+	var sealed *orders.SealedDelivery
+	if o.DeliveryKeyID.Valid && r.KeepEncrypted {
+		sealed = &orders.SealedDelivery{KeyID: o.DeliveryKeyID.String, DataKey: o.DeliveryDataKey}
+	}
+
+	if o.DeliveryKeyID.Valid && !r.KeepEncrypted {
+		if r.Cipher == nil {
+			return nil, fmt.Errorf("order %s is encrypted, but encryption is not configured", o.ID)
+		}
+
+		err := r.Cipher.Decrypt(ctx, o.ID, o.DeliveryKeyID.String, o.DeliveryDataKey,
+			&o.DeliveryName, &o.DeliveryPhone, &o.DeliveryAddress, &o.DeliveryEmail)
+		if err != nil {
+			return nil, fmt.Errorf("decrypting delivery of order %s: %w", o.ID, err)
+		}
+	}
+
 	return &orders.Order{
-		ID:          o.ID,
-		TrackNumber: o.TrackNumber,
-		Entry:       o.Entry,
-		Delivery: orders.Delivery{
-			Name:    o.DeliveryName,
-			Phone:   o.DeliveryPhone,
-			Zip:     o.DeliveryZip,
-			City:    o.DeliveryCity,
-			Address: o.DeliveryAddress,
-			Region:  o.DeliveryRegion,
-			Email:   o.DeliveryEmail,
+		Order: orderschema.Order{
+			ID:          o.ID,
+			TrackNumber: o.TrackNumber,
+			Entry:       o.Entry,
+			Delivery: orders.Delivery{
+				Name:    o.DeliveryName,
+				Phone:   o.DeliveryPhone,
+				Zip:     o.DeliveryZip,
+				City:    o.DeliveryCity,
+				Address: o.DeliveryAddress,
+				Region:  o.DeliveryRegion,
+				Email:   o.DeliveryEmail,
+			},
+			Locale:          o.Locale,
+			Signature:       o.InternalSignature,
+			CustomerID:      o.CustomerID,
+			DeliveryService: o.DeliveryService,
+			ShardKey:        o.Shardkey,
+			SMID:            int(o.SmID),
+			CreatedAt:       o.DateCreated,
+			OOFShard:        o.OofShard,
 		},
-		Locale:          o.Locale,
-		Signature:       o.InternalSignature,
-		CustomerID:      o.CustomerID,
-		DeliveryService: o.DeliveryService,
-		ShardKey:        o.Shardkey,
-		SMID:            int(o.SmID),
-		CreatedAt:       o.DateCreated,
-		OOFShard:        o.OofShard,
-	}
+		Tenant:       o.Tenant,
+		AnonymizedAt: o.AnonymizedAt.Time,
+		Sealed:       sealed,
+	}, nil
+}
+
+// tenantParam returns the tenant reads are limited to, NULL means all the tenants.
+func tenantParam(ctx context.Context) pgtype.Text {
+	tenant, ok := orders.TenantFrom(ctx)
+	return pgtype.Text{String: tenant, Valid: ok}
 }
@@ -0,0 +1,204 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// partitionedTables are detached together, since items and payments share the partitioning of their orders.
+var partitionedTables = []string{"orders", "items", "payments"}
+
+const partitionSuffixLayout = "2006_01"
+
+// archiveSchema holds partitions detached without being dropped.
+const archiveSchema = "orders_archive"
+
+// Partitions manages monthly partitions of orders, items and payments.
+type Partitions struct {
+	Pool *pgxpool.Pool
+}
+
+// CreateAhead creates partitions for every month from the month of from up to the given number of months ahead.
+func (p *Partitions) CreateAhead(ctx context.Context, from time.Time, months int) error {
+	_, err := p.Pool.Exec(ctx,
+		"SELECT create_orders_partitions($1::date, $2::date)",
+		from, from.AddDate(0, months, 0),
+	)
+
+	return err
+}
+
+// DrainDefault moves the rows of the default partitions into the monthly partitions of their months, creating the
+// missing ones. The default partitions are detached meanwhile, since a partition cannot be created for a month
+// the default one has rows of. It returns the number of moved orders.
+func (p *Partitions) DrainDefault(ctx context.Context) (int64, error) {
+	var moved int64
+
+	err := withTx(ctx, p.Pool, func(ctx context.Context) error {
+		tx := ctx.Value(txKey{}).(pgx.Tx)
+
+		var months []time.Time
+		for _, table := range partitionedTables {
+			rows, err := tx.Query(ctx, fmt.Sprintf(
+				"SELECT DISTINCT date_trunc('month', date_created)::date FROM %s",
+				pgx.Identifier{table + "_default"}.Sanitize(),
+			))
+			if err != nil {
+				return err
+			}
+
+			tableMonths, err := pgx.CollectRows(rows, pgx.RowTo[time.Time])
+			if err != nil {
+				return err
+			}
+
+			months = append(months, tableMonths...)
+		}
+
+		if len(months) == 0 {
+			return nil
+		}
+
+		for _, table := range partitionedTables {
+			_, err := tx.Exec(ctx, fmt.Sprintf("ALTER TABLE %s DETACH PARTITION %s",
+				pgx.Identifier{table}.Sanitize(), pgx.Identifier{table + "_default"}.Sanitize()))
+			if err != nil {
+				return fmt.Errorf("detaching default partition of %s: %w", table, err)
+			}
+		}
+
+		for _, month := range months {
+			if _, err := tx.Exec(ctx, "SELECT create_orders_partitions($1::date, $1::date)", month); err != nil {
+				return fmt.Errorf("creating partitions of %s: %w", month.Format("2006-01"), err)
+			}
+		}
+
+		for _, table := range partitionedTables {
+			parent, partition := pgx.Identifier{table}.Sanitize(), pgx.Identifier{table + "_default"}.Sanitize()
+
+			tag, err := tx.Exec(ctx, fmt.Sprintf("INSERT INTO %s SELECT * FROM %s", parent, partition))
+			if err != nil {
+				return fmt.Errorf("moving rows of %s: %w", partition, err)
+			}
+
+			if table == "orders" {
+				moved = tag.RowsAffected()
+			}
+
+			if _, err := tx.Exec(ctx, "TRUNCATE "+partition); err != nil {
+				return err
+			}
+
+			_, err = tx.Exec(ctx, fmt.Sprintf("ALTER TABLE %s ATTACH PARTITION %s DEFAULT", parent, partition))
+			if err != nil {
+				return fmt.Errorf("attaching %s: %w", partition, err)
+			}
+		}
+
+		return nil
+	})
+
+	return moved, err
+}
+
+// ListMonths returns the first days of months having an attached orders partition.
+func (p *Partitions) ListMonths(ctx context.Context) ([]time.Time, error) {
+	rows, err := p.Pool.Query(ctx, `
+		SELECT child.relname
+		FROM pg_inherits
+		JOIN pg_class parent ON parent.oid = pg_inherits.inhparent
+		JOIN pg_class child ON child.oid = pg_inherits.inhrelid
+		WHERE parent.relname = 'orders'`,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	names, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return nil, err
+	}
+
+	var months []time.Time
+	for _, name := range names {
+		suffix, ok := strings.CutPrefix(name, "orders_p")
+		if !ok {
+			continue
+		}
+
+		month, err := time.Parse(partitionSuffixLayout, suffix)
+		if err != nil {
+			continue
+		}
+
+		months = append(months, month)
+	}
+
+	return months, nil
+}
+
+// Detach detaches partitions of the month from all the partitioned tables in a single transaction.
+// Detached partitions are either dropped or moved to the archive schema. A partition of the month may already
+// be archived, when the month was recreated for late orders drained from the default partition,
+// then the rows are merged into the archived partition.
+func (p *Partitions) Detach(ctx context.Context, month time.Time, drop bool) error {
+	return withTx(ctx, p.Pool, func(ctx context.Context) error {
+		tx := ctx.Value(txKey{}).(pgx.Tx)
+
+		if !drop {
+			if _, err := tx.Exec(ctx, "CREATE SCHEMA IF NOT EXISTS "+archiveSchema); err != nil {
+				return err
+			}
+		}
+
+		for _, table := range partitionedTables {
+			name := table + "_p" + month.Format(partitionSuffixLayout)
+			partition := pgx.Identifier{name}.Sanitize()
+
+			_, err := tx.Exec(ctx, fmt.Sprintf("ALTER TABLE %s DETACH PARTITION %s", table, partition))
+			if err != nil {
+				return fmt.Errorf("detaching %s: %w", partition, err)
+			}
+
+			if drop {
+				_, err = tx.Exec(ctx, "DROP TABLE "+partition)
+			} else {
+				err = archivePartition(ctx, tx, name)
+			}
+
+			if err != nil {
+				return fmt.Errorf("archiving %s: %w", partition, err)
+			}
+		}
+
+		return nil
+	})
+}
+
+// archivePartition moves the detached partition to the archive schema, merging its rows into the archived
+// partition of the same name if there is one.
+func archivePartition(ctx context.Context, tx pgx.Tx, name string) error {
+	partition, archived := pgx.Identifier{name}.Sanitize(), pgx.Identifier{archiveSchema, name}.Sanitize()
+
+	var exists bool
+	if err := tx.QueryRow(ctx, "SELECT to_regclass($1) IS NOT NULL", archived).Scan(&exists); err != nil {
+		return err
+	}
+
+	if !exists {
+		_, err := tx.Exec(ctx, fmt.Sprintf("ALTER TABLE %s SET SCHEMA %s", partition, archiveSchema))
+		return err
+	}
+
+	if _, err := tx.Exec(ctx, fmt.Sprintf("INSERT INTO %s SELECT * FROM %s", archived, partition)); err != nil {
+		return err
+	}
+
+	_, err := tx.Exec(ctx, "DROP TABLE "+partition)
+	return err
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"order-persistor/internal/orders"
 	"order-persistor/internal/postgres/sqlc"
+	"time"
 
 	"github.com/jackc/pgx/v5/pgxpool"
 )
@@ -12,17 +13,24 @@ type PaymentsDAO struct {
 	Pool *pgxpool.Pool
 }
 
-func (r *PaymentsDAO) GetByOrderID(ctx context.Context, orderID string) (*orders.Payment, error) {
+// GetByOrderIDs loads payments of all the given orders at once, keyed by order ID.
+func (r *PaymentsDAO) GetByOrderIDs(ctx context.Context, orderIDs []string) (map[string]*orders.Payment, error) {
 	exec := extractExecutor(ctx, r.Pool)
-	dto, err := sqlc.New(exec).GetPaymentByOrderID(ctx, orderID)
+	dtos, err := sqlc.New(exec).GetPaymentsByOrderIDs(ctx, orderIDs)
 	if err != nil {
 		return nil, err
 	}
 
-	return mapDtoToPayment(dto), nil
+	payments := make(map[string]*orders.Payment, len(dtos))
+	for _, dto := range dtos {
+		payments[dto.OrderID] = mapDtoToPayment(dto)
+	}
+
+	return payments, nil
 }
 
-func (r *PaymentsDAO) Create(ctx context.Context, orderID string, p *orders.Payment) (*orders.Payment, error) {
+// Create inserts the payment of the order, orderCreatedAt is needed to place the payment into the partition of its order.
+func (r *PaymentsDAO) Create(ctx context.Context, orderID string, orderCreatedAt time.Time, p *orders.Payment) (*orders.Payment, error) {
 	exec := extractExecutor(ctx, r.Pool)
 	dto, err := sqlc.New(exec).CreatePayment(ctx, sqlc.CreatePaymentParams{
 		Transaction:  p.Transaction,
@@ -36,6 +44,7 @@ func (r *PaymentsDAO) Create(ctx context.Context, orderID string, p *orders.Paym
 		DeliveryCost: p.DeliveryCost,
 		GoodsTotal:   int32(p.GoodsTotal),
 		CustomFee:    p.CustomFee,
+		DateCreated:  orderCreatedAt,
 	})
 
 	if err != nil {
@@ -45,6 +54,11 @@ func (r *PaymentsDAO) Create(ctx context.Context, orderID string, p *orders.Paym
 	return mapDtoToPayment(dto), nil
 }
 
+func (r *PaymentsDAO) DeleteByOrderIDs(ctx context.Context, orderIDs []string) error {
+	exec := extractExecutor(ctx, r.Pool)
+	return sqlc.New(exec).DeletePaymentsByOrderIDs(ctx, orderIDs)
+}
+
 func mapDtoToPayment(p sqlc.Payment) *orders.Payment {
 	return &orders.Payment{
 		Transaction:  p.Transaction,
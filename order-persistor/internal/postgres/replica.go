@@ -0,0 +1,144 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"order-persistor/internal/config"
+	"order-persistor/internal/metrics"
+	"sync/atomic"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type primaryKey struct{}
+
+// WithPrimary marks the context, so reads made with it are never routed to the replica.
+// It is meant for the reads which must see the latest writes, like the ones of data subject requests.
+func WithPrimary(ctx context.Context) context.Context {
+	return context.WithValue(ctx, primaryKey{}, true)
+}
+
+func primaryRequested(ctx context.Context) bool {
+	requested, _ := ctx.Value(primaryKey{}).(bool)
+	return requested
+}
+
+// Replica tracks health of a read replica.
+type Replica struct {
+	Pool *pgxpool.Pool
+
+	cfg     config.PostgresReplica
+	logger  *slog.Logger
+	healthy atomic.Bool
+}
+
+// NewReplica creates a replica considered healthy until the first failed check.
+// Health checking only starts with an explicit call of Run function.
+func NewReplica(pool *pgxpool.Pool, cfg config.PostgresReplica, logger *slog.Logger) *Replica {
+	r := &Replica{
+		Pool:   pool,
+		cfg:    cfg,
+		logger: logger,
+	}
+
+	r.setHealthy(true)
+	return r
+}
+
+// Run periodically checks replica health, blocking the calling coroutine.
+func (r *Replica) Run(ctx context.Context) error {
+	ticker := time.NewTicker(r.cfg.HealthCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			if err := r.check(ctx); err != nil {
+				r.markUnhealthy(err)
+				continue
+			}
+
+			if !r.healthy.Load() {
+				r.logger.Info("postgres replica recovered")
+			}
+
+			r.setHealthy(true)
+		}
+	}
+}
+
+func (r *Replica) Healthy() bool {
+	return r.healthy.Load()
+}
+
+// routeRead runs the read on the replica when it is usable, otherwise on the primary.
+// Any replica failure, including absence of rows, makes the read to be retried on the primary,
+// while only the failures other than absence of rows mark the replica unhealthy.
+func routeRead(ctx context.Context, replica *Replica, run func(onReplica bool) error) error {
+	if replica == nil || primaryRequested(ctx) || !replica.Healthy() {
+		metrics.Reads.WithLabelValues("primary").Inc()
+		return run(false)
+	}
+
+	metrics.Reads.WithLabelValues("replica").Inc()
+	err := run(true)
+	if err == nil || ctx.Err() != nil {
+		return err
+	}
+
+	if !errors.Is(err, pgx.ErrNoRows) {
+		replica.markUnhealthy(err)
+	}
+
+	metrics.Reads.WithLabelValues("primary").Inc()
+	return run(false)
+}
+
+func (r *Replica) markUnhealthy(err error) {
+	if r.healthy.Load() {
+		r.logger.Error("postgres replica is unhealthy, routing reads to primary", "err", err)
+	}
+
+	r.setHealthy(false)
+}
+
+func (r *Replica) setHealthy(healthy bool) {
+	r.healthy.Store(healthy)
+
+	if healthy {
+		metrics.ReplicaHealthy.Set(1)
+	} else {
+		metrics.ReplicaHealthy.Set(0)
+	}
+}
+
+func (r *Replica) check(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, r.cfg.HealthCheckInterval)
+	defer cancel()
+
+	// replay timestamp stops moving while the primary is idle, so the replica which replayed all the received wal
+	// is not lagging regardless of it
+	var lagSeconds float64
+	err := r.Pool.QueryRow(ctx, `
+		SELECT CASE
+			WHEN pg_last_wal_receive_lsn() = pg_last_wal_replay_lsn() THEN 0
+			ELSE COALESCE(EXTRACT(EPOCH FROM now() - pg_last_xact_replay_timestamp()), 0)
+		END::float8`,
+	).Scan(&lagSeconds)
+	if err != nil {
+		return err
+	}
+
+	lag := time.Duration(lagSeconds * float64(time.Second))
+	if r.cfg.MaxLag > 0 && lag > r.cfg.MaxLag {
+		return fmt.Errorf("replication lag %s exceeds %s", lag, r.cfg.MaxLag)
+	}
+
+	return nil
+}
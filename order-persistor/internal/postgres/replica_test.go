@@ -0,0 +1,101 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"order-persistor/internal/config"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+)
+
+func TestRouteRead(t *testing.T) {
+	t.Parallel()
+
+	replicaErr := errors.New("connection reset")
+
+	tests := []struct {
+		name      string
+		noReplica bool
+		unhealthy bool
+		primary   bool
+		// replicaErr is returned by the read made on the replica
+		replicaErr  error
+		wantTargets []bool
+		wantHealthy bool
+	}{
+		{name: "no replica", noReplica: true, wantTargets: []bool{false}},
+		{name: "healthy replica", wantTargets: []bool{true}, wantHealthy: true},
+		{name: "primary requested", primary: true, wantTargets: []bool{false}, wantHealthy: true},
+		{name: "unhealthy replica", unhealthy: true, wantTargets: []bool{false}},
+		{name: "replica failure", replicaErr: replicaErr, wantTargets: []bool{true, false}},
+		{name: "missing on replica", replicaErr: pgx.ErrNoRows, wantTargets: []bool{true, false}, wantHealthy: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var replica *Replica
+			if !tt.noReplica {
+				replica = NewReplica(nil, config.PostgresReplica{}, slog.New(slog.DiscardHandler))
+				replica.healthy.Store(!tt.unhealthy)
+			}
+
+			ctx := context.Background()
+			if tt.primary {
+				ctx = WithPrimary(ctx)
+			}
+
+			var targets []bool
+			err := routeRead(ctx, replica, func(onReplica bool) error {
+				targets = append(targets, onReplica)
+				if onReplica {
+					return tt.replicaErr
+				}
+
+				return nil
+			})
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if len(targets) != len(tt.wantTargets) {
+				t.Fatalf("expected reads on replica %v, got %v", tt.wantTargets, targets)
+			}
+
+			for i := range targets {
+				if targets[i] != tt.wantTargets[i] {
+					t.Fatalf("expected reads on replica %v, got %v", tt.wantTargets, targets)
+				}
+			}
+
+			if replica != nil && replica.Healthy() != tt.wantHealthy {
+				t.Errorf("expected replica healthy %t, got %t", tt.wantHealthy, replica.Healthy())
+			}
+		})
+	}
+}
+
+func TestRouteRead_canceled(t *testing.T) {
+	t.Parallel()
+
+	replica := NewReplica(nil, config.PostgresReplica{}, slog.New(slog.DiscardHandler))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	reads := 0
+	err := routeRead(ctx, replica, func(bool) error {
+		reads++
+		return ctx.Err()
+	})
+
+	if !errors.Is(err, context.Canceled) || reads != 1 {
+		t.Fatalf("expected canceled read not to be retried, got %v after %d reads", err, reads)
+	}
+
+	if !replica.Healthy() {
+		t.Error("expected canceled read not to mark replica unhealthy")
+	}
+}
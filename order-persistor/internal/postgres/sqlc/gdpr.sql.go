@@ -0,0 +1,34 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.29.0
+// source: gdpr.sql
+
+package sqlc
+
+import (
+	"context"
+)
+
+const createGDPRAuditEntry = `-- name: CreateGDPRAuditEntry :exec
+INSERT INTO gdpr_audit (action, subject_type, subject_hash, actor, order_ids)
+VALUES ($1, $2, $3, $4, $5)
+`
+
+type CreateGDPRAuditEntryParams struct {
+	Action      string
+	SubjectType string
+	SubjectHash string
+	Actor       string
+	OrderIds    []string
+}
+
+func (q *Queries) CreateGDPRAuditEntry(ctx context.Context, arg CreateGDPRAuditEntryParams) error {
+	_, err := q.db.Exec(ctx, createGDPRAuditEntry,
+		arg.Action,
+		arg.SubjectType,
+		arg.SubjectHash,
+		arg.Actor,
+		arg.OrderIds,
+	)
+	return err
+}
@@ -7,6 +7,7 @@ package sqlc
 
 import (
 	"context"
+	"time"
 
 	"github.com/shopspring/decimal"
 )
@@ -24,9 +25,10 @@ INSERT INTO items(
     total_price,
     nm_id,
     brand,
-    status
-) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
-RETURNING id, order_id, chrt_id, track_number, price, rid, name, sale, size, total_price, nm_id, brand, status
+    status,
+    date_created
+) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+RETURNING id, order_id, chrt_id, track_number, price, rid, name, sale, size, total_price, nm_id, brand, status, date_created
 `
 
 type CreateItemParams struct {
@@ -42,6 +44,7 @@ type CreateItemParams struct {
 	NmID        int32
 	Brand       string
 	Status      int32
+	DateCreated time.Time
 }
 
 func (q *Queries) CreateItem(ctx context.Context, arg CreateItemParams) (Item, error) {
@@ -58,6 +61,7 @@ func (q *Queries) CreateItem(ctx context.Context, arg CreateItemParams) (Item, e
 		arg.NmID,
 		arg.Brand,
 		arg.Status,
+		arg.DateCreated,
 	)
 	var i Item
 	err := row.Scan(
@@ -74,18 +78,30 @@ func (q *Queries) CreateItem(ctx context.Context, arg CreateItemParams) (Item, e
 		&i.NmID,
 		&i.Brand,
 		&i.Status,
+		&i.DateCreated,
 	)
 	return i, err
 }
 
-const getItemsByOrderID = `-- name: GetItemsByOrderID :many
-SELECT id, order_id, chrt_id, track_number, price, rid, name, sale, size, total_price, nm_id, brand, status
+const deleteItemsByOrderIDs = `-- name: DeleteItemsByOrderIDs :exec
+DELETE FROM items
+WHERE order_id = ANY($1::text[])
+`
+
+func (q *Queries) DeleteItemsByOrderIDs(ctx context.Context, orderIds []string) error {
+	_, err := q.db.Exec(ctx, deleteItemsByOrderIDs, orderIds)
+	return err
+}
+
+const getItemsByOrderIDs = `-- name: GetItemsByOrderIDs :many
+SELECT id, order_id, chrt_id, track_number, price, rid, name, sale, size, total_price, nm_id, brand, status, date_created
 FROM items
-WHERE order_id = $1
+WHERE order_id = ANY($1::text[])
+ORDER BY order_id, id
 `
 
-func (q *Queries) GetItemsByOrderID(ctx context.Context, orderID string) ([]Item, error) {
-	rows, err := q.db.Query(ctx, getItemsByOrderID, orderID)
+func (q *Queries) GetItemsByOrderIDs(ctx context.Context, orderIds []string) ([]Item, error) {
+	rows, err := q.db.Query(ctx, getItemsByOrderIDs, orderIds)
 	if err != nil {
 		return nil, err
 	}
@@ -107,6 +123,7 @@ func (q *Queries) GetItemsByOrderID(ctx context.Context, orderID string) ([]Item
 			&i.NmID,
 			&i.Brand,
 			&i.Status,
+			&i.DateCreated,
 		); err != nil {
 			return nil, err
 		}
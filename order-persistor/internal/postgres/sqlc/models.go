@@ -7,9 +7,20 @@ package sqlc
 import (
 	"time"
 
+	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/shopspring/decimal"
 )
 
+type GdprAudit struct {
+	ID          int64
+	Action      string
+	SubjectType string
+	SubjectHash string
+	Actor       string
+	OrderIds    []string
+	CreatedAt   time.Time
+}
+
 type Item struct {
 	ID          int32
 	OrderID     string
@@ -24,9 +35,53 @@ type Item struct {
 	NmID        int32
 	Brand       string
 	Status      int32
+	DateCreated time.Time
+}
+
+type ItemsDefault struct {
+	ID          int32
+	OrderID     string
+	ChrtID      int32
+	TrackNumber string
+	Price       decimal.Decimal
+	Rid         string
+	Name        string
+	Sale        decimal.Decimal
+	Size        string
+	TotalPrice  decimal.Decimal
+	NmID        int32
+	Brand       string
+	Status      int32
+	DateCreated time.Time
 }
 
 type Order struct {
+	ID                 string
+	TrackNumber        string
+	Entry              string
+	Locale             string
+	InternalSignature  string
+	CustomerID         string
+	DeliveryService    string
+	Shardkey           string
+	SmID               int32
+	DateCreated        time.Time
+	OofShard           string
+	DeliveryName       string
+	DeliveryCity       string
+	DeliveryPhone      string
+	DeliveryZip        string
+	DeliveryAddress    string
+	DeliveryRegion     string
+	DeliveryEmail      string
+	Tenant             string
+	DeliveryKeyID      pgtype.Text
+	DeliveryDataKey    []byte
+	DeliveryEmailIndex pgtype.Text
+	AnonymizedAt       pgtype.Timestamptz
+}
+
+type OrdersDefault struct {
 	ID                string
 	TrackNumber       string
 	Entry             string
@@ -59,4 +114,20 @@ type Payment struct {
 	DeliveryCost decimal.Decimal
 	GoodsTotal   int32
 	CustomFee    decimal.Decimal
+	DateCreated  time.Time
+}
+
+type PaymentsDefault struct {
+	Transaction  string
+	OrderID      string
+	RequestID    string
+	Currency     string
+	Provider     string
+	Amount       decimal.Decimal
+	PaymentDt    int64
+	Bank         string
+	DeliveryCost decimal.Decimal
+	GoodsTotal   int32
+	CustomFee    decimal.Decimal
+	DateCreated  time.Time
 }
@@ -8,8 +8,46 @@ package sqlc
 import (
 	"context"
 	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const anonymizeOrders = `-- name: AnonymizeOrders :execrows
+UPDATE orders
+SET delivery_name = '',
+    delivery_phone = '',
+    delivery_zip = '',
+    delivery_address = '',
+    delivery_email = '',
+    delivery_key_id = NULL,
+    delivery_data_key = NULL,
+    delivery_email_index = NULL,
+    anonymized_at = now()
+WHERE id = ANY($1::text[])
+`
+
+// Delivery personal data is erased, while the order with its items and payment is kept for accounting.
+func (q *Queries) AnonymizeOrders(ctx context.Context, ids []string) (int64, error) {
+	result, err := q.db.Exec(ctx, anonymizeOrders, ids)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const countOrdersBefore = `-- name: CountOrdersBefore :one
+SELECT count(*)
+FROM orders
+WHERE date_created < $1
+`
+
+func (q *Queries) CountOrdersBefore(ctx context.Context, dateCreated time.Time) (int64, error) {
+	row := q.db.QueryRow(ctx, countOrdersBefore, dateCreated)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createOrder = `-- name: CreateOrder :one
 INSERT INTO orders (
     id,
@@ -29,31 +67,39 @@ INSERT INTO orders (
     delivery_address,
     delivery_region,
     delivery_email,
-    delivery_city
+    delivery_city,
+    tenant,
+    delivery_key_id,
+    delivery_data_key,
+    delivery_email_index
 )
-VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18)
-RETURNING id, track_number, entry, locale, internal_signature, customer_id, delivery_service, shardkey, sm_id, date_created, oof_shard, delivery_name, delivery_city, delivery_phone, delivery_zip, delivery_address, delivery_region, delivery_email
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22)
+RETURNING id, track_number, entry, locale, internal_signature, customer_id, delivery_service, shardkey, sm_id, date_created, oof_shard, delivery_name, delivery_city, delivery_phone, delivery_zip, delivery_address, delivery_region, delivery_email, tenant, delivery_key_id, delivery_data_key, delivery_email_index, anonymized_at
 `
 
 type CreateOrderParams struct {
-	ID                string
-	TrackNumber       string
-	Entry             string
-	Locale            string
-	InternalSignature string
-	CustomerID        string
-	DeliveryService   string
-	Shardkey          string
-	SmID              int32
-	DateCreated       time.Time
-	OofShard          string
-	DeliveryName      string
-	DeliveryPhone     string
-	DeliveryZip       string
-	DeliveryAddress   string
-	DeliveryRegion    string
-	DeliveryEmail     string
-	DeliveryCity      string
+	ID                 string
+	TrackNumber        string
+	Entry              string
+	Locale             string
+	InternalSignature  string
+	CustomerID         string
+	DeliveryService    string
+	Shardkey           string
+	SmID               int32
+	DateCreated        time.Time
+	OofShard           string
+	DeliveryName       string
+	DeliveryPhone      string
+	DeliveryZip        string
+	DeliveryAddress    string
+	DeliveryRegion     string
+	DeliveryEmail      string
+	DeliveryCity       string
+	Tenant             string
+	DeliveryKeyID      pgtype.Text
+	DeliveryDataKey    []byte
+	DeliveryEmailIndex pgtype.Text
 }
 
 func (q *Queries) CreateOrder(ctx context.Context, arg CreateOrderParams) (Order, error) {
@@ -76,6 +122,10 @@ func (q *Queries) CreateOrder(ctx context.Context, arg CreateOrderParams) (Order
 		arg.DeliveryRegion,
 		arg.DeliveryEmail,
 		arg.DeliveryCity,
+		arg.Tenant,
+		arg.DeliveryKeyID,
+		arg.DeliveryDataKey,
+		arg.DeliveryEmailIndex,
 	)
 	var i Order
 	err := row.Scan(
@@ -97,18 +147,44 @@ func (q *Queries) CreateOrder(ctx context.Context, arg CreateOrderParams) (Order
 		&i.DeliveryAddress,
 		&i.DeliveryRegion,
 		&i.DeliveryEmail,
+		&i.Tenant,
+		&i.DeliveryKeyID,
+		&i.DeliveryDataKey,
+		&i.DeliveryEmailIndex,
+		&i.AnonymizedAt,
 	)
 	return i, err
 }
 
+const deleteOrdersByIDs = `-- name: DeleteOrdersByIDs :execrows
+DELETE FROM orders
+WHERE id = ANY($1::text[])
+`
+
+func (q *Queries) DeleteOrdersByIDs(ctx context.Context, ids []string) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteOrdersByIDs, ids)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
 const getOrderByID = `-- name: GetOrderByID :one
-SELECT id, track_number, entry, locale, internal_signature, customer_id, delivery_service, shardkey, sm_id, date_created, oof_shard, delivery_name, delivery_city, delivery_phone, delivery_zip, delivery_address, delivery_region, delivery_email
+
+SELECT id, track_number, entry, locale, internal_signature, customer_id, delivery_service, shardkey, sm_id, date_created, oof_shard, delivery_name, delivery_city, delivery_phone, delivery_zip, delivery_address, delivery_region, delivery_email, tenant, delivery_key_id, delivery_data_key, delivery_email_index, anonymized_at
 FROM orders
 WHERE id = $1
+  AND ($2::text IS NULL OR tenant = $2::text)
 `
 
-func (q *Queries) GetOrderByID(ctx context.Context, id string) (Order, error) {
-	row := q.db.QueryRow(ctx, getOrderByID, id)
+type GetOrderByIDParams struct {
+	ID     string
+	Tenant pgtype.Text
+}
+
+// Reads are limited to a single tenant if it is given, and span all the tenants otherwise.
+func (q *Queries) GetOrderByID(ctx context.Context, arg GetOrderByIDParams) (Order, error) {
+	row := q.db.QueryRow(ctx, getOrderByID, arg.ID, arg.Tenant)
 	var i Order
 	err := row.Scan(
 		&i.ID,
@@ -129,19 +205,350 @@ func (q *Queries) GetOrderByID(ctx context.Context, id string) (Order, error) {
 		&i.DeliveryAddress,
 		&i.DeliveryRegion,
 		&i.DeliveryEmail,
+		&i.Tenant,
+		&i.DeliveryKeyID,
+		&i.DeliveryDataKey,
+		&i.DeliveryEmailIndex,
+		&i.AnonymizedAt,
 	)
 	return i, err
 }
 
+const getOrdersBefore = `-- name: GetOrdersBefore :many
+SELECT id, track_number, entry, locale, internal_signature, customer_id, delivery_service, shardkey, sm_id, date_created, oof_shard, delivery_name, delivery_city, delivery_phone, delivery_zip, delivery_address, delivery_region, delivery_email, tenant, delivery_key_id, delivery_data_key, delivery_email_index, anonymized_at
+FROM orders
+WHERE (date_created, id) < ($1::timestamptz, $2::text)
+  AND ($3::text IS NULL OR tenant = $3::text)
+ORDER BY date_created DESC, id DESC
+LIMIT $4
+`
+
+type GetOrdersBeforeParams struct {
+	DateCreated time.Time
+	ID          string
+	Tenant      pgtype.Text
+	N           int32
+}
+
+func (q *Queries) GetOrdersBefore(ctx context.Context, arg GetOrdersBeforeParams) ([]Order, error) {
+	rows, err := q.db.Query(ctx, getOrdersBefore,
+		arg.DateCreated,
+		arg.ID,
+		arg.Tenant,
+		arg.N,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Order
+	for rows.Next() {
+		var i Order
+		if err := rows.Scan(
+			&i.ID,
+			&i.TrackNumber,
+			&i.Entry,
+			&i.Locale,
+			&i.InternalSignature,
+			&i.CustomerID,
+			&i.DeliveryService,
+			&i.Shardkey,
+			&i.SmID,
+			&i.DateCreated,
+			&i.OofShard,
+			&i.DeliveryName,
+			&i.DeliveryCity,
+			&i.DeliveryPhone,
+			&i.DeliveryZip,
+			&i.DeliveryAddress,
+			&i.DeliveryRegion,
+			&i.DeliveryEmail,
+			&i.Tenant,
+			&i.DeliveryKeyID,
+			&i.DeliveryDataKey,
+			&i.DeliveryEmailIndex,
+			&i.AnonymizedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getOrdersByCustomerID = `-- name: GetOrdersByCustomerID :many
+SELECT id, track_number, entry, locale, internal_signature, customer_id, delivery_service, shardkey, sm_id, date_created, oof_shard, delivery_name, delivery_city, delivery_phone, delivery_zip, delivery_address, delivery_region, delivery_email, tenant, delivery_key_id, delivery_data_key, delivery_email_index, anonymized_at
+FROM orders
+WHERE customer_id = $1::text
+  AND ($2::text IS NULL OR tenant = $2::text)
+ORDER BY date_created DESC, id DESC
+LIMIT $3
+`
+
+type GetOrdersByCustomerIDParams struct {
+	CustomerID string
+	Tenant     pgtype.Text
+	N          int32
+}
+
+func (q *Queries) GetOrdersByCustomerID(ctx context.Context, arg GetOrdersByCustomerIDParams) ([]Order, error) {
+	rows, err := q.db.Query(ctx, getOrdersByCustomerID, arg.CustomerID, arg.Tenant, arg.N)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Order
+	for rows.Next() {
+		var i Order
+		if err := rows.Scan(
+			&i.ID,
+			&i.TrackNumber,
+			&i.Entry,
+			&i.Locale,
+			&i.InternalSignature,
+			&i.CustomerID,
+			&i.DeliveryService,
+			&i.Shardkey,
+			&i.SmID,
+			&i.DateCreated,
+			&i.OofShard,
+			&i.DeliveryName,
+			&i.DeliveryCity,
+			&i.DeliveryPhone,
+			&i.DeliveryZip,
+			&i.DeliveryAddress,
+			&i.DeliveryRegion,
+			&i.DeliveryEmail,
+			&i.Tenant,
+			&i.DeliveryKeyID,
+			&i.DeliveryDataKey,
+			&i.DeliveryEmailIndex,
+			&i.AnonymizedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getOrdersByEmail = `-- name: GetOrdersByEmail :many
+SELECT id, track_number, entry, locale, internal_signature, customer_id, delivery_service, shardkey, sm_id, date_created, oof_shard, delivery_name, delivery_city, delivery_phone, delivery_zip, delivery_address, delivery_region, delivery_email, tenant, delivery_key_id, delivery_data_key, delivery_email_index, anonymized_at
+FROM orders
+WHERE delivery_email = $1::text
+  AND ($2::text IS NULL OR tenant = $2::text)
+ORDER BY date_created DESC, id DESC
+LIMIT $3
+`
+
+type GetOrdersByEmailParams struct {
+	Email  string
+	Tenant pgtype.Text
+	N      int32
+}
+
+func (q *Queries) GetOrdersByEmail(ctx context.Context, arg GetOrdersByEmailParams) ([]Order, error) {
+	rows, err := q.db.Query(ctx, getOrdersByEmail, arg.Email, arg.Tenant, arg.N)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Order
+	for rows.Next() {
+		var i Order
+		if err := rows.Scan(
+			&i.ID,
+			&i.TrackNumber,
+			&i.Entry,
+			&i.Locale,
+			&i.InternalSignature,
+			&i.CustomerID,
+			&i.DeliveryService,
+			&i.Shardkey,
+			&i.SmID,
+			&i.DateCreated,
+			&i.OofShard,
+			&i.DeliveryName,
+			&i.DeliveryCity,
+			&i.DeliveryPhone,
+			&i.DeliveryZip,
+			&i.DeliveryAddress,
+			&i.DeliveryRegion,
+			&i.DeliveryEmail,
+			&i.Tenant,
+			&i.DeliveryKeyID,
+			&i.DeliveryDataKey,
+			&i.DeliveryEmailIndex,
+			&i.AnonymizedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getOrdersByEmailIndex = `-- name: GetOrdersByEmailIndex :many
+
+SELECT id, track_number, entry, locale, internal_signature, customer_id, delivery_service, shardkey, sm_id, date_created, oof_shard, delivery_name, delivery_city, delivery_phone, delivery_zip, delivery_address, delivery_region, delivery_email, tenant, delivery_key_id, delivery_data_key, delivery_email_index, anonymized_at
+FROM orders
+WHERE (delivery_email_index = $1::text
+    OR (delivery_key_id IS NULL AND lower(delivery_email) = lower($2::text)))
+  AND ($3::text IS NULL OR tenant = $3::text)
+ORDER BY date_created DESC, id DESC
+LIMIT $4
+`
+
+type GetOrdersByEmailIndexParams struct {
+	EmailIndex string
+	Email      string
+	Tenant     pgtype.Text
+	N          int32
+}
+
+// Emails are searched by blind index when delivery data is encrypted, and by plain value otherwise.
+func (q *Queries) GetOrdersByEmailIndex(ctx context.Context, arg GetOrdersByEmailIndexParams) ([]Order, error) {
+	rows, err := q.db.Query(ctx, getOrdersByEmailIndex,
+		arg.EmailIndex,
+		arg.Email,
+		arg.Tenant,
+		arg.N,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Order
+	for rows.Next() {
+		var i Order
+		if err := rows.Scan(
+			&i.ID,
+			&i.TrackNumber,
+			&i.Entry,
+			&i.Locale,
+			&i.InternalSignature,
+			&i.CustomerID,
+			&i.DeliveryService,
+			&i.Shardkey,
+			&i.SmID,
+			&i.DateCreated,
+			&i.OofShard,
+			&i.DeliveryName,
+			&i.DeliveryCity,
+			&i.DeliveryPhone,
+			&i.DeliveryZip,
+			&i.DeliveryAddress,
+			&i.DeliveryRegion,
+			&i.DeliveryEmail,
+			&i.Tenant,
+			&i.DeliveryKeyID,
+			&i.DeliveryDataKey,
+			&i.DeliveryEmailIndex,
+			&i.AnonymizedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getOrdersToReencrypt = `-- name: GetOrdersToReencrypt :many
+SELECT id, track_number, entry, locale, internal_signature, customer_id, delivery_service, shardkey, sm_id, date_created, oof_shard, delivery_name, delivery_city, delivery_phone, delivery_zip, delivery_address, delivery_region, delivery_email, tenant, delivery_key_id, delivery_data_key, delivery_email_index, anonymized_at
+FROM orders
+WHERE (date_created, id) > ($1::timestamptz, $2::text)
+  AND ($3::bool OR delivery_key_id IS DISTINCT FROM $4::text)
+ORDER BY date_created, id
+LIMIT $5
+`
+
+type GetOrdersToReencryptParams struct {
+	DateCreated time.Time
+	ID          string
+	AllKeys     bool
+	KeyID       string
+	N           int32
+}
+
+// Orders are returned in ascending order after the cursor, either all of them
+// or only those not encrypted with the given key.
+func (q *Queries) GetOrdersToReencrypt(ctx context.Context, arg GetOrdersToReencryptParams) ([]Order, error) {
+	rows, err := q.db.Query(ctx, getOrdersToReencrypt,
+		arg.DateCreated,
+		arg.ID,
+		arg.AllKeys,
+		arg.KeyID,
+		arg.N,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Order
+	for rows.Next() {
+		var i Order
+		if err := rows.Scan(
+			&i.ID,
+			&i.TrackNumber,
+			&i.Entry,
+			&i.Locale,
+			&i.InternalSignature,
+			&i.CustomerID,
+			&i.DeliveryService,
+			&i.Shardkey,
+			&i.SmID,
+			&i.DateCreated,
+			&i.OofShard,
+			&i.DeliveryName,
+			&i.DeliveryCity,
+			&i.DeliveryPhone,
+			&i.DeliveryZip,
+			&i.DeliveryAddress,
+			&i.DeliveryRegion,
+			&i.DeliveryEmail,
+			&i.Tenant,
+			&i.DeliveryKeyID,
+			&i.DeliveryDataKey,
+			&i.DeliveryEmailIndex,
+			&i.AnonymizedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getRecentOrders = `-- name: GetRecentOrders :many
-SELECT id, track_number, entry, locale, internal_signature, customer_id, delivery_service, shardkey, sm_id, date_created, oof_shard, delivery_name, delivery_city, delivery_phone, delivery_zip, delivery_address, delivery_region, delivery_email
+SELECT id, track_number, entry, locale, internal_signature, customer_id, delivery_service, shardkey, sm_id, date_created, oof_shard, delivery_name, delivery_city, delivery_phone, delivery_zip, delivery_address, delivery_region, delivery_email, tenant, delivery_key_id, delivery_data_key, delivery_email_index, anonymized_at
 FROM orders
-ORDER BY date_created DESC
-LIMIT $1
+WHERE $1::text IS NULL OR tenant = $1::text
+ORDER BY date_created DESC, id DESC
+LIMIT $2
 `
 
-func (q *Queries) GetRecentOrders(ctx context.Context, limit int32) ([]Order, error) {
-	rows, err := q.db.Query(ctx, getRecentOrders, limit)
+type GetRecentOrdersParams struct {
+	Tenant pgtype.Text
+	N      int32
+}
+
+func (q *Queries) GetRecentOrders(ctx context.Context, arg GetRecentOrdersParams) ([]Order, error) {
+	rows, err := q.db.Query(ctx, getRecentOrders, arg.Tenant, arg.N)
 	if err != nil {
 		return nil, err
 	}
@@ -168,6 +575,11 @@ func (q *Queries) GetRecentOrders(ctx context.Context, limit int32) ([]Order, er
 			&i.DeliveryAddress,
 			&i.DeliveryRegion,
 			&i.DeliveryEmail,
+			&i.Tenant,
+			&i.DeliveryKeyID,
+			&i.DeliveryDataKey,
+			&i.DeliveryEmailIndex,
+			&i.AnonymizedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -178,3 +590,67 @@ func (q *Queries) GetRecentOrders(ctx context.Context, limit int32) ([]Order, er
 	}
 	return items, nil
 }
+
+const lockOrderID = `-- name: LockOrderID :exec
+SELECT pg_advisory_xact_lock(hashtext('orders'), hashtext($1::text))
+`
+
+// Serializes creating orders with the same id until the end of the transaction.
+func (q *Queries) LockOrderID(ctx context.Context, id string) error {
+	_, err := q.db.Exec(ctx, lockOrderID, id)
+	return err
+}
+
+const orderExists = `-- name: OrderExists :one
+SELECT EXISTS (
+    SELECT 1
+    FROM orders
+    WHERE id = $1
+)
+`
+
+func (q *Queries) OrderExists(ctx context.Context, id string) (bool, error) {
+	row := q.db.QueryRow(ctx, orderExists, id)
+	var exists bool
+	err := row.Scan(&exists)
+	return exists, err
+}
+
+const updateOrderDelivery = `-- name: UpdateOrderDelivery :exec
+UPDATE orders
+SET delivery_name = $1,
+    delivery_phone = $2,
+    delivery_address = $3,
+    delivery_email = $4,
+    delivery_key_id = $5,
+    delivery_data_key = $6,
+    delivery_email_index = $7
+WHERE id = $8 AND date_created = $9
+`
+
+type UpdateOrderDeliveryParams struct {
+	DeliveryName       string
+	DeliveryPhone      string
+	DeliveryAddress    string
+	DeliveryEmail      string
+	DeliveryKeyID      pgtype.Text
+	DeliveryDataKey    []byte
+	DeliveryEmailIndex pgtype.Text
+	ID                 string
+	DateCreated        time.Time
+}
+
+func (q *Queries) UpdateOrderDelivery(ctx context.Context, arg UpdateOrderDeliveryParams) error {
+	_, err := q.db.Exec(ctx, updateOrderDelivery,
+		arg.DeliveryName,
+		arg.DeliveryPhone,
+		arg.DeliveryAddress,
+		arg.DeliveryEmail,
+		arg.DeliveryKeyID,
+		arg.DeliveryDataKey,
+		arg.DeliveryEmailIndex,
+		arg.ID,
+		arg.DateCreated,
+	)
+	return err
+}
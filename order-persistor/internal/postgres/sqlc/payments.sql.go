@@ -7,6 +7,7 @@ package sqlc
 
 import (
 	"context"
+	"time"
 
 	"github.com/shopspring/decimal"
 )
@@ -23,9 +24,10 @@ INSERT INTO payments(
     bank,
     delivery_cost,
     goods_total,
-    custom_fee
-) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
-RETURNING transaction, order_id, request_id, currency, provider, amount, payment_dt, bank, delivery_cost, goods_total, custom_fee
+    custom_fee,
+    date_created
+) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+RETURNING transaction, order_id, request_id, currency, provider, amount, payment_dt, bank, delivery_cost, goods_total, custom_fee, date_created
 `
 
 type CreatePaymentParams struct {
@@ -40,6 +42,7 @@ type CreatePaymentParams struct {
 	DeliveryCost decimal.Decimal
 	GoodsTotal   int32
 	CustomFee    decimal.Decimal
+	DateCreated  time.Time
 }
 
 func (q *Queries) CreatePayment(ctx context.Context, arg CreatePaymentParams) (Payment, error) {
@@ -55,6 +58,7 @@ func (q *Queries) CreatePayment(ctx context.Context, arg CreatePaymentParams) (P
 		arg.DeliveryCost,
 		arg.GoodsTotal,
 		arg.CustomFee,
+		arg.DateCreated,
 	)
 	var i Payment
 	err := row.Scan(
@@ -69,30 +73,55 @@ func (q *Queries) CreatePayment(ctx context.Context, arg CreatePaymentParams) (P
 		&i.DeliveryCost,
 		&i.GoodsTotal,
 		&i.CustomFee,
+		&i.DateCreated,
 	)
 	return i, err
 }
 
-const getPaymentByOrderID = `-- name: GetPaymentByOrderID :one
-SELECT transaction, order_id, request_id, currency, provider, amount, payment_dt, bank, delivery_cost, goods_total, custom_fee FROM payments
-WHERE order_id = $1
+const deletePaymentsByOrderIDs = `-- name: DeletePaymentsByOrderIDs :exec
+DELETE FROM payments
+WHERE order_id = ANY($1::text[])
 `
 
-func (q *Queries) GetPaymentByOrderID(ctx context.Context, orderID string) (Payment, error) {
-	row := q.db.QueryRow(ctx, getPaymentByOrderID, orderID)
-	var i Payment
-	err := row.Scan(
-		&i.Transaction,
-		&i.OrderID,
-		&i.RequestID,
-		&i.Currency,
-		&i.Provider,
-		&i.Amount,
-		&i.PaymentDt,
-		&i.Bank,
-		&i.DeliveryCost,
-		&i.GoodsTotal,
-		&i.CustomFee,
-	)
-	return i, err
+func (q *Queries) DeletePaymentsByOrderIDs(ctx context.Context, orderIds []string) error {
+	_, err := q.db.Exec(ctx, deletePaymentsByOrderIDs, orderIds)
+	return err
+}
+
+const getPaymentsByOrderIDs = `-- name: GetPaymentsByOrderIDs :many
+SELECT transaction, order_id, request_id, currency, provider, amount, payment_dt, bank, delivery_cost, goods_total, custom_fee, date_created FROM payments
+WHERE order_id = ANY($1::text[])
+`
+
+func (q *Queries) GetPaymentsByOrderIDs(ctx context.Context, orderIds []string) ([]Payment, error) {
+	rows, err := q.db.Query(ctx, getPaymentsByOrderIDs, orderIds)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Payment
+	for rows.Next() {
+		var i Payment
+		if err := rows.Scan(
+			&i.Transaction,
+			&i.OrderID,
+			&i.RequestID,
+			&i.Currency,
+			&i.Provider,
+			&i.Amount,
+			&i.PaymentDt,
+			&i.Bank,
+			&i.DeliveryCost,
+			&i.GoodsTotal,
+			&i.CustomFee,
+			&i.DateCreated,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
 }
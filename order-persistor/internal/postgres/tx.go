@@ -25,18 +25,26 @@ func extractExecutor(ctx context.Context, pool *pgxpool.Pool) Executor {
 	return pool
 }
 
+// withTx runs fn in a new transaction, or in a savepoint if ctx already carries a transaction,
+// so a failure of fn only rolls back its own changes.
 func withTx(ctx context.Context, pool *pgxpool.Pool, fn func(ctx context.Context) error) error {
-	tx, ok := ctx.Value(txKey{}).(pgx.Tx)
-	if !ok {
-		var err error
+	var (
+		tx  pgx.Tx
+		err error
+	)
+
+	if outer, ok := ctx.Value(txKey{}).(pgx.Tx); ok {
+		tx, err = outer.Begin(ctx)
+	} else {
 		tx, err = pool.Begin(ctx)
-		if err != nil {
-			return fmt.Errorf("error starting new transaction: %w", err)
-		}
+	}
 
-		ctx = context.WithValue(ctx, txKey{}, tx)
+	if err != nil {
+		return fmt.Errorf("error starting new transaction: %w", err)
 	}
 
+	ctx = context.WithValue(ctx, txKey{}, tx)
+
 	if err := fn(ctx); err != nil {
 		if err := tx.Rollback(ctx); err != nil {
 			return fmt.Errorf("error rolling back tx: %w", err)
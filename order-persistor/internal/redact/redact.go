@@ -0,0 +1,185 @@
+// Package redact masks personal data of customers in orders and raw order messages.
+package redact
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"order-persistor/internal/orders"
+	"strings"
+	"unicode/utf8"
+)
+
+// Mode is the way personal data is masked.
+type Mode string
+
+const (
+	// Partial keeps a few characters useful for support, e.g. "I*** I*****" or "i***@example.com".
+	Partial Mode = "partial"
+	// Hash replaces values with a short digest, so equal values can still be correlated.
+	Hash Mode = "hash"
+	// Remove replaces values with empty strings.
+	Remove Mode = "remove"
+)
+
+// hashLen is the number of hex characters kept of a digest.
+const hashLen = 16
+
+// field is a kind of personal data, masked partially in its own way.
+type field int
+
+const (
+	fieldName field = iota
+	fieldPhone
+	fieldEmail
+	fieldAddress
+	fieldID
+)
+
+// messageFields are the personal data fields of a raw order message, by path.
+var messageFields = []struct {
+	path  []string
+	field field
+}{
+	{[]string{"customer_id"}, fieldID},
+	{[]string{"delivery", "name"}, fieldName},
+	{[]string{"delivery", "phone"}, fieldPhone},
+	{[]string{"delivery", "zip"}, fieldID},
+	{[]string{"delivery", "address"}, fieldAddress},
+	{[]string{"delivery", "email"}, fieldEmail},
+}
+
+// Policy masks personal data with the mode.
+type Policy struct {
+	mode Mode
+	key  []byte
+}
+
+// NewPolicy creates a policy of the mode. Hashes are keyed with key if it is set,
+// so that values with little entropy like phones could not be recovered by brute force.
+func NewPolicy(mode Mode, key string) (*Policy, error) {
+	switch mode {
+	case Partial, Hash, Remove:
+	default:
+		return nil, fmt.Errorf("unknown redaction mode: %s", mode)
+	}
+
+	return &Policy{mode: mode, key: []byte(key)}, nil
+}
+
+// Order returns a copy of the order with personal data masked.
+// The order itself is left intact, since it may be shared with the cache.
+func (p *Policy) Order(o *orders.Order) *orders.Order {
+	res := *o
+	res.CustomerID = p.mask(fieldID, o.CustomerID)
+	res.Delivery.Name = p.mask(fieldName, o.Delivery.Name)
+	res.Delivery.Phone = p.mask(fieldPhone, o.Delivery.Phone)
+	res.Delivery.Zip = p.mask(fieldID, o.Delivery.Zip)
+	res.Delivery.Address = p.mask(fieldAddress, o.Delivery.Address)
+	res.Delivery.Email = p.mask(fieldEmail, o.Delivery.Email)
+
+	return &res
+}
+
+// Message returns the raw order message with personal data masked, suitable for logging.
+// Messages which are not JSON objects can not be masked reliably, so only their size is returned.
+func (p *Policy) Message(body []byte) string {
+	var msg map[string]any
+	if err := json.Unmarshal(body, &msg); err != nil {
+		return fmt.Sprintf("<%d bytes, not a JSON object>", len(body))
+	}
+
+	for _, f := range messageFields {
+		obj := msg
+		for _, key := range f.path[:len(f.path)-1] {
+			obj, _ = obj[key].(map[string]any)
+		}
+
+		last := f.path[len(f.path)-1]
+		if v, ok := obj[last].(string); ok {
+			obj[last] = p.mask(f.field, v)
+		} else if obj[last] != nil {
+			// values of unexpected types are not masked partially, since their text might be anything
+			obj[last] = p.mask(f.field, fmt.Sprint(obj[last]))
+		}
+	}
+
+	masked, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Sprintf("<%d bytes, could not be masked>", len(body))
+	}
+
+	return string(masked)
+}
+
+func (p *Policy) mask(f field, v string) string {
+	if v == "" {
+		return ""
+	}
+
+	switch p.mode {
+	case Hash:
+		return p.hash(v)
+	case Remove:
+		return ""
+	}
+
+	switch f {
+	case fieldName, fieldAddress:
+		words := strings.Fields(v)
+		for i, w := range words {
+			words[i] = keep(w, 1, 0)
+		}
+
+		return strings.Join(words, " ")
+	case fieldPhone:
+		return keep(v, 3, 2)
+	case fieldEmail:
+		local, domain, ok := strings.Cut(v, "@")
+		if !ok {
+			return keep(v, 1, 0)
+		}
+
+		return keep(local, 1, 0) + "@" + domain
+	default:
+		return keep(v, 1, 0)
+	}
+}
+
+func (p *Policy) hash(v string) string {
+	var sum []byte
+	if len(p.key) > 0 {
+		mac := hmac.New(sha256.New, p.key)
+		mac.Write([]byte(v))
+		sum = mac.Sum(nil)
+	} else {
+		digest := sha256.Sum256([]byte(v))
+		sum = digest[:]
+	}
+
+	return "sha256:" + hex.EncodeToString(sum)[:hashLen]
+}
+
+// keep replaces all the characters of v with asterisks except for head first and tail last ones.
+// Short values are masked entirely, so that at least half of the characters is hidden.
+func keep(v string, head, tail int) string {
+	n := utf8.RuneCountInString(v)
+	if head+tail > n/2 {
+		return strings.Repeat("*", n)
+	}
+
+	var b strings.Builder
+	i := 0
+	for _, r := range v {
+		if i < head || i >= n-tail {
+			b.WriteRune(r)
+		} else {
+			b.WriteByte('*')
+		}
+		i++
+	}
+
+	return b.String()
+}
@@ -0,0 +1,107 @@
+package redact
+
+import (
+	"order-persistor/internal/orders"
+	"strings"
+	"testing"
+
+	"github.com/lezzercringe/some-assignment/orderschema"
+)
+
+func testOrder() *orders.Order {
+	return &orders.Order{
+		Order: orderschema.Order{
+			ID:         "b563feb7b2b84b6test",
+			CustomerID: "test",
+			Delivery: orders.Delivery{
+				Name:    "Test Testov",
+				Phone:   "+9720000000",
+				Zip:     "2639809",
+				City:    "Kiryat Mozkin",
+				Address: "Ploshad Mira 15",
+				Region:  "Kraiot",
+				Email:   "test@gmail.com",
+			},
+		},
+	}
+}
+
+func TestPolicy_Order(t *testing.T) {
+	t.Parallel()
+
+	cases := map[Mode]orders.Delivery{
+		Partial: {
+			Name:    "T*** T*****",
+			Phone:   "+97******00",
+			Zip:     "2******",
+			City:    "Kiryat Mozkin",
+			Address: "P****** M*** 1*",
+			Region:  "Kraiot",
+			Email:   "t***@gmail.com",
+		},
+		Remove: {
+			City:   "Kiryat Mozkin",
+			Region: "Kraiot",
+		},
+	}
+
+	for mode, want := range cases {
+		t.Run(string(mode), func(t *testing.T) {
+			t.Parallel()
+
+			p, err := NewPolicy(mode, "")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			order := testOrder()
+			masked := p.Order(order)
+
+			if masked.Delivery != want {
+				t.Fatalf("unexpected delivery: %+v", masked.Delivery)
+			}
+
+			if order.Delivery != testOrder().Delivery {
+				t.Fatal("source order was modified")
+			}
+		})
+	}
+
+	t.Run(string(Hash), func(t *testing.T) {
+		t.Parallel()
+
+		p, _ := NewPolicy(Hash, "secret")
+		masked := p.Order(testOrder())
+		again := p.Order(testOrder())
+
+		if !strings.HasPrefix(masked.Delivery.Phone, "sha256:") || masked.Delivery.Phone != again.Delivery.Phone {
+			t.Fatalf("expected stable hash, got %q and %q", masked.Delivery.Phone, again.Delivery.Phone)
+		}
+
+		unkeyed, _ := NewPolicy(Hash, "")
+		if unkeyed.Order(testOrder()).Delivery.Phone == masked.Delivery.Phone {
+			t.Fatal("expected hash to depend on the key")
+		}
+	})
+}
+
+func TestPolicy_Message(t *testing.T) {
+	t.Parallel()
+
+	p, _ := NewPolicy(Partial, "")
+
+	msg := p.Message([]byte(`{"order_uid":"1","customer_id":"test","delivery":{"name":"Test Testov","phone":12345678,"email":"test@gmail.com"}}`))
+	for _, leaked := range []string{"Testov", "12345678", "test@"} {
+		if strings.Contains(msg, leaked) {
+			t.Fatalf("message %s contains %q", msg, leaked)
+		}
+	}
+
+	if !strings.Contains(msg, `"order_uid":"1"`) {
+		t.Fatalf("message %s lost non-personal fields", msg)
+	}
+
+	if msg := p.Message([]byte(`{"delivery":{"name":"Test Te`)); strings.Contains(msg, "Test") {
+		t.Fatalf("malformed message %s is not masked", msg)
+	}
+}
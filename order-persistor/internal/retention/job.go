@@ -0,0 +1,105 @@
+package retention
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"order-persistor/internal/config"
+	"order-persistor/internal/metrics"
+	"time"
+)
+
+type Partitions interface {
+	// DrainDefault moves the rows of the default partitions into the monthly partitions, creating the missing ones.
+	// It returns the number of moved orders.
+	DrainDefault(ctx context.Context) (int64, error)
+	CreateAhead(ctx context.Context, from time.Time, months int) error
+	ListMonths(ctx context.Context) ([]time.Time, error)
+	Detach(ctx context.Context, month time.Time, drop bool) error
+}
+
+// Job keeps monthly partitions of orders ready for upcoming data and detaches the expired ones.
+// Orders landing in the default partitions, like imported history or orders dated beyond the premade months,
+// are moved to their monthly partitions, so they are detached along with them.
+type Job struct {
+	partitions Partitions
+	cfg        config.Retention
+	logger     *slog.Logger
+	now        func() time.Time
+}
+
+func NewJob(cfg config.Retention, partitions Partitions, logger *slog.Logger) *Job {
+	return &Job{
+		partitions: partitions,
+		cfg:        cfg,
+		logger:     logger,
+		now:        time.Now,
+	}
+}
+
+// Run performs maintenance immediately and then every configured interval, blocking the calling coroutine.
+// Failed maintenance is logged and retried on the next tick.
+func (j *Job) Run(ctx context.Context) error {
+	ticker := time.NewTicker(j.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		if err := j.RunOnce(ctx); err != nil {
+			metrics.RetentionFailures.Inc()
+			j.logger.Error("retention: maintenance failed", "err", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce performs maintenance once. Failed steps do not prevent the following ones, their errors are joined.
+func (j *Job) RunOnce(ctx context.Context) error {
+	now := j.now().UTC()
+
+	var errs []error
+
+	// rows in the default partitions would prevent creating partitions of their months
+	moved, err := j.partitions.DrainDefault(ctx)
+	if err != nil {
+		errs = append(errs, fmt.Errorf("could not drain default partitions: %w", err))
+	} else if moved > 0 {
+		metrics.RetentionDefaultPartitionOrders.Add(float64(moved))
+		j.logger.Warn("retention: moved orders out of default partitions", "orders", moved)
+	}
+
+	if err := j.partitions.CreateAhead(ctx, now, j.cfg.PremakeMonths); err != nil {
+		errs = append(errs, fmt.Errorf("could not create upcoming partitions: %w", err))
+	}
+
+	if !j.cfg.Enabled {
+		return errors.Join(errs...)
+	}
+
+	months, err := j.partitions.ListMonths(ctx)
+	if err != nil {
+		return errors.Join(append(errs, fmt.Errorf("could not list partitions: %w", err))...)
+	}
+
+	cutoff := now.Add(-j.cfg.MaxAge)
+	for _, month := range months {
+		// partition is expired only when its whole month is older than the cutoff
+		if month.AddDate(0, 1, 0).After(cutoff) {
+			continue
+		}
+
+		if err := j.partitions.Detach(ctx, month, j.cfg.Mode == "drop"); err != nil {
+			errs = append(errs, fmt.Errorf("could not detach partition of %s: %w", month.Format("2006-01"), err))
+			continue
+		}
+
+		j.logger.Info("retention: detached partition", "month", month.Format("2006-01"), "mode", j.cfg.Mode)
+	}
+
+	return errors.Join(errs...)
+}
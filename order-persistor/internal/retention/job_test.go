@@ -0,0 +1,138 @@
+package retention
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"order-persistor/internal/config"
+	"order-persistor/internal/mocks"
+	"testing"
+	"time"
+
+	"go.uber.org/mock/gomock"
+)
+
+func newTestJob(cfg config.Retention, partitions Partitions, now time.Time) *Job {
+	j := NewJob(cfg, partitions, slog.New(slog.DiscardHandler))
+	j.now = func() time.Time { return now }
+	return j
+}
+
+func TestJob_RunOnce(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2025, 8, 15, 12, 0, 0, 0, time.UTC)
+	month := func(y int, m time.Month) time.Time {
+		return time.Date(y, m, 1, 0, 0, 0, 0, time.UTC)
+	}
+
+	t.Run("detaches only fully expired months", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		partitions := mocks.NewMockPartitions(ctrl)
+		job := newTestJob(config.Retention{
+			PremakeMonths: 2,
+			Enabled:       true,
+			MaxAge:        60 * 24 * time.Hour,
+			Mode:          "drop",
+		}, partitions, now)
+
+		partitions.EXPECT().DrainDefault(gomock.Any()).Return(int64(0), nil)
+		partitions.EXPECT().CreateAhead(gomock.Any(), now, 2).Return(nil)
+		partitions.EXPECT().
+			ListMonths(gomock.Any()).
+			Return([]time.Time{month(2025, 5), month(2025, 6), month(2025, 7)}, nil)
+
+		// cutoff is 2025-06-16, so only may is entirely older
+		partitions.EXPECT().Detach(gomock.Any(), month(2025, 5), true).Return(nil)
+
+		if err := job.RunOnce(context.Background()); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("only creates partitions if retention is disabled", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		partitions := mocks.NewMockPartitions(ctrl)
+		job := newTestJob(config.Retention{PremakeMonths: 1}, partitions, now)
+
+		partitions.EXPECT().DrainDefault(gomock.Any()).Return(int64(0), nil)
+		partitions.EXPECT().CreateAhead(gomock.Any(), now, 1).Return(nil)
+
+		if err := job.RunOnce(context.Background()); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("detach error is propagated", func(t *testing.T) {
+		detachErr := errors.New("some obscure error")
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		partitions := mocks.NewMockPartitions(ctrl)
+		job := newTestJob(config.Retention{
+			PremakeMonths: 1,
+			Enabled:       true,
+			MaxAge:        24 * time.Hour,
+			Mode:          "detach",
+		}, partitions, now)
+
+		partitions.EXPECT().DrainDefault(gomock.Any()).Return(int64(0), nil)
+		partitions.EXPECT().CreateAhead(gomock.Any(), now, 1).Return(nil)
+		partitions.EXPECT().ListMonths(gomock.Any()).Return([]time.Time{month(2025, 1), month(2025, 2)}, nil)
+		partitions.EXPECT().Detach(gomock.Any(), month(2025, 1), false).Return(detachErr)
+		// failure of a month does not stop detaching the others
+		partitions.EXPECT().Detach(gomock.Any(), month(2025, 2), false).Return(nil)
+
+		if err := job.RunOnce(context.Background()); !errors.Is(err, detachErr) {
+			t.Fatalf("detach error was not propagated: %v", err)
+		}
+	})
+
+	t.Run("failed maintenance steps do not stop the others", func(t *testing.T) {
+		drainErr := errors.New("lock timeout")
+		createErr := errors.New("partition would overlap")
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		partitions := mocks.NewMockPartitions(ctrl)
+		job := newTestJob(config.Retention{
+			PremakeMonths: 1,
+			Enabled:       true,
+			MaxAge:        24 * time.Hour,
+			Mode:          "drop",
+		}, partitions, now)
+
+		partitions.EXPECT().DrainDefault(gomock.Any()).Return(int64(0), drainErr)
+		partitions.EXPECT().CreateAhead(gomock.Any(), now, 1).Return(createErr)
+		partitions.EXPECT().ListMonths(gomock.Any()).Return([]time.Time{month(2025, 1)}, nil)
+		partitions.EXPECT().Detach(gomock.Any(), month(2025, 1), true).Return(nil)
+
+		err := job.RunOnce(context.Background())
+		if !errors.Is(err, drainErr) || !errors.Is(err, createErr) {
+			t.Fatalf("expected both errors to be reported, got %v", err)
+		}
+	})
+
+	t.Run("default partition is drained before creating partitions", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		partitions := mocks.NewMockPartitions(ctrl)
+		job := newTestJob(config.Retention{PremakeMonths: 1}, partitions, now)
+
+		gomock.InOrder(
+			partitions.EXPECT().DrainDefault(gomock.Any()).Return(int64(3), nil),
+			partitions.EXPECT().CreateAhead(gomock.Any(), now, 1).Return(nil),
+		)
+
+		if err := job.RunOnce(context.Background()); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+}
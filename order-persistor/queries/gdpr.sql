@@ -0,0 +1,3 @@
+-- name: CreateGDPRAuditEntry :exec
+INSERT INTO gdpr_audit (action, subject_type, subject_hash, actor, order_ids)
+VALUES ($1, $2, $3, $4, $5);
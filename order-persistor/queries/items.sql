@@ -11,11 +11,17 @@ INSERT INTO items(
     total_price,
     nm_id,
     brand,
-    status
-) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+    status,
+    date_created
+) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
 RETURNING *;
 
--- name: GetItemsByOrderID :many
+-- name: GetItemsByOrderIDs :many
 SELECT *
 FROM items
-WHERE order_id = $1;
+WHERE order_id = ANY(sqlc.arg(order_ids)::text[])
+ORDER BY order_id, id;
+
+-- name: DeleteItemsByOrderIDs :exec
+DELETE FROM items
+WHERE order_id = ANY(sqlc.arg(order_ids)::text[]);
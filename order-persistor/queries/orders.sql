@@ -17,18 +17,116 @@ INSERT INTO orders (
     delivery_address,
     delivery_region,
     delivery_email,
-    delivery_city
+    delivery_city,
+    tenant,
+    delivery_key_id,
+    delivery_data_key,
+    delivery_email_index
 )
-VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22)
 RETURNING *;
 
+-- name: LockOrderID :exec
+-- Serializes creating orders with the same id until the end of the transaction.
+SELECT pg_advisory_xact_lock(hashtext('orders'), hashtext(sqlc.arg(id)::text));
+
+-- name: OrderExists :one
+SELECT EXISTS (
+    SELECT 1
+    FROM orders
+    WHERE id = $1
+);
+
+-- Reads are limited to a single tenant if it is given, and span all the tenants otherwise.
+
 -- name: GetOrderByID :one
 SELECT *
 FROM orders
-WHERE id = $1;
+WHERE id = sqlc.arg(id)
+  AND (sqlc.narg(tenant)::text IS NULL OR tenant = sqlc.narg(tenant)::text);
 
 -- name: GetRecentOrders :many
 SELECT *
 FROM orders
-ORDER BY date_created DESC
-LIMIT $1;
+WHERE sqlc.narg(tenant)::text IS NULL OR tenant = sqlc.narg(tenant)::text
+ORDER BY date_created DESC, id DESC
+LIMIT sqlc.arg(n);
+
+-- name: GetOrdersBefore :many
+SELECT *
+FROM orders
+WHERE (date_created, id) < (sqlc.arg(date_created)::timestamptz, sqlc.arg(id)::text)
+  AND (sqlc.narg(tenant)::text IS NULL OR tenant = sqlc.narg(tenant)::text)
+ORDER BY date_created DESC, id DESC
+LIMIT sqlc.arg(n);
+
+-- Emails are searched by blind index when delivery data is encrypted, and by plain value otherwise.
+
+-- name: GetOrdersByEmailIndex :many
+SELECT *
+FROM orders
+WHERE (delivery_email_index = sqlc.arg(email_index)::text
+    OR (delivery_key_id IS NULL AND lower(delivery_email) = lower(sqlc.arg(email)::text)))
+  AND (sqlc.narg(tenant)::text IS NULL OR tenant = sqlc.narg(tenant)::text)
+ORDER BY date_created DESC, id DESC
+LIMIT sqlc.arg(n);
+
+-- name: GetOrdersByEmail :many
+SELECT *
+FROM orders
+WHERE delivery_email = sqlc.arg(email)::text
+  AND (sqlc.narg(tenant)::text IS NULL OR tenant = sqlc.narg(tenant)::text)
+ORDER BY date_created DESC, id DESC
+LIMIT sqlc.arg(n);
+
+-- name: GetOrdersByCustomerID :many
+SELECT *
+FROM orders
+WHERE customer_id = sqlc.arg(customer_id)::text
+  AND (sqlc.narg(tenant)::text IS NULL OR tenant = sqlc.narg(tenant)::text)
+ORDER BY date_created DESC, id DESC
+LIMIT sqlc.arg(n);
+
+-- name: AnonymizeOrders :execrows
+-- Delivery personal data is erased, while the order with its items and payment is kept for accounting.
+UPDATE orders
+SET delivery_name = '',
+    delivery_phone = '',
+    delivery_zip = '',
+    delivery_address = '',
+    delivery_email = '',
+    delivery_key_id = NULL,
+    delivery_data_key = NULL,
+    delivery_email_index = NULL,
+    anonymized_at = now()
+WHERE id = ANY(sqlc.arg(ids)::text[]);
+
+-- name: GetOrdersToReencrypt :many
+-- Orders are returned in ascending order after the cursor, either all of them
+-- or only those not encrypted with the given key.
+SELECT *
+FROM orders
+WHERE (date_created, id) > (sqlc.arg(date_created)::timestamptz, sqlc.arg(id)::text)
+  AND (sqlc.arg(all_keys)::bool OR delivery_key_id IS DISTINCT FROM sqlc.arg(key_id)::text)
+ORDER BY date_created, id
+LIMIT sqlc.arg(n);
+
+-- name: UpdateOrderDelivery :exec
+UPDATE orders
+SET delivery_name = sqlc.arg(delivery_name),
+    delivery_phone = sqlc.arg(delivery_phone),
+    delivery_address = sqlc.arg(delivery_address),
+    delivery_email = sqlc.arg(delivery_email),
+    delivery_key_id = sqlc.narg(delivery_key_id),
+    delivery_data_key = sqlc.narg(delivery_data_key),
+    delivery_email_index = sqlc.narg(delivery_email_index)
+WHERE id = sqlc.arg(id) AND date_created = sqlc.arg(date_created);
+
+-- name: CountOrdersBefore :one
+SELECT count(*)
+FROM orders
+WHERE date_created < $1;
+
+-- name: DeleteOrdersByIDs :execrows
+DELETE FROM orders
+WHERE id = ANY(sqlc.arg(ids)::text[]);
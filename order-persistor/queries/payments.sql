@@ -10,10 +10,15 @@ INSERT INTO payments(
     bank,
     delivery_cost,
     goods_total,
-    custom_fee
-) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+    custom_fee,
+    date_created
+) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
 RETURNING *;
 
--- name: GetPaymentByOrderID :one
+-- name: GetPaymentsByOrderIDs :many
 SELECT * FROM payments
-WHERE order_id = $1;
+WHERE order_id = ANY(sqlc.arg(order_ids)::text[]);
+
+-- name: DeletePaymentsByOrderIDs :exec
+DELETE FROM payments
+WHERE order_id = ANY(sqlc.arg(order_ids)::text[]);
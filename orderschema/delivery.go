@@ -1,4 +1,4 @@
-package orders
+package orderschema
 
 type Delivery struct {
 	Name    string `json:"name" validate:"required"`
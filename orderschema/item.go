@@ -1,4 +1,4 @@
-package orders
+package orderschema
 
 import "github.com/shopspring/decimal"
 
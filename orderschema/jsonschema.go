@@ -0,0 +1,79 @@
+package orderschema
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/xeipuuv/gojsonschema"
+)
+
+var ErrSchemaViolation = errors.New("order does not match JSON schema")
+
+// SchemaValidator checks raw order messages against the JSON Schema.
+type SchemaValidator struct {
+	schema *gojsonschema.Schema
+}
+
+// NewSchemaValidator compiles the JSON Schema. Strict validator also rejects
+// the fields which are not described by the schema.
+func NewSchemaValidator(strict bool) (*SchemaValidator, error) {
+	doc := jsonschemaDocument()
+	if strict {
+		disallowAdditional(doc)
+	}
+
+	schema, err := gojsonschema.NewSchema(gojsonschema.NewGoLoader(doc))
+	if err != nil {
+		return nil, fmt.Errorf("could not compile order JSON schema: %w", err)
+	}
+
+	return &SchemaValidator{schema: schema}, nil
+}
+
+// Validate returns an error wrapping ErrSchemaViolation, listing all the violations, if raw does not match the schema.
+func (v *SchemaValidator) Validate(raw []byte) error {
+	res, err := v.schema.Validate(gojsonschema.NewBytesLoader(raw))
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrSchemaViolation, err)
+	}
+
+	if res.Valid() {
+		return nil
+	}
+
+	violations := make([]string, 0, len(res.Errors()))
+	for _, e := range res.Errors() {
+		violations = append(violations, e.String())
+	}
+
+	return fmt.Errorf("%w: %s", ErrSchemaViolation, strings.Join(violations, "; "))
+}
+
+func jsonschemaDocument() map[string]any {
+	var doc map[string]any
+	if err := json.Unmarshal(jsonSchema, &doc); err != nil {
+		panic("embedded order JSON schema is invalid: " + err.Error())
+	}
+
+	return doc
+}
+
+// disallowAdditional forbids properties which are not described in every object of the schema.
+func disallowAdditional(node any) {
+	switch n := node.(type) {
+	case map[string]any:
+		if _, ok := n["properties"]; ok {
+			n["additionalProperties"] = false
+		}
+
+		for _, child := range n {
+			disallowAdditional(child)
+		}
+	case []any:
+		for _, child := range n {
+			disallowAdditional(child)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package orderschema
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"testing"
+)
+
+// sampleWith returns the sample order after fn changed its generic JSON representation.
+func sampleWith(t *testing.T, fn func(order map[string]any)) []byte {
+	t.Helper()
+
+	raw, err := os.ReadFile("testdata/order.json")
+	if err != nil {
+		t.Fatalf("could not read sample order: %v", err)
+	}
+
+	var order map[string]any
+	if err := json.Unmarshal(raw, &order); err != nil {
+		t.Fatalf("could not decode sample order: %v", err)
+	}
+
+	fn(order)
+
+	res, _ := json.Marshal(order)
+	return res
+}
+
+func TestSchemaValidator_Validate(t *testing.T) {
+	t.Parallel()
+
+	lax, err := NewSchemaValidator(false)
+	if err != nil {
+		t.Fatalf("could not create validator: %v", err)
+	}
+
+	strict, err := NewSchemaValidator(true)
+	if err != nil {
+		t.Fatalf("could not create strict validator: %v", err)
+	}
+
+	cases := []struct {
+		name        string
+		change      func(order map[string]any)
+		validLax    bool
+		validStrict bool
+	}{
+		{
+			name:        "sample order",
+			change:      func(map[string]any) {},
+			validLax:    true,
+			validStrict: true,
+		},
+		{
+			name: "decimal strings",
+			change: func(o map[string]any) {
+				o["payment"].(map[string]any)["amount"] = "1817.00"
+			},
+			validLax:    true,
+			validStrict: true,
+		},
+		{
+			name:        "unknown order field",
+			change:      func(o map[string]any) { o["comment"] = "leave at the door" },
+			validLax:    true,
+			validStrict: false,
+		},
+		{
+			name: "unknown item field",
+			change: func(o map[string]any) {
+				o["items"].([]any)[0].(map[string]any)["color"] = "black"
+			},
+			validLax:    true,
+			validStrict: false,
+		},
+		{
+			name:   "missing order_uid",
+			change: func(o map[string]any) { delete(o, "order_uid") },
+		},
+		{
+			name: "wrong amount type",
+			change: func(o map[string]any) {
+				o["payment"].(map[string]any)["amount"] = true
+			},
+		},
+		{
+			name:   "bad date",
+			change: func(o map[string]any) { o["date_created"] = "yesterday" },
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			raw := sampleWith(t, c.change)
+
+			for _, v := range []struct {
+				validator *SchemaValidator
+				valid     bool
+				mode      string
+			}{{lax, c.validLax, "lax"}, {strict, c.validStrict, "strict"}} {
+				err := v.validator.Validate(raw)
+
+				if v.valid && err != nil {
+					t.Errorf("%s: unexpected error: %v", v.mode, err)
+				}
+
+				if !v.valid && !errors.Is(err, ErrSchemaViolation) {
+					t.Errorf("%s: expected schema violation, got: %v", v.mode, err)
+				}
+			}
+		})
+	}
+}
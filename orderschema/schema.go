@@ -0,0 +1,17 @@
+package orderschema
+
+import (
+	_ "embed"
+	"slices"
+)
+
+// Version is the version of the order schema, bumped on every incompatible change.
+const Version = "1"
+
+//go:embed order.schema.json
+var jsonSchema []byte
+
+// JSONSchema returns the JSON Schema (draft-07) of an order.
+func JSONSchema() []byte {
+	return slices.Clone(jsonSchema)
+}
@@ -0,0 +1,135 @@
+package orderschema
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+)
+
+type schemaObject struct {
+	Properties map[string]json.RawMessage `json:"properties"`
+	Required   []string                   `json:"required"`
+}
+
+type schemaDocument struct {
+	schemaObject
+	Definitions map[string]schemaObject `json:"definitions"`
+}
+
+// fieldsOf returns JSON names of the struct fields, and names of those which are required by validation tags.
+func fieldsOf(t reflect.Type) (names, required []string) {
+	for _, f := range reflect.VisibleFields(t) {
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		names = append(names, name)
+
+		if slices.Contains(strings.Split(f.Tag.Get("validate"), ","), "required") {
+			required = append(required, name)
+		}
+	}
+
+	return names, required
+}
+
+func TestJSONSchema_MatchesTypes(t *testing.T) {
+	t.Parallel()
+
+	var doc schemaDocument
+	if err := json.Unmarshal(JSONSchema(), &doc); err != nil {
+		t.Fatalf("invalid JSON schema: %v", err)
+	}
+
+	cases := map[string]struct {
+		typ    reflect.Type
+		schema schemaObject
+	}{
+		"order":    {reflect.TypeFor[Order](), doc.schemaObject},
+		"delivery": {reflect.TypeFor[Delivery](), doc.Definitions["delivery"]},
+		"payment":  {reflect.TypeFor[Payment](), doc.Definitions["payment"]},
+		"item":     {reflect.TypeFor[Item](), doc.Definitions["item"]},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			names, required := fieldsOf(c.typ)
+
+			for _, n := range names {
+				if _, ok := c.schema.Properties[n]; !ok {
+					t.Errorf("field %q is missing in JSON schema", n)
+				}
+			}
+
+			for n := range c.schema.Properties {
+				if !slices.Contains(names, n) {
+					t.Errorf("JSON schema property %q has no field", n)
+				}
+			}
+
+			for _, n := range required {
+				if !slices.Contains(c.schema.Required, n) {
+					t.Errorf("required field %q is not required by JSON schema", n)
+				}
+			}
+		})
+	}
+}
+
+func TestOrder_RoundTrip(t *testing.T) {
+	t.Parallel()
+
+	raw, err := os.ReadFile("testdata/order.json")
+	if err != nil {
+		t.Fatalf("could not read sample order: %v", err)
+	}
+
+	var order Order
+	if err := json.Unmarshal(raw, &order); err != nil {
+		t.Fatalf("could not decode sample order: %v", err)
+	}
+
+	if err := Validate(context.Background(), &order); err != nil {
+		t.Fatalf("sample order is invalid: %v", err)
+	}
+
+	encoded, err := json.Marshal(order)
+	if err != nil {
+		t.Fatalf("could not encode order: %v", err)
+	}
+
+	// every field of the sample should survive decoding, so no field is silently dropped
+	var want, got map[string]any
+	_ = json.Unmarshal(raw, &want)
+	_ = json.Unmarshal(encoded, &got)
+
+	if !reflect.DeepEqual(keysOf(want), keysOf(got)) {
+		t.Fatalf("fields differ after round trip:\nwant %v\ngot  %v", keysOf(want), keysOf(got))
+	}
+}
+
+// keysOf returns sorted paths of all the object keys in v.
+func keysOf(v any) []string {
+	var res []string
+
+	var walk func(prefix string, v any)
+	walk = func(prefix string, v any) {
+		switch v := v.(type) {
+		case map[string]any:
+			for k, child := range v {
+				res = append(res, prefix+k)
+				walk(prefix+k+".", child)
+			}
+		case []any:
+			for _, child := range v {
+				walk(prefix+"[].", child)
+			}
+		}
+	}
+
+	walk("", v)
+	slices.Sort(res)
+
+	return slices.Compact(res)
+}
@@ -0,0 +1,14 @@
+package orderschema
+
+import (
+	"context"
+
+	"github.com/go-playground/validator/v10"
+)
+
+var validate = validator.New()
+
+// Validate checks the order against the rules every persisted order has to satisfy.
+func Validate(ctx context.Context, o *Order) error {
+	return validate.StructCtx(ctx, o)
+}
@@ -0,0 +1,173 @@
+package producer
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// Prices and thresholds of the catalog are in USD and converted into the country currency.
+const (
+	freeDeliveryFrom = 50.0
+	dutyFreeLimit    = 200.0
+)
+
+var customFeeRate = decimal.NewFromFloat(0.15)
+
+type city struct {
+	name   string
+	region string
+}
+
+type country struct {
+	locale   string
+	currency string
+	// usdRate is the price of one USD in the currency
+	usdRate float64
+	// precision is the number of decimal places prices are rounded to
+	precision        int32
+	phonePrefix      string
+	phoneDigits      int
+	zipDigits        int
+	cities           []city
+	streets          []string
+	firstNames       []string
+	lastNames        []string
+	emailDomains     []string
+	banks            []string
+	deliveryServices []string
+	deliveryCosts    []decimal.Decimal
+}
+
+func (c *country) price(usd float64) decimal.Decimal {
+	return decimal.NewFromFloat(usd * c.usdRate).Round(c.precision)
+}
+
+func amounts(values ...int64) []decimal.Decimal {
+	res := make([]decimal.Decimal, 0, len(values))
+	for _, v := range values {
+		res = append(res, decimal.NewFromInt(v))
+	}
+
+	return res
+}
+
+// countries are weighted by repeating the most frequent ones.
+var countries = func() []*country {
+	ru := &country{
+		locale: "ru", currency: "RUB", usdRate: 90, precision: 0,
+		phonePrefix: "+79", phoneDigits: 9, zipDigits: 6,
+		cities: []city{
+			{"Москва", "Москва"}, {"Санкт-Петербург", "Санкт-Петербург"}, {"Казань", "Татарстан"},
+			{"Екатеринбург", "Свердловская область"}, {"Новосибирск", "Новосибирская область"},
+			{"Краснодар", "Краснодарский край"}, {"Подольск", "Московская область"},
+		},
+		streets:          []string{"ул. Ленина", "пр. Мира", "ул. Гагарина", "ул. Садовая", "Ленинградский пр."},
+		firstNames:       []string{"Анна", "Иван", "Мария", "Алексей", "Екатерина", "Дмитрий", "Ольга", "Сергей"},
+		lastNames:        []string{"Иванова", "Смирнов", "Кузнецова", "Попов", "Соколова", "Лебедев", "Новикова"},
+		emailDomains:     []string{"mail.ru", "yandex.ru", "gmail.com", "bk.ru"},
+		banks:            []string{"sber", "tinkoff", "alpha", "vtb"},
+		deliveryServices: []string{"wb-pvz", "wb-courier", "cdek", "boxberry"},
+		deliveryCosts:    amounts(0, 99, 199, 299),
+	}
+
+	kz := &country{
+		locale: "kk", currency: "KZT", usdRate: 480, precision: 0,
+		phonePrefix: "+77", phoneDigits: 9, zipDigits: 6,
+		cities:           []city{{"Алматы", "Алматы"}, {"Астана", "Астана"}, {"Шымкент", "Шымкент"}, {"Караганда", "Карагандинская область"}},
+		streets:          []string{"пр. Абая", "ул. Толе би", "пр. Назарбаева", "ул. Жибек Жолы"},
+		firstNames:       []string{"Айгерим", "Нурлан", "Динара", "Ерлан", "Асель"},
+		lastNames:        []string{"Ахметова", "Сериков", "Жумабаева", "Нурланов"},
+		emailDomains:     []string{"mail.kz", "gmail.com", "mail.ru"},
+		banks:            []string{"kaspi", "halyk", "jusan"},
+		deliveryServices: []string{"wb-pvz", "kazpost"},
+		deliveryCosts:    amounts(0, 490, 990),
+	}
+
+	by := &country{
+		locale: "be", currency: "BYN", usdRate: 3.2, precision: 2,
+		phonePrefix: "+37529", phoneDigits: 7, zipDigits: 6,
+		cities:           []city{{"Минск", "Минск"}, {"Гомель", "Гомельская область"}, {"Брест", "Брестская область"}},
+		streets:          []string{"пр. Независимости", "ул. Немига", "ул. Советская"},
+		firstNames:       []string{"Алена", "Андрей", "Наталья", "Павел"},
+		lastNames:        []string{"Ковалева", "Новик", "Шевчук", "Мельник"},
+		emailDomains:     []string{"tut.by", "gmail.com", "mail.ru"},
+		banks:            []string{"belarusbank", "priorbank"},
+		deliveryServices: []string{"wb-pvz", "belpost"},
+		deliveryCosts:    amounts(0, 3, 6),
+	}
+
+	am := &country{
+		locale: "hy", currency: "AMD", usdRate: 390, precision: 0,
+		phonePrefix: "+3749", phoneDigits: 7, zipDigits: 4,
+		cities:           []city{{"Ереван", "Ереван"}, {"Гюмри", "Ширак"}},
+		streets:          []string{"пр. Маштоца", "ул. Абовяна", "ул. Туманяна"},
+		firstNames:       []string{"Ани", "Арман", "Нарине", "Давид"},
+		lastNames:        []string{"Петросян", "Саргсян", "Акопян"},
+		emailDomains:     []string{"mail.am", "gmail.com"},
+		banks:            []string{"ameriabank", "acba"},
+		deliveryServices: []string{"wb-pvz", "haypost"},
+		deliveryCosts:    amounts(0, 500, 1000),
+	}
+
+	us := &country{
+		locale: "en", currency: "USD", usdRate: 1, precision: 2,
+		phonePrefix: "+1", phoneDigits: 10, zipDigits: 5,
+		cities:           []city{{"New York", "NY"}, {"Austin", "TX"}, {"Seattle", "WA"}},
+		streets:          []string{"Main St", "Oak Ave", "Maple Dr", "2nd St"},
+		firstNames:       []string{"Emily", "James", "Olivia", "Michael"},
+		lastNames:        []string{"Smith", "Johnson", "Brown", "Miller"},
+		emailDomains:     []string{"gmail.com", "outlook.com", "yahoo.com"},
+		banks:            []string{"chase", "citi", "wells-fargo"},
+		deliveryServices: []string{"ups", "fedex", "usps"},
+		deliveryCosts:    []decimal.Decimal{decimal.Zero, decimal.RequireFromString("4.99"), decimal.RequireFromString("9.99")},
+	}
+
+	return []*country{ru, ru, ru, ru, ru, ru, kz, kz, by, am, us}
+}()
+
+type product struct {
+	name  string
+	brand string
+	price float64
+	sizes []string
+}
+
+var (
+	clothesSizes = []string{"XS", "S", "M", "L", "XL"}
+	shoeSizes    = []string{"36", "37", "38", "39", "40", "41", "42", "43", "44"}
+	noSize       = []string{"0"}
+)
+
+var catalog = []product{
+	{"Mascaras", "Vivienne Sabo", 5, noSize},
+	{"Футболка", "Befree", 12, clothesSizes},
+	{"Джинсы", "Gloria Jeans", 35, clothesSizes},
+	{"Кроссовки", "Nike", 110, shoeSizes},
+	{"Кеды", "Converse", 70, shoeSizes},
+	{"Куртка демисезонная", "Finn Flare", 140, clothesSizes},
+	{"Наушники беспроводные", "Xiaomi", 45, noSize},
+	{"Смартфон", "Samsung", 420, noSize},
+	{"Чехол для телефона", "Baseus", 6, noSize},
+	{"Шампунь", "Head & Shoulders", 7, noSize},
+	{"Конструктор", "LEGO", 60, noSize},
+	{"Сковорода", "Tefal", 38, noSize},
+	{"Постельное белье", "Ikea", 30, []string{"1.5", "2", "евро"}},
+	{"Книга", "Эксмо", 9, noSize},
+}
+
+// sales are discount percents, most of the items are sold without a discount.
+var sales = []int{0, 0, 0, 0, 5, 10, 15, 20, 30, 50}
+
+var providers = []string{"wbpay", "wbpay", "wbpay", "card", "sbp"}
+
+// invalidations break the order so that the consumer rejects it.
+var invalidations = []func(o *Order){
+	func(o *Order) { o.ID = "" },
+	func(o *Order) { o.TrackNumber = "" },
+	func(o *Order) { o.Delivery.Email = "" },
+	func(o *Order) { o.Payment.Transaction = "" },
+	func(o *Order) { o.OOFShard = "one" },
+	func(o *Order) { o.SMID = 0 },
+	func(o *Order) { o.CreatedAt = time.Time{} },
+}
@@ -1,23 +1,89 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"flag"
+	"fmt"
 	"log/slog"
 	"os"
+	"os/signal"
 	"producer"
+	"strings"
+	"time"
 
+	"github.com/shopspring/decimal"
 	"gopkg.in/yaml.v3"
 )
 
+const (
+	modeFake  = "fake"
+	modeFile  = "file"
+	modeEdge  = "edge"
+	modeLoad  = "load"
+	modeUsage = "fake, file, edge or load"
+
+	// maxRate keeps the interval between messages in load mode above zero.
+	maxRate = int(time.Second)
+)
+
 var (
 	configPath   string
+	mode         string
 	messageCount int
+	fixturesPath string
+	replay       bool
+	replaySpeed  float64
+	edgeCases    string
+	load         producer.LoadConfig
+	keyStrategy  string
+	partition    int
+	headers      headerFlags
+	seed         uint64
+	invalid      float64
 )
 
+// headerFlags collects repeated -header key=value flags.
+type headerFlags map[string]string
+
+func (h headerFlags) String() string {
+	return fmt.Sprint(map[string]string(h))
+}
+
+func (h headerFlags) Set(v string) error {
+	key, value, ok := strings.Cut(v, "=")
+	if !ok || key == "" {
+		return fmt.Errorf("header should be key=value, got %q", v)
+	}
+
+	h[key] = value
+	return nil
+}
+
 func init() {
+	// orders are sent with numeric money fields, the same as the upstream systems send them
+	decimal.MarshalJSONWithoutQuotes = true
+
 	flag.StringVar(&configPath, "config", "config.yaml", "config file path")
-	flag.IntVar(&messageCount, "count", 1, "count of messages to send (0, 1, 2...)")
+	flag.StringVar(&mode, "mode", modeFake, "what to send: "+modeUsage)
+	flag.IntVar(&messageCount, "count", 1, "count of fake messages to send (0, 1, 2...)")
+	flag.StringVar(&fixturesPath, "path", "fixtures", "NDJSON file or directory of NDJSON files to send in file mode")
+	flag.BoolVar(&replay, "replay", false, "keep the original timing between messages by their date_created in file mode")
+	flag.Float64Var(&replaySpeed, "speed", 1, "replay speed multiplier, e.g. 10 sends messages ten times faster")
+	flag.StringVar(&edgeCases, "cases", "", "comma-separated edge cases to send in edge mode, all if empty: "+
+		strings.Join(producer.EdgeCaseNames(), ", "))
+	flag.IntVar(&load.Rate, "rate", 100, "target messages per second in load mode, 0 for unlimited")
+	flag.DurationVar(&load.Duration, "duration", time.Minute, "how long to produce messages in load mode")
+	flag.IntVar(&load.Concurrency, "concurrency", 4, "count of concurrent senders in load mode")
+	flag.StringVar(&keyStrategy, "key", "", "message key: order_uid, customer_id, shardkey or none, overrides config")
+	flag.IntVar(&partition, "partition", -1, "partition to send all messages to, overrides config")
+
+	flag.Uint64Var(&seed, "seed", 0, "seed of fake orders generator, overrides config")
+	flag.Float64Var(&invalid, "invalid", -1, "fraction of invalid fake orders in [0, 1], overrides config")
+
+	headers = headerFlags{}
+	flag.Var(headers, "header", "key=value header added to every message, can be repeated, overrides config")
 }
 
 func main() {
@@ -35,17 +101,120 @@ func main() {
 		os.Exit(1)
 	}
 
+	if err := validateFlags(); err != nil {
+		slog.Error("invalid flags", "err", err)
+		os.Exit(1)
+	}
+
+	applyFlags(&cfg)
+
 	p, err := producer.NewProducer(cfg)
 	if err != nil {
 		slog.Error("could not create producer", "err", err)
 		os.Exit(1)
 	}
 
-	defer p.Close()
-	go p.Start()
+	gen, err := producer.NewGenerator(cfg.Generator)
+	if err != nil {
+		slog.Error("could not create orders generator", "err", err)
+		os.Exit(1)
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+
+	switch mode {
+	case modeFake:
+		err = produceFake(ctx, p, gen)
+	case modeFile:
+		err = produceFixtures(ctx, p)
+	case modeEdge:
+		err = produceEdgeCases(ctx, p)
+	case modeLoad:
+		p.RunLoad(ctx, load, func() []byte {
+			msg, _ := json.Marshal(gen.Next())
+			return msg
+		})
+	}
+
+	p.Close()
 
+	report := p.Report()
+	slog.Info("delivery summary", "mode", mode, "report", report)
+
+	if err != nil {
+		slog.Error("could not produce messages", "mode", mode, "err", err)
+		os.Exit(1)
+	}
+
+	if report.Failed > 0 || report.Undelivered > 0 {
+		os.Exit(1)
+	}
+}
+
+// applyFlags overrides the config with the message options given on the command line.
+func applyFlags(cfg *producer.Config) {
+	if keyStrategy != "" {
+		cfg.Key = keyStrategy
+	}
+
+	if partition >= 0 {
+		p := int32(partition)
+		cfg.Partition = &p
+	}
+
+	if len(headers) > 0 && cfg.Headers == nil {
+		cfg.Headers = map[string]string{}
+	}
+
+	for key, value := range headers {
+		cfg.Headers[key] = value
+	}
+
+	if seed != 0 {
+		cfg.Generator.Seed = seed
+	}
+
+	if invalid >= 0 {
+		cfg.Generator.InvalidFraction = invalid
+	}
+}
+
+func validateFlags() error {
+	switch mode {
+	case modeFake, modeFile, modeEdge, modeLoad:
+	default:
+		return fmt.Errorf("unknown mode %q, expected %s", mode, modeUsage)
+	}
+
+	if replaySpeed <= 0 {
+		return errors.New("speed should be > 0")
+	}
+
+	if load.Rate < 0 || load.Rate > maxRate {
+		return fmt.Errorf("rate should be in [0, %d]", maxRate)
+	}
+
+	if load.Duration <= 0 {
+		return errors.New("duration should be > 0")
+	}
+
+	if load.Concurrency <= 0 {
+		return errors.New("concurrency should be > 0")
+	}
+
+	return nil
+}
+
+// produceFake, produceFixtures and produceEdgeCases stop once ctx is done, returning its error,
+// so that an interrupted run still closes the producer and reports the delivery summary.
+func produceFake(ctx context.Context, p *producer.Producer, gen *producer.Generator) error {
 	for range messageCount {
-		order := producer.GenerateOrder()
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		order := gen.Next()
 		msg, _ := json.Marshal(order)
 
 		if err := p.Produce(msg); err != nil {
@@ -55,4 +224,79 @@ func main() {
 
 		slog.Info("produced order", "id", order.ID)
 	}
+
+	return nil
+}
+
+func produceFixtures(ctx context.Context, p *producer.Producer) error {
+	fixtures, err := producer.ReadFixtures(fixturesPath)
+	if err != nil {
+		return fmt.Errorf("could not read fixtures: %w", err)
+	}
+
+	for i, f := range fixtures {
+		var delay time.Duration
+		if replay && i > 0 {
+			delay = producer.ReplayDelay(fixtures[i-1], f, replaySpeed)
+		}
+
+		if err := wait(ctx, delay); err != nil {
+			return err
+		}
+
+		if err := p.Produce(f.Value); err != nil {
+			slog.Error("failed producing message", "source", f.Source, "err", err)
+			continue
+		}
+
+		slog.Info("produced fixture", "source", f.Source)
+	}
+
+	return nil
+}
+
+func produceEdgeCases(ctx context.Context, p *producer.Producer) error {
+	var names []string
+	if edgeCases != "" {
+		names = strings.Split(edgeCases, ",")
+	}
+
+	cases, err := producer.EdgeCases(names...)
+	if err != nil {
+		return err
+	}
+
+	for _, c := range cases {
+		for i, payload := range c.Payloads {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+
+			if err := p.Produce(payload); err != nil {
+				slog.Error("failed producing message", "case", c.Name, "payload", i, "err", err)
+				continue
+			}
+
+			slog.Info("produced edge case", "case", c.Name, "description", c.Description, "payload", i, "size", len(payload))
+		}
+	}
+
+	return nil
+}
+
+// wait blocks for d or until ctx is done, returning its error then.
+func wait(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
 }
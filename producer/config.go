@@ -1,6 +1,32 @@
 package producer
 
+import (
+	"errors"
+	"time"
+)
+
 type Config struct {
 	Servers string `yaml:"servers"`
 	Topic   string `yaml:"topic"`
+	// FlushTimeout limits how long the producer waits for outstanding deliveries on close, 30s by default.
+	FlushTimeout time.Duration `yaml:"flush_timeout"`
+	// Key is the key strategy: order_uid, customer_id, shardkey or none.
+	Key string `yaml:"key"`
+	// Partition sends all the messages to a single partition, the partitioner chooses it by key if not set.
+	Partition *int32 `yaml:"partition"`
+	// Headers are added to every message.
+	Headers map[string]string `yaml:"headers"`
+	// TraceContext adds a traceparent header of a new trace to every message.
+	TraceContext bool `yaml:"trace_context"`
+	// Generator configures fake orders of fake and load modes.
+	Generator GeneratorConfig `yaml:"generator"`
+}
+
+// Validate checks the options which kafka client does not check itself.
+func (c Config) Validate() error {
+	if c.Partition != nil && *c.Partition < 0 {
+		return errors.New("partition should be >= 0")
+	}
+
+	return validateKeyStrategy(c.Key)
 }
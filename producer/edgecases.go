@@ -0,0 +1,192 @@
+package producer
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// oversizedItemsCount is the number of items in the oversized-items edge case,
+// the order still fits into the default 1 MB kafka message size limit.
+const oversizedItemsCount = 3000
+
+// EdgeCase is a named set of payloads which are either invalid orders or valid ones
+// which stress the consumer. Payloads are the same on every run, so scenarios are reproducible.
+type EdgeCase struct {
+	Name        string
+	Description string
+	Payloads    [][]byte
+}
+
+var edgeCases = []struct {
+	name        string
+	description string
+	payloads    func() [][]byte
+}{
+	{"missing-fields", "orders without required fields", missingFields},
+	{"wrong-types", "orders with fields of wrong JSON types", wrongTypes},
+	{"oversized-items", fmt.Sprintf("valid order with %d items", oversizedItemsCount), oversizedItems},
+	{"duplicate", "the same valid order sent twice", duplicate},
+	{"malformed-json", "payloads which are not JSON objects", malformedJSON},
+}
+
+// EdgeCaseNames returns names of all the known edge cases.
+func EdgeCaseNames() []string {
+	names := make([]string, 0, len(edgeCases))
+	for _, c := range edgeCases {
+		names = append(names, c.name)
+	}
+
+	return names
+}
+
+// EdgeCases returns the edge cases with the given names, or all of them if no names are given.
+func EdgeCases(names ...string) ([]EdgeCase, error) {
+	for _, name := range names {
+		if !slices.Contains(EdgeCaseNames(), name) {
+			return nil, fmt.Errorf("unknown edge case %q, known are: %s", name, strings.Join(EdgeCaseNames(), ", "))
+		}
+	}
+
+	var res []EdgeCase
+	for _, c := range edgeCases {
+		if len(names) > 0 && !slices.Contains(names, c.name) {
+			continue
+		}
+
+		res = append(res, EdgeCase{
+			Name:        c.name,
+			Description: c.description,
+			Payloads:    c.payloads(),
+		})
+	}
+
+	return res, nil
+}
+
+// fixtureOrder returns a valid order with fixed field values.
+func fixtureOrder(id string) Order {
+	return Order{
+		ID:          id,
+		TrackNumber: "WBILMTESTTRACK",
+		Entry:       "WBIL",
+		Delivery: Delivery{
+			Name:    "Test Testov",
+			Phone:   "+9720000000",
+			Zip:     "2639809",
+			City:    "Kiryat Mozkin",
+			Address: "Ploshad Mira 15",
+			Region:  "Kraiot",
+			Email:   "test@gmail.com",
+		},
+		Payment: &Payment{
+			Transaction:  id,
+			Currency:     "USD",
+			Provider:     "wbpay",
+			Amount:       decimal.NewFromInt(1817),
+			PaymentDT:    1637907727,
+			Bank:         "alpha",
+			DeliveryCost: decimal.NewFromInt(1500),
+			GoodsTotal:   317,
+			CustomFee:    decimal.Zero,
+		},
+		Items: []Item{
+			{
+				CHRTID:      9934930,
+				TrackNumber: "WBILMTESTTRACK",
+				Price:       decimal.NewFromInt(453),
+				RID:         "ab4219087a764ae0btest",
+				Name:        "Mascaras",
+				Sale:        decimal.NewFromInt(30),
+				Size:        "0",
+				TotalPrice:  decimal.NewFromInt(317),
+				NMID:        2389212,
+				Brand:       "Vivienne Sabo",
+				Status:      202,
+			},
+		},
+		Locale:          "en",
+		CustomerID:      "test",
+		DeliveryService: "meest",
+		ShardKey:        "9",
+		SMID:            99,
+		CreatedAt:       time.Date(2021, 11, 26, 6, 22, 19, 0, time.UTC),
+		OOFShard:        "1",
+	}
+}
+
+// mutate encodes the fixture order after fn changed its generic JSON representation.
+func mutate(id string, fn func(order map[string]any)) []byte {
+	encoded, _ := json.Marshal(fixtureOrder(id))
+
+	var order map[string]any
+	_ = json.Unmarshal(encoded, &order)
+	fn(order)
+
+	res, _ := json.Marshal(order)
+	return res
+}
+
+func missingFields() [][]byte {
+	return [][]byte{
+		mutate("edge-missing-uid", func(o map[string]any) { delete(o, "order_uid") }),
+		mutate("edge-missing-delivery", func(o map[string]any) { delete(o, "delivery") }),
+		mutate("edge-missing-track-number", func(o map[string]any) { delete(o, "track_number") }),
+		mutate("edge-missing-customer", func(o map[string]any) { delete(o, "customer_id") }),
+		mutate("edge-missing-transaction", func(o map[string]any) {
+			delete(o["payment"].(map[string]any), "transaction")
+		}),
+		mutate("edge-missing-date", func(o map[string]any) { delete(o, "date_created") }),
+	}
+}
+
+func wrongTypes() [][]byte {
+	return [][]byte{
+		mutate("edge-numeric-uid", func(o map[string]any) { o["order_uid"] = 12345 }),
+		mutate("edge-bool-amount", func(o map[string]any) {
+			o["payment"].(map[string]any)["amount"] = true
+		}),
+		mutate("edge-object-items", func(o map[string]any) { o["items"] = o["items"].([]any)[0] }),
+		mutate("edge-numeric-date", func(o map[string]any) { o["date_created"] = 1637907739 }),
+		mutate("edge-null-delivery", func(o map[string]any) { o["delivery"] = nil }),
+	}
+}
+
+func oversizedItems() [][]byte {
+	order := fixtureOrder("edge-oversized-items")
+	item := order.Items[0]
+
+	order.Items = make([]Item, 0, oversizedItemsCount)
+	for i := range oversizedItemsCount {
+		item.CHRTID = i + 1
+		order.Items = append(order.Items, item)
+	}
+
+	goods := item.TotalPrice.Mul(decimal.NewFromInt(oversizedItemsCount))
+	order.Payment.GoodsTotal = int(goods.IntPart())
+	order.Payment.Amount = goods.Add(order.Payment.DeliveryCost)
+
+	encoded, _ := json.Marshal(order)
+	return [][]byte{encoded}
+}
+
+func duplicate() [][]byte {
+	encoded, _ := json.Marshal(fixtureOrder("edge-duplicate"))
+	return [][]byte{encoded, encoded}
+}
+
+func malformedJSON() [][]byte {
+	valid, _ := json.Marshal(fixtureOrder("edge-truncated"))
+
+	return [][]byte{
+		valid[:len(valid)/2],
+		[]byte("not a json"),
+		[]byte(`["order_uid", "edge-array"]`),
+		[]byte("null"),
+		{},
+	}
+}
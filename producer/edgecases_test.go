@@ -0,0 +1,105 @@
+package producer
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/lezzercringe/some-assignment/orderschema"
+)
+
+// persistorAccepts decodes and validates the payload the way the consumer of the persistor does.
+func persistorAccepts(payload []byte) error {
+	var order orderschema.Order
+	if err := json.Unmarshal(payload, &order); err != nil {
+		return err
+	}
+
+	return orderschema.Validate(context.Background(), &order)
+}
+
+func TestEdgeCases(t *testing.T) {
+	t.Parallel()
+
+	// defaultMaxMessageBytes is the default message size limit of kafka brokers
+	const defaultMaxMessageBytes = 1 << 20
+
+	invalid := map[string]bool{
+		"missing-fields": true,
+		"wrong-types":    true,
+		"malformed-json": true,
+	}
+
+	cases, err := EdgeCases()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(cases) != len(EdgeCaseNames()) {
+		t.Fatalf("expected all %d edge cases, got %d", len(EdgeCaseNames()), len(cases))
+	}
+
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			if len(c.Payloads) == 0 {
+				t.Fatal("expected payloads")
+			}
+
+			for i, payload := range c.Payloads {
+				err := persistorAccepts(payload)
+				if invalid[c.Name] && err == nil {
+					t.Errorf("payload %d is accepted by the persistor: %s", i, payload)
+				}
+
+				if !invalid[c.Name] && err != nil {
+					t.Errorf("payload %d is rejected by the persistor: %v", i, err)
+				}
+
+				if len(payload) > defaultMaxMessageBytes {
+					t.Errorf("payload %d of %d bytes exceeds kafka message size limit", i, len(payload))
+				}
+			}
+		})
+	}
+
+	t.Run("duplicate sends the same order twice", func(t *testing.T) {
+		cases, _ := EdgeCases("duplicate")
+		if payloads := cases[0].Payloads; len(payloads) != 2 || !bytes.Equal(payloads[0], payloads[1]) {
+			t.Errorf("expected two equal payloads, got %d", len(payloads))
+		}
+	})
+
+	t.Run("oversized order has all the items", func(t *testing.T) {
+		cases, _ := EdgeCases("oversized-items")
+
+		var order orderschema.Order
+		if err := json.Unmarshal(cases[0].Payloads[0], &order); err != nil || len(order.Items) != oversizedItemsCount {
+			t.Errorf("expected %d items, got %d, %v", oversizedItemsCount, len(order.Items), err)
+		}
+	})
+
+	t.Run("payloads are reproducible", func(t *testing.T) {
+		first, _ := EdgeCases()
+		second, _ := EdgeCases()
+
+		for i := range first {
+			for j := range first[i].Payloads {
+				if !bytes.Equal(first[i].Payloads[j], second[i].Payloads[j]) {
+					t.Errorf("payload %d of %s differs between runs", j, first[i].Name)
+				}
+			}
+		}
+	})
+
+	t.Run("selected by name", func(t *testing.T) {
+		cases, err := EdgeCases("wrong-types", "duplicate")
+		if err != nil || len(cases) != 2 {
+			t.Fatalf("expected two edge cases, got %d, %v", len(cases), err)
+		}
+
+		if _, err := EdgeCases("duplicate", "unknown"); err == nil {
+			t.Error("expected error for unknown edge case")
+		}
+	})
+}
@@ -0,0 +1,114 @@
+package producer
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+)
+
+// maxFixtureSize limits the size of a single message in a fixtures file.
+const maxFixtureSize = 16 << 20
+
+// fixtureExtensions are the extensions of files read from a fixtures directory.
+var fixtureExtensions = []string{".ndjson", ".jsonl"}
+
+// Fixture is a single message read from a fixtures file. Value is sent as is,
+// so fixtures may contain payloads which are not valid orders.
+type Fixture struct {
+	Source string
+	Value  []byte
+	// CreatedAt is taken from the date_created field and is zero if the payload has none.
+	CreatedAt time.Time
+}
+
+// ReadFixtures reads messages from a NDJSON file, or from all the NDJSON files of a directory
+// in lexical order. Blank lines are skipped.
+func ReadFixtures(path string) ([]Fixture, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if !info.IsDir() {
+		return readFixturesFile(path)
+	}
+
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var res []Fixture
+	for _, e := range entries {
+		if e.IsDir() || !slices.Contains(fixtureExtensions, filepath.Ext(e.Name())) {
+			continue
+		}
+
+		fixtures, err := readFixturesFile(filepath.Join(path, e.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		res = append(res, fixtures...)
+	}
+
+	return res, nil
+}
+
+func readFixturesFile(path string) ([]Fixture, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(nil, maxFixtureSize)
+
+	var res []Fixture
+	for n := 1; scanner.Scan(); n++ {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+
+		var created struct {
+			DateCreated time.Time `json:"date_created"`
+		}
+
+		// malformed payloads are sent without timing
+		_ = json.Unmarshal(line, &created)
+
+		res = append(res, Fixture{
+			Source:    fmt.Sprintf("%s:%d", path, n),
+			Value:     bytes.Clone(line),
+			CreatedAt: created.DateCreated,
+		})
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading %s: %w", path, err)
+	}
+
+	return res, nil
+}
+
+// ReplayDelay returns how long to wait before sending next after prev to keep their original timing,
+// shrunk by speed. Messages without date_created and messages going back in time are sent immediately.
+func ReplayDelay(prev, next Fixture, speed float64) time.Duration {
+	if prev.CreatedAt.IsZero() || next.CreatedAt.IsZero() || speed <= 0 {
+		return 0
+	}
+
+	gap := next.CreatedAt.Sub(prev.CreatedAt)
+	if gap <= 0 {
+		return 0
+	}
+
+	return time.Duration(float64(gap) / speed)
+}
@@ -0,0 +1,113 @@
+package producer
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestReadFixtures(t *testing.T) {
+	t.Parallel()
+
+	t.Run("bundled fixtures", func(t *testing.T) {
+		fixtures, err := ReadFixtures("fixtures")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if len(fixtures) != 3 {
+			t.Fatalf("expected 3 fixtures, got %d", len(fixtures))
+		}
+
+		for i, f := range fixtures {
+			if err := persistorAccepts(f.Value); err != nil {
+				t.Errorf("fixture %s is rejected by the persistor: %v", f.Source, err)
+			}
+
+			if f.CreatedAt.IsZero() || (i > 0 && f.CreatedAt.Before(fixtures[i-1].CreatedAt)) {
+				t.Errorf("unexpected date of fixture %s: %v", f.Source, f.CreatedAt)
+			}
+		}
+	})
+
+	t.Run("directory", func(t *testing.T) {
+		dir := t.TempDir()
+		write := func(name, content string) {
+			if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		write("b.jsonl", `{"order_uid":"third"}`+"\n")
+		write("a.ndjson", `{"order_uid":"first","date_created":"2021-11-26T06:22:19Z"}`+"\n\n  \nnot a json\n")
+		write("c.json", `{"order_uid":"skipped"}`)
+		if err := os.Mkdir(filepath.Join(dir, "nested.ndjson"), 0o700); err != nil {
+			t.Fatal(err)
+		}
+
+		fixtures, err := ReadFixtures(dir)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		want := []struct {
+			source    string
+			value     string
+			createdAt time.Time
+		}{
+			{filepath.Join(dir, "a.ndjson") + ":1", `{"order_uid":"first","date_created":"2021-11-26T06:22:19Z"}`, time.Date(2021, 11, 26, 6, 22, 19, 0, time.UTC)},
+			{filepath.Join(dir, "a.ndjson") + ":4", "not a json", time.Time{}},
+			{filepath.Join(dir, "b.jsonl") + ":1", `{"order_uid":"third"}`, time.Time{}},
+		}
+
+		if len(fixtures) != len(want) {
+			t.Fatalf("expected %d fixtures, got %d", len(want), len(fixtures))
+		}
+
+		for i, w := range want {
+			f := fixtures[i]
+			if f.Source != w.source || string(f.Value) != w.value || !f.CreatedAt.Equal(w.createdAt) {
+				t.Errorf("expected fixture %d to be %+v, got %s %s %v", i, w, f.Source, f.Value, f.CreatedAt)
+			}
+		}
+	})
+
+	t.Run("missing path", func(t *testing.T) {
+		if _, err := ReadFixtures(filepath.Join(t.TempDir(), "missing.ndjson")); err == nil {
+			t.Error("expected error for missing path")
+		}
+	})
+}
+
+func TestReplayDelay(t *testing.T) {
+	t.Parallel()
+
+	at := func(sec int) Fixture {
+		return Fixture{CreatedAt: time.Date(2021, 11, 26, 6, 22, sec, 0, time.UTC)}
+	}
+
+	tests := []struct {
+		name       string
+		prev, next Fixture
+		speed      float64
+		want       time.Duration
+	}{
+		{name: "original timing", prev: at(19), next: at(21), speed: 1, want: 2 * time.Second},
+		{name: "faster", prev: at(19), next: at(21), speed: 4, want: 500 * time.Millisecond},
+		{name: "slower", prev: at(19), next: at(21), speed: 0.5, want: 4 * time.Second},
+		{name: "back in time", prev: at(21), next: at(19), speed: 1, want: 0},
+		{name: "same time", prev: at(21), next: at(21), speed: 1, want: 0},
+		{name: "previous without date", prev: Fixture{}, next: at(21), speed: 1, want: 0},
+		{name: "next without date", prev: at(19), next: Fixture{}, speed: 1, want: 0},
+		{name: "no speed", prev: at(19), next: at(21), speed: 0, want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ReplayDelay(tt.prev, tt.next, tt.speed); got != tt.want {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
@@ -1,60 +1,249 @@
 package producer
 
 import (
-	"math/rand"
+	"encoding/hex"
+	"fmt"
+	"math/rand/v2"
+	"slices"
+	"strings"
+	"sync"
 	"time"
 
-	"github.com/go-faker/faker/v4"
+	"github.com/shopspring/decimal"
 )
 
-func GenerateOrder() Order {
-	return Order{
-		ID:          faker.UUIDHyphenated(),
-		TrackNumber: faker.Word(),
-		Entry:       faker.Word(),
+// customersCount is the number of distinct customers, so that customers place several orders.
+const customersCount = 5000
+
+// GeneratorConfig configures the fake orders generator. The same config always produces the same orders.
+type GeneratorConfig struct {
+	Seed uint64 `yaml:"seed"`
+	// Start is the creation time of the first order, following orders are created
+	// after random intervals of MeanInterval on average.
+	Start        time.Time     `yaml:"start"`
+	MeanInterval time.Duration `yaml:"mean_interval"`
+	// ItemCounts maps count of items in an order to its relative weight.
+	ItemCounts map[int]int `yaml:"item_counts"`
+	// InvalidFraction is the fraction of orders which break one of the consumer's validation rules.
+	InvalidFraction float64 `yaml:"invalid_fraction"`
+}
+
+var defaultGeneratorConfig = GeneratorConfig{
+	Seed:         1,
+	Start:        time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+	MeanInterval: 30 * time.Second,
+	ItemCounts:   map[int]int{1: 45, 2: 25, 3: 13, 4: 8, 5: 5, 8: 3, 15: 1},
+}
+
+func (c GeneratorConfig) Validate() error {
+	if c.InvalidFraction < 0 || c.InvalidFraction > 1 {
+		return fmt.Errorf("invalid_fraction should be in [0, 1], got %v", c.InvalidFraction)
+	}
+
+	if c.MeanInterval < 0 {
+		return fmt.Errorf("mean_interval should be >= 0, got %v", c.MeanInterval)
+	}
+
+	for count, weight := range c.ItemCounts {
+		if count <= 0 || weight < 0 {
+			return fmt.Errorf("item_counts should map positive counts to non-negative weights, got %d: %d", count, weight)
+		}
+	}
+
+	return nil
+}
+
+// Generator produces realistic orders: items, sales and payment totals are consistent with each other,
+// and delivery, currency and locale belong to the same country. It is safe for concurrent use.
+type Generator struct {
+	mu          sync.Mutex
+	rng         *rand.Rand
+	cfg         GeneratorConfig
+	itemCounts  []int
+	itemWeights []int
+	totalWeight int
+	createdAt   time.Time
+}
+
+// NewGenerator creates a generator, zero fields of cfg are taken from the defaults.
+func NewGenerator(cfg GeneratorConfig) (*Generator, error) {
+	if cfg.Seed == 0 {
+		cfg.Seed = defaultGeneratorConfig.Seed
+	}
+
+	if cfg.Start.IsZero() {
+		cfg.Start = defaultGeneratorConfig.Start
+	}
+
+	if cfg.MeanInterval == 0 {
+		cfg.MeanInterval = defaultGeneratorConfig.MeanInterval
+	}
+
+	if len(cfg.ItemCounts) == 0 {
+		cfg.ItemCounts = defaultGeneratorConfig.ItemCounts
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	g := &Generator{
+		rng:       rand.New(rand.NewPCG(cfg.Seed, cfg.Seed)),
+		cfg:       cfg,
+		createdAt: cfg.Start,
+	}
+
+	// map iteration order is random, counts are sorted to keep the generator deterministic
+	for count, weight := range cfg.ItemCounts {
+		if weight > 0 {
+			g.itemCounts = append(g.itemCounts, count)
+		}
+	}
+
+	slices.Sort(g.itemCounts)
+	for _, count := range g.itemCounts {
+		g.itemWeights = append(g.itemWeights, cfg.ItemCounts[count])
+		g.totalWeight += cfg.ItemCounts[count]
+	}
+
+	if g.totalWeight == 0 {
+		return nil, fmt.Errorf("item_counts should have a positive weight")
+	}
+
+	return g, nil
+}
+
+func (g *Generator) Next() Order {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.createdAt = g.createdAt.Add(time.Duration(g.rng.ExpFloat64() * float64(g.cfg.MeanInterval))).Truncate(time.Second)
+
+	c := pick(g.rng, countries)
+	id := g.hex(16)
+	track := "WB" + g.upper(12)
+
+	order := Order{
+		ID:          id,
+		TrackNumber: track,
+		Entry:       "WBIL",
 		Delivery: Delivery{
-			Name:    faker.Name(),
-			Phone:   faker.Phonenumber(),
-			Zip:     faker.Word(),
-			City:    faker.Word(),
-			Address: faker.Word(),
-			Region:  faker.Word(),
-			Email:   faker.Email(),
+			Name:    pick(g.rng, c.firstNames) + " " + pick(g.rng, c.lastNames),
+			Phone:   c.phonePrefix + g.digits(c.phoneDigits),
+			Zip:     g.digits(c.zipDigits),
+			Address: fmt.Sprintf("%s %d", pick(g.rng, c.streets), 1+g.rng.IntN(150)),
+			Email:   fmt.Sprintf("%s%d@%s", strings.ToLower(g.upper(1+g.rng.IntN(8))), g.rng.IntN(1000), pick(g.rng, c.emailDomains)),
 		},
-		Payment: Payment{
-			Transaction:  faker.UUIDHyphenated(),
-			RequestID:    faker.UUIDHyphenated(),
-			Currency:     faker.Currency(),
-			Provider:     faker.Word(),
-			Amount:       rand.Intn(10000),
-			PaymentDT:    time.Now().Unix(),
-			Bank:         faker.Word(),
-			DeliveryCost: rand.Intn(2000),
-			GoodsTotal:   rand.Intn(3000),
-			CustomFee:    rand.Intn(3000),
-		},
-		Items: []Item{
-			{
-				ChrtID:      rand.Intn(9999999),
-				TrackNumber: faker.Word(),
-				Price:       453,
-				RID:         faker.UUIDHyphenated(),
-				Name:        faker.Word(),
-				Sale:        rand.Intn(9999),
-				Size:        faker.Word(),
-				TotalPrice:  317,
-				NmID:        rand.Intn(9999999),
-				Brand:       faker.Word(),
-				Status:      rand.Intn(999),
-			},
-		},
-		Locale:            "en",
-		InternalSignature: faker.Word(),
-		CustomerID:        faker.Username(),
-		DeliveryService:   faker.Word(),
-		ShardKey:          "9",
-		SmID:              rand.Intn(999),
-		DateCreated:       time.Now(),
-		OofShard:          "1",
+		Locale:          c.locale,
+		CustomerID:      fmt.Sprintf("cust-%07d", g.rng.IntN(customersCount)),
+		DeliveryService: pick(g.rng, c.deliveryServices),
+		ShardKey:        fmt.Sprint(g.rng.IntN(10)),
+		SMID:            1 + g.rng.IntN(100),
+		CreatedAt:       g.createdAt,
+		OOFShard:        fmt.Sprint(1 + g.rng.IntN(2)),
+	}
+
+	city := pick(g.rng, c.cities)
+	order.Delivery.City, order.Delivery.Region = city.name, city.region
+
+	goods := decimal.Zero
+	for range g.itemCount() {
+		item := g.item(c, track)
+		goods = goods.Add(item.TotalPrice)
+		order.Items = append(order.Items, item)
 	}
+
+	delivery := pick(g.rng, c.deliveryCosts)
+	if goods.GreaterThanOrEqual(c.price(freeDeliveryFrom)) {
+		delivery = decimal.Zero
+	}
+
+	// custom fee is only charged for the orders above the duty-free threshold
+	fee := decimal.Zero
+	if goods.GreaterThan(c.price(dutyFreeLimit)) {
+		fee = goods.Sub(c.price(dutyFreeLimit)).Mul(customFeeRate).Round(c.precision)
+	}
+
+	order.Payment = &Payment{
+		Transaction:  id,
+		Currency:     c.currency,
+		Provider:     pick(g.rng, providers),
+		Amount:       goods.Add(delivery).Add(fee),
+		PaymentDT:    g.createdAt.Add(-time.Duration(5+g.rng.IntN(120)) * time.Second).Unix(),
+		Bank:         pick(g.rng, c.banks),
+		DeliveryCost: delivery,
+		GoodsTotal:   int(goods.Round(0).IntPart()),
+		CustomFee:    fee,
+	}
+
+	if g.rng.Float64() < g.cfg.InvalidFraction {
+		pick(g.rng, invalidations)(&order)
+	}
+
+	return order
+}
+
+func (g *Generator) item(c *country, track string) Item {
+	p := pick(g.rng, catalog)
+	// prices vary around the catalog price by up to 20%
+	price := c.price(p.price * (0.8 + 0.4*g.rng.Float64()))
+	sale := pick(g.rng, sales)
+
+	return Item{
+		CHRTID:      1000000 + g.rng.IntN(9000000),
+		TrackNumber: track,
+		Price:       price,
+		RID:         g.hex(10) + "test",
+		Name:        p.name,
+		Sale:        decimal.NewFromInt(int64(sale)),
+		Size:        pick(g.rng, p.sizes),
+		TotalPrice:  price.Mul(decimal.NewFromInt(int64(100 - sale))).Div(decimal.NewFromInt(100)).Round(c.precision),
+		NMID:        1000000 + g.rng.IntN(9000000),
+		Brand:       p.brand,
+		Status:      202,
+	}
+}
+
+func (g *Generator) itemCount() int {
+	n := g.rng.IntN(g.totalWeight)
+	for i, w := range g.itemWeights {
+		if n < w {
+			return g.itemCounts[i]
+		}
+
+		n -= w
+	}
+
+	return g.itemCounts[len(g.itemCounts)-1]
+}
+
+func (g *Generator) hex(n int) string {
+	b := make([]byte, (n+1)/2)
+	for i := range b {
+		b[i] = byte(g.rng.IntN(256))
+	}
+
+	return hex.EncodeToString(b)[:n]
+}
+
+func (g *Generator) digits(n int) string {
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = byte('0' + g.rng.IntN(10))
+	}
+
+	return string(b)
+}
+
+func (g *Generator) upper(n int) string {
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = byte('A' + g.rng.IntN(26))
+	}
+
+	return string(b)
+}
+
+func pick[T any](rng *rand.Rand, values []T) T {
+	return values[rng.IntN(len(values))]
 }
@@ -0,0 +1,71 @@
+package producer
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/lezzercringe/some-assignment/orderschema"
+	"github.com/shopspring/decimal"
+)
+
+// Generated orders are checked with the rules of the shared schema, so the generator
+// can not drift from what the consumer accepts.
+func TestGenerator_Next(t *testing.T) {
+	t.Parallel()
+
+	t.Run("orders are valid and consistent", func(t *testing.T) {
+		gen, err := NewGenerator(GeneratorConfig{Seed: 42})
+		if err != nil {
+			t.Fatalf("could not create generator: %v", err)
+		}
+
+		for range 500 {
+			order := gen.Next()
+
+			encoded, _ := json.Marshal(order)
+			var decoded orderschema.Order
+			if err := json.Unmarshal(encoded, &decoded); err != nil {
+				t.Fatalf("could not decode generated order: %v", err)
+			}
+
+			if err := orderschema.Validate(context.Background(), &decoded); err != nil {
+				t.Fatalf("generated order %s is invalid: %v", order.ID, err)
+			}
+
+			goods := decimal.Zero
+			for _, item := range order.Items {
+				goods = goods.Add(item.TotalPrice)
+			}
+
+			if !order.Payment.Amount.Equal(goods.Add(order.Payment.DeliveryCost).Add(order.Payment.CustomFee)) {
+				t.Fatalf("order %s amount %s does not match its items and fees", order.ID, order.Payment.Amount)
+			}
+		}
+	})
+
+	t.Run("same seed produces same orders", func(t *testing.T) {
+		first, _ := NewGenerator(GeneratorConfig{Seed: 7})
+		second, _ := NewGenerator(GeneratorConfig{Seed: 7})
+
+		for range 10 {
+			a, _ := json.Marshal(first.Next())
+			b, _ := json.Marshal(second.Next())
+
+			if string(a) != string(b) {
+				t.Fatalf("orders differ:\n%s\n%s", a, b)
+			}
+		}
+	})
+
+	t.Run("invalid fraction breaks validation", func(t *testing.T) {
+		gen, _ := NewGenerator(GeneratorConfig{Seed: 42, InvalidFraction: 1})
+
+		for range 50 {
+			order := gen.Next()
+			if err := orderschema.Validate(context.Background(), &order); err == nil {
+				t.Fatalf("order %s expected to be invalid", order.ID)
+			}
+		}
+	})
+}
@@ -0,0 +1,59 @@
+package producer
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"slices"
+)
+
+// Key strategies choose the order field used as the message key.
+// Messages with the same key go to the same partition and keep their order.
+const (
+	KeyNone       = "none"
+	KeyOrderUID   = "order_uid"
+	KeyCustomerID = "customer_id"
+	KeyShardKey   = "shardkey"
+)
+
+var keyStrategies = []string{KeyNone, KeyOrderUID, KeyCustomerID, KeyShardKey}
+
+// traceParentHeader is the W3C trace context header.
+const traceParentHeader = "traceparent"
+
+func validateKeyStrategy(strategy string) error {
+	if strategy == "" || slices.Contains(keyStrategies, strategy) {
+		return nil
+	}
+
+	return fmt.Errorf("unknown key strategy %q, expected one of %v", strategy, keyStrategies)
+}
+
+// messageKey returns the key of the payload for the strategy.
+// Payloads which are not orders or lack the field are sent without a key.
+func messageKey(strategy string, payload []byte) []byte {
+	if strategy == "" || strategy == KeyNone {
+		return nil
+	}
+
+	var fields map[string]any
+	if err := json.Unmarshal(payload, &fields); err != nil {
+		return nil
+	}
+
+	value, ok := fields[strategy].(string)
+	if !ok || value == "" {
+		return nil
+	}
+
+	return []byte(value)
+}
+
+// newTraceParent returns a traceparent header value of a new sampled trace.
+func newTraceParent() string {
+	var ids [24]byte
+	_, _ = rand.Read(ids[:])
+
+	return fmt.Sprintf("00-%s-%s-01", hex.EncodeToString(ids[:16]), hex.EncodeToString(ids[16:]))
+}
@@ -0,0 +1,74 @@
+package producer
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestMessageKey(t *testing.T) {
+	t.Parallel()
+
+	order, _ := json.Marshal(fixtureOrder("b563feb7b2b84b6test"))
+
+	tests := []struct {
+		name     string
+		strategy string
+		payload  string
+		want     string
+	}{
+		{name: "order uid", strategy: KeyOrderUID, payload: string(order), want: "b563feb7b2b84b6test"},
+		{name: "customer id", strategy: KeyCustomerID, payload: string(order), want: "test"},
+		{name: "shard key", strategy: KeyShardKey, payload: string(order), want: "9"},
+		{name: "no strategy", strategy: "", payload: string(order), want: ""},
+		{name: "none strategy", strategy: KeyNone, payload: string(order), want: ""},
+		{name: "missing field", strategy: KeyOrderUID, payload: `{"customer_id":"test"}`, want: ""},
+		{name: "empty field", strategy: KeyOrderUID, payload: `{"order_uid":""}`, want: ""},
+		{name: "numeric field", strategy: KeyOrderUID, payload: `{"order_uid":12345}`, want: ""},
+		{name: "null field", strategy: KeyOrderUID, payload: `{"order_uid":null}`, want: ""},
+		{name: "not an object", strategy: KeyOrderUID, payload: `["b563feb7b2b84b6test"]`, want: ""},
+		{name: "malformed json", strategy: KeyOrderUID, payload: `{"order_uid":"b563`, want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := messageKey(tt.strategy, []byte(tt.payload))
+			if string(got) != tt.want {
+				t.Errorf("expected key %q, got %q", tt.want, got)
+			}
+
+			if tt.want == "" && got != nil {
+				t.Errorf("expected no key, got empty one")
+			}
+		})
+	}
+}
+
+func TestValidateKeyStrategy(t *testing.T) {
+	t.Parallel()
+
+	for _, strategy := range append([]string{""}, keyStrategies...) {
+		if err := validateKeyStrategy(strategy); err != nil {
+			t.Errorf("expected strategy %q to be valid, got: %v", strategy, err)
+		}
+	}
+
+	if err := validateKeyStrategy("delivery_email"); err == nil || !strings.Contains(err.Error(), "unknown key strategy") {
+		t.Errorf("expected unknown key strategy error, got: %v", err)
+	}
+}
+
+func TestNewTraceParent(t *testing.T) {
+	t.Parallel()
+
+	first, second := newTraceParent(), newTraceParent()
+
+	parts := strings.Split(first, "-")
+	if len(parts) != 4 || parts[0] != "00" || len(parts[1]) != 32 || len(parts[2]) != 16 || parts[3] != "01" {
+		t.Fatalf("malformed traceparent %q", first)
+	}
+
+	if first == second {
+		t.Errorf("expected new trace ids, got %q twice", first)
+	}
+}
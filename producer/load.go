@@ -0,0 +1,56 @@
+package producer
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+type LoadConfig struct {
+	// Rate is the target number of messages per second across all workers, 0 means as fast as possible.
+	Rate        int
+	Duration    time.Duration
+	Concurrency int
+}
+
+// RunLoad produces messages made by next from cfg.Concurrency workers until cfg.Duration passes or ctx is done.
+// next is called concurrently.
+func (p *Producer) RunLoad(ctx context.Context, cfg LoadConfig, next func() []byte) {
+	ctx, cancel := context.WithTimeout(ctx, cfg.Duration)
+	defer cancel()
+
+	var tokens <-chan time.Time
+	if cfg.Rate > 0 {
+		ticker := time.NewTicker(time.Second / time.Duration(cfg.Rate))
+		defer ticker.Stop()
+
+		tokens = ticker.C
+	}
+
+	var wg sync.WaitGroup
+	for range cfg.Concurrency {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for {
+				if tokens != nil {
+					select {
+					case <-ctx.Done():
+						return
+					case <-tokens:
+					}
+				} else if ctx.Err() != nil {
+					return
+				}
+
+				if err := p.Produce(next()); err != nil {
+					slog.Error("failed producing message", "err", err)
+				}
+			}
+		}()
+	}
+
+	wg.Wait()
+}
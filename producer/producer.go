@@ -1,18 +1,42 @@
 package producer
 
 import (
-	"fmt"
+	"errors"
+	"log/slog"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
 )
 
+const (
+	defaultFlushTimeout = 30 * time.Second
+	// queueFullBackoff is how long Produce waits for the local queue to drain before retrying.
+	queueFullBackoff = 10 * time.Millisecond
+)
+
+// Producer sends messages to the configured topic and tracks their delivery reports.
 type Producer struct {
-	client *kafka.Producer
-	cfg    Config
+	client    *kafka.Producer
+	cfg       Config
+	partition int32
+	headers   []kafka.Header
+
+	sent    atomic.Int64
+	started sync.Once
+	done    chan struct{}
+	mu      sync.Mutex
+	report  Report
 }
 
 func NewProducer(cfg Config) (*Producer, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
 	producer, err := kafka.NewProducer(&kafka.ConfigMap{
 		"bootstrap.servers": cfg.Servers,
 	})
@@ -21,31 +45,138 @@ func NewProducer(cfg Config) (*Producer, error) {
 		return nil, err
 	}
 
-	return &Producer{
-		client: producer,
-		cfg:    cfg,
-	}, nil
+	p := &Producer{
+		client:    producer,
+		cfg:       cfg,
+		partition: kafka.PartitionAny,
+		done:      make(chan struct{}),
+	}
+
+	if cfg.Partition != nil {
+		p.partition = *cfg.Partition
+	}
+
+	for key, value := range cfg.Headers {
+		p.headers = append(p.headers, kafka.Header{Key: key, Value: []byte(value)})
+	}
+
+	slices.SortFunc(p.headers, func(a, b kafka.Header) int {
+		return strings.Compare(a.Key, b.Key)
+	})
+
+	go p.handleEvents()
+	return p, nil
 }
 
-func (p *Producer) Start() {
+func (p *Producer) handleEvents() {
+	defer close(p.done)
+
 	for e := range p.client.Events() {
-		if kafkaErr, ok := e.(kafka.Error); ok {
-			fmt.Printf("Error delivering message: %v\n", kafkaErr)
+		switch ev := e.(type) {
+		case *kafka.Message:
+			p.delivered(ev)
+		case kafka.Error:
+			slog.Error("kafka client error", "err", ev)
 		}
 	}
 }
 
+func (p *Producer) delivered(msg *kafka.Message) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if msg.TopicPartition.Error != nil {
+		p.report.Failed++
+		slog.Error("failed delivering message", "err", msg.TopicPartition.Error)
+		return
+	}
+
+	p.report.Acked++
+	p.report.Finished = time.Now()
+
+	if produced, ok := msg.Opaque.(time.Time); ok {
+		p.report.Latencies = append(p.report.Latencies, time.Since(produced))
+	}
+}
+
+// Produce enqueues the message, waiting while the local queue is full.
+// The delivery result is only known from the report, which counts the message as failed if it is not enqueued.
 func (p *Producer) Produce(msg []byte) error {
-	return p.client.Produce(&kafka.Message{
-		TopicPartition: kafka.TopicPartition{
-			Topic: &p.cfg.Topic,
-		},
-		Value:     msg,
-		Timestamp: time.Now(),
-	}, nil)
+	key := messageKey(p.cfg.Key, msg)
+	headers := p.headers
+
+	if p.cfg.TraceContext {
+		headers = append(slices.Clip(headers), kafka.Header{Key: traceParentHeader, Value: []byte(newTraceParent())})
+	}
+
+	for {
+		now := time.Now()
+		err := p.client.Produce(&kafka.Message{
+			TopicPartition: kafka.TopicPartition{
+				Topic:     &p.cfg.Topic,
+				Partition: p.partition,
+			},
+			Key:       key,
+			Value:     msg,
+			Headers:   headers,
+			Timestamp: now,
+			Opaque:    now,
+		}, nil)
+
+		var kafkaErr kafka.Error
+		if errors.As(err, &kafkaErr) && kafkaErr.Code() == kafka.ErrQueueFull {
+			time.Sleep(queueFullBackoff)
+			continue
+		}
+
+		if err != nil {
+			p.mu.Lock()
+			p.report.Failed++
+			p.mu.Unlock()
+
+			return err
+		}
+
+		p.started.Do(func() {
+			p.mu.Lock()
+			p.report.Started = now
+			p.mu.Unlock()
+		})
+		p.sent.Add(1)
+
+		return nil
+	}
+}
+
+// Report returns delivery statistics of the messages produced so far.
+func (p *Producer) Report() Report {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	res := p.report
+	res.Sent = int(p.sent.Load())
+	res.Latencies = append([]time.Duration(nil), p.report.Latencies...)
+
+	return res
 }
 
+// Close waits up to the configured flush timeout for outstanding deliveries.
+// Messages still not delivered by then are counted as undelivered in the report.
 func (p *Producer) Close() {
-	p.client.Flush(1500)
+	timeout := p.cfg.FlushTimeout
+	if timeout <= 0 {
+		timeout = defaultFlushTimeout
+	}
+
+	left := p.client.Flush(int(timeout.Milliseconds()))
+	if left > 0 {
+		slog.Warn("messages were not delivered before flush timeout", "undelivered", left, "timeout", timeout)
+	}
+
 	p.client.Close()
+	<-p.done
+
+	p.mu.Lock()
+	p.report.Undelivered = left
+	p.mu.Unlock()
 }
@@ -0,0 +1,29 @@
+package producer
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestProducer_Produce(t *testing.T) {
+	t.Parallel()
+
+	t.Run("messages not enqueued are failed", func(t *testing.T) {
+		// nothing listens on the port, the client only connects to deliver the enqueued messages
+		p, err := NewProducer(Config{Servers: "127.0.0.1:1", Topic: "orders", FlushTimeout: time.Millisecond})
+		if err != nil {
+			t.Fatalf("could not create producer: %v", err)
+		}
+		defer p.Close()
+
+		// larger than the default message.max.bytes, so it is rejected before being enqueued
+		if err := p.Produce(bytes.Repeat([]byte("x"), 2<<20)); err == nil {
+			t.Fatal("expected oversized message to be rejected")
+		}
+
+		if r := p.Report(); r.Sent != 0 || r.Failed != 1 {
+			t.Errorf("expected the message to be failed and not sent, got %+v", r)
+		}
+	})
+}
@@ -0,0 +1,59 @@
+package producer
+
+import (
+	"log/slog"
+	"math"
+	"slices"
+	"strconv"
+	"time"
+)
+
+// Report summarizes delivery of the produced messages.
+type Report struct {
+	Sent  int
+	Acked int
+	// Failed counts the messages which could not be enqueued along with the ones whose delivery failed.
+	Failed      int
+	Undelivered int
+	Latencies   []time.Duration
+	// Started is the time the first message was produced, Finished the time the last one was acked.
+	Started  time.Time
+	Finished time.Time
+}
+
+// Percentile returns the delivery latency below which p percent of the acked messages fall.
+func (r Report) Percentile(p float64) time.Duration {
+	if len(r.Latencies) == 0 {
+		return 0
+	}
+
+	sorted := slices.Clone(r.Latencies)
+	slices.Sort(sorted)
+
+	i := int(math.Ceil(p/100*float64(len(sorted)))) - 1
+	return sorted[min(max(i, 0), len(sorted)-1)]
+}
+
+// Throughput returns acked messages per second.
+func (r Report) Throughput() float64 {
+	elapsed := r.Finished.Sub(r.Started)
+	if r.Acked == 0 || elapsed <= 0 {
+		return 0
+	}
+
+	return float64(r.Acked) / elapsed.Seconds()
+}
+
+func (r Report) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.Int("sent", r.Sent),
+		slog.Int("acked", r.Acked),
+		slog.Int("failed", r.Failed),
+		slog.Int("undelivered", r.Undelivered),
+		slog.Duration("p50", r.Percentile(50)),
+		slog.Duration("p90", r.Percentile(90)),
+		slog.Duration("p99", r.Percentile(99)),
+		slog.Duration("max", r.Percentile(100)),
+		slog.String("throughput", strconv.FormatFloat(r.Throughput(), 'f', 1, 64)+" msg/s"),
+	)
+}
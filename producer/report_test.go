@@ -0,0 +1,76 @@
+package producer
+
+import (
+	"testing"
+	"time"
+)
+
+func TestReport_Percentile(t *testing.T) {
+	t.Parallel()
+
+	latencies := []time.Duration{
+		40 * time.Millisecond, 10 * time.Millisecond, 30 * time.Millisecond, 20 * time.Millisecond, 50 * time.Millisecond,
+		90 * time.Millisecond, 60 * time.Millisecond, 80 * time.Millisecond, 100 * time.Millisecond, 70 * time.Millisecond,
+	}
+
+	tests := []struct {
+		name      string
+		latencies []time.Duration
+		p         float64
+		want      time.Duration
+	}{
+		{name: "no latencies", latencies: nil, p: 50, want: 0},
+		{name: "single latency", latencies: []time.Duration{time.Second}, p: 99, want: time.Second},
+		{name: "median", latencies: latencies, p: 50, want: 50 * time.Millisecond},
+		{name: "between ranks rounds up", latencies: latencies, p: 55, want: 60 * time.Millisecond},
+		{name: "p90", latencies: latencies, p: 90, want: 90 * time.Millisecond},
+		{name: "p99", latencies: latencies, p: 99, want: 100 * time.Millisecond},
+		{name: "max", latencies: latencies, p: 100, want: 100 * time.Millisecond},
+		{name: "zero is min", latencies: latencies, p: 0, want: 10 * time.Millisecond},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := (Report{Latencies: tt.latencies}).Percentile(tt.p); got != tt.want {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+
+	t.Run("latencies are not reordered", func(t *testing.T) {
+		r := Report{Latencies: []time.Duration{3, 1, 2}}
+		r.Percentile(50)
+
+		if r.Latencies[0] != 3 || r.Latencies[1] != 1 || r.Latencies[2] != 2 {
+			t.Errorf("latencies were modified: %v", r.Latencies)
+		}
+	})
+}
+
+func TestReport_Throughput(t *testing.T) {
+	t.Parallel()
+
+	started := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		acked    int
+		finished time.Time
+		want     float64
+	}{
+		{name: "acked over elapsed", acked: 500, finished: started.Add(2 * time.Second), want: 250},
+		{name: "fraction of second", acked: 10, finished: started.Add(100 * time.Millisecond), want: 100},
+		{name: "nothing acked", acked: 0, finished: started.Add(time.Second), want: 0},
+		{name: "not finished", acked: 10, finished: time.Time{}, want: 0},
+		{name: "no time elapsed", acked: 1, finished: started, want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := Report{Acked: tt.acked, Started: started, Finished: tt.finished}
+			if got := r.Throughput(); got != tt.want {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}